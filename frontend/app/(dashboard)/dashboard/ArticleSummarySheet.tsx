@@ -70,7 +70,7 @@ export function ArticleSummarySheet({
               Source & Date
             </Label>
             <p className="text-sm">
-              {article.source_name} • <RelativeTime date={article.created_at} />
+              {article.source_name} • <RelativeTime date={article.published_at ?? article.created_at} />
             </p>
           </div>
 
@@ -72,9 +72,9 @@ export function NewsTable({
             </TableHead>
             <TableHead
               className="cursor-pointer text-center hover:text-primary transition-colors"
-              onClick={() => onSort("created_at")}
+              onClick={() => onSort("published_at")}
             >
-              Posted At {getSortIndicator("created_at")}
+              Published At {getSortIndicator("published_at")}
             </TableHead>
             <TableHead className="text-center">Actions</TableHead>
           </TableRow>
@@ -120,7 +120,9 @@ export function NewsTable({
                 <TableCell>{article.category}</TableCell>
                 <TableCell>{article.source_name}</TableCell>
                 <TableCell>
-                  <RelativeTime date={article.created_at} />
+                  <RelativeTime
+                    date={article.published_at ?? article.created_at}
+                  />
                 </TableCell>
                 <TableCell>
                   <ActionButtons
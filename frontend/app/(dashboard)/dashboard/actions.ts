@@ -37,7 +37,7 @@ export async function getNews(filters: NewsFilters): Promise<NewsListResponse> {
       limit = 20,
       offset = 0,
       query,
-      sort = "created_at",
+      sort = "published_at",
       order = "desc",
       category,
       source,
@@ -59,8 +59,16 @@ export async function getNews(filters: NewsFilters): Promise<NewsListResponse> {
       queryBuilder = queryBuilder.eq("source_name", source);
     }
 
-    // Apply sorting
-    queryBuilder = queryBuilder.order(sort, { ascending: order === "asc" });
+    // Apply sorting, stories without a publish time sort by their crawl time
+    queryBuilder = queryBuilder.order(sort, {
+      ascending: order === "asc",
+      nullsFirst: false,
+    });
+    if (sort === "published_at") {
+      queryBuilder = queryBuilder.order("created_at", {
+        ascending: order === "asc",
+      });
+    }
 
     // Apply pagination
     queryBuilder = queryBuilder.range(offset, offset + limit - 1);
@@ -22,7 +22,7 @@ function DashboardContent() {
   const [totalCount, setTotalCount] = useState(0);
   const [currentPage, setCurrentPage] = useState(1);
   const [searchTerm, setSearchTerm] = useState("");
-  const [sortColumn, setSortColumn] = useState<keyof NewsArticle>("created_at");
+  const [sortColumn, setSortColumn] = useState<keyof NewsArticle>("published_at");
   const [sortDirection, setSortDirection] = useState<"asc" | "desc">("desc");
   const [selectedCategory, setSelectedCategory] =
     useState<string>("Categories");
@@ -10,10 +10,49 @@ export interface NewsArticle {
   source_link: string; // Unique constraint in SQL
   meta_description: string | null; // Made nullable to match SQL schema
   meta_keywords: string | null; // Made nullable to match SQL schema
+  language: string | null; // ISO 639-1 code of the story language
+  summary_method: "llm" | "extractive" | null; // How the summary was produced
+  consistency_score: number | null; // Share of summary facts found in the story
+  unsupported_claims: string[] | null; // Summary facts not found in the story
+  verification_status: "VERIFIED" | "DEVELOPING" | "UNVERIFIED" | "DISPUTED" | null; // From the sources of the same story
+  model_verification_status: string | null; // Guessed by the AI from the story alone
+  corroborating_sources: number | null; // Independent sources reporting the story
+  disputed_facts: string[] | null; // Numbers the sources disagree on
+  duplicate_of: string | null; // Source link of the story this one nearly duplicates
+  duplicate_similarity: number | null;
+  revision: number; // Number of versions of the story, above 1 when updated
+  published_at: string | null; // When the publisher first published the story
+  modified_at: string | null; // When the publisher last updated the story
+  revised_at: string | null; // When a re-crawl found the story changed
+  retracted: boolean; // Deleted or unpublished by the publisher
+  retracted_at: string | null;
+  retraction_reason: "not_found" | "gone" | "home_redirect" | "removed_notice" | null;
   created_at: string; // Made required as it's NOT NULL in SQL
   updated_at: string; // Added to match SQL schema
 }
 
+export interface NewsTranslation {
+  source_link: string;
+  language: string; // ISO 639-1 code of the translation
+  headline: string;
+  summary: string | null;
+  bullet_points: string[] | null;
+  model: string | null;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface ArticleRevision {
+  source_link: string;
+  revision: number;
+  headline: string;
+  story: string;
+  content_hash: string | null;
+  diff: string | null; // "- " removed and "+ " added sentences
+  modified_at: string | null;
+  created_at: string;
+}
+
 export interface NewsFilters {
   limit?: number;
   offset?: number;
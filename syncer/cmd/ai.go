@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"ncrawler/internal/ai"
+	"ncrawler/internal/crawler"
+	"ncrawler/pkg/helpers"
+
+	"github.com/spf13/cobra"
+)
+
+var aiUsageSince time.Duration
+
+// aiCmd represents the ai command
+var aiCmd = &cobra.Command{
+	Use:   "ai",
+	Short: "Manage the AI enrichment",
+	Long:  `Inspect and manage the AI summarization of the news data`,
+}
+
+// aiUsageCmd represents the ai usage command
+var aiUsageCmd = &cobra.Command{
+	Use:   "usage",
+	Short: "Report the AI spend",
+	Long:  `Print the AI token usage and cost by day and source`,
+	Run: func(cmd *cobra.Command, args []string) {
+		dbPool := helpers.GetDbPool()
+		since := helpers.StartOfDay(time.Now().UTC().Add(-aiUsageSince))
+
+		summaries, err := dbPool.AIUsage.GetUsageSummary(since)
+		if err != nil {
+			slog.Error("error at getting ai usage", "error", err)
+			return
+		}
+
+		var (
+			total     float64
+			bySource  = map[string]float64{}
+			sourceOrd []string
+		)
+
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "day\tsource\trequests\tprompt tokens\tcompletion tokens\tcost (USD)")
+		for _, us := range summaries {
+			fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%d\t%.4f\n",
+				us.Day.Format(time.DateOnly), us.SourceName, us.Requests, us.PromptTokens, us.CompletionTokens, us.CostUSD)
+
+			if _, ok := bySource[us.SourceName]; !ok {
+				sourceOrd = append(sourceOrd, us.SourceName)
+			}
+			bySource[us.SourceName] += us.CostUSD
+			total += us.CostUSD
+		}
+		fmt.Fprintln(tw)
+		for _, source := range sourceOrd {
+			fmt.Fprintf(tw, "total\t%s\t\t\t\t%.4f\n", source, bySource[source])
+		}
+		fmt.Fprintf(tw, "total\t\t\t\t\t%.4f\n", total)
+
+		if err := tw.Flush(); err != nil {
+			slog.Error("error at writing ai usage", "error", err)
+		}
+	},
+}
+
+var (
+	aiBatchLimit int
+	aiBatchWait  bool
+	aiBatchPoll  time.Duration
+)
+
+// aiBatchCmd represents the ai batch command
+var aiBatchCmd = &cobra.Command{
+	Use:   "batch",
+	Short: "Summarize pending articles with the Batch API",
+	Long: `Summarize the stored articles without a summary through the OpenAI Batch API.
+Open batches of a previous run are resumed, so the command can be restarted at any time.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := crawler.RunBatch(ai.NewBatchClient(), aiBatchLimit, aiBatchWait, aiBatchPoll); err != nil {
+			slog.Error("error at running batch", "error", err)
+		}
+	},
+}
+
+func init() {
+	aiCmd.PersistentFlags().StringVar(&ai.BaseURL, "openai-base-url", "", "address of the OpenAI API, e.g. a local stub server")
+
+	aiUsageCmd.Flags().DurationVarP(&aiUsageSince, "since", "s", 30*24*time.Hour, "report the usage of this period")
+
+	aiBatchCmd.Flags().IntVarP(&aiBatchLimit, "limit", "l", 1000, "maximum number of articles of a new batch")
+	aiBatchCmd.Flags().BoolVarP(&aiBatchWait, "wait", "w", false, "poll until all the batches are applied")
+	aiBatchCmd.Flags().DurationVar(&aiBatchPoll, "poll", time.Minute, "interval between two polls")
+
+	aiCmd.AddCommand(aiUsageCmd)
+	aiCmd.AddCommand(aiBatchCmd)
+	rootCmd.AddCommand(aiCmd)
+}
@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"ncrawler/internal/backup"
+	"ncrawler/internal/crawler"
+	"ncrawler/pkg/helpers"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	backupDatastore   string
+	restoreDatastore  string
+	restoreVerifyOnly bool
+)
+
+// backupCmd represents the backup command
+var backupCmd = &cobra.Command{
+	Use:   "backup [file]",
+	Short: "Back up the news data",
+	Long: `Write the news with their entities, revisions, translations and story clusters, the archived news, the
+tombstones of the deleted news, the AI usage and the sync runs of a datastore to a single gzip compressed and checksummed archive, readable by restore into either datastore.
+Without a file the archive is written to ` + crawler.BACKUP_DIR,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		datastore := datastoreName(backupDatastore)
+
+		var path string
+		if len(args) > 0 {
+			path = args[0]
+		} else {
+			if err := os.MkdirAll(crawler.BACKUP_DIR, 0o755); err != nil {
+				slog.Error("error at creating backup directory", "error", err)
+				return
+			}
+			path = filepath.Join(crawler.BACKUP_DIR, "ncrawler-"+datastore+"-"+time.Now().UTC().Format("20060102T150405Z")+".backup.gz")
+		}
+
+		report, err := crawler.RunBackup(datastore, path)
+		if err != nil {
+			slog.Error("error at backing up news data", "error", err)
+			return
+		}
+
+		printBackupReport(report)
+		fmt.Printf("backed up to %s\n", path)
+	},
+}
+
+// restoreCmd represents the restore command
+var restoreCmd = &cobra.Command{
+	Use:   "restore <file>",
+	Short: "Restore the news data from a backup",
+	Long: `Load a backup archive into an empty, migrated datastore. The archive is checked against its checksum
+and row counts before anything is restored, and the rows are counted again once restored.
+The tables sqlite3 does not keep, like the revisions, are skipped`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		report, err := crawler.RunRestore(datastoreName(restoreDatastore), args[0], restoreVerifyOnly)
+		if !report.Header.CreatedAt.IsZero() {
+			fmt.Printf("backup of %s at schema version %d, created %s, format version %d\n", report.Header.Datastore,
+				report.Header.SchemaVersion, report.Header.CreatedAt.Format(time.DateTime), report.Header.Version)
+		}
+		if err != nil {
+			slog.Error("error at restoring news data", "error", err)
+			return
+		}
+
+		printBackupReport(report)
+		if restoreVerifyOnly {
+			fmt.Println("backup is intact")
+		}
+	},
+}
+
+// printBackupReport lists the rows backed up, restored and skipped of every table
+func printBackupReport(report crawler.BackupReport) {
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "table\trows\tskipped")
+	for _, table := range backup.Tables {
+		fmt.Fprintf(tw, "%s\t%d\t%d\n", table, report.Rows[table], report.Skipped[table])
+	}
+
+	if err := tw.Flush(); err != nil {
+		slog.Error("error at writing backup report", "error", err)
+	}
+}
+
+func init() {
+	backupCmd.Flags().StringVarP(&backupDatastore, "datastore", "d", helpers.DATASTORE_PG, "datastore to back up, pg or sqlite3")
+	restoreCmd.Flags().StringVarP(&restoreDatastore, "datastore", "d", helpers.DATASTORE_PG, "datastore to restore into, pg or sqlite3")
+	restoreCmd.Flags().BoolVar(&restoreVerifyOnly, "verify-only", false, "only check the archive")
+
+	rootCmd.AddCommand(backupCmd)
+	rootCmd.AddCommand(restoreCmd)
+}
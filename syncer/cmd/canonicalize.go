@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+
+	"ncrawler/internal/crawler"
+	"ncrawler/pkg/helpers"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	canonicalizeDatastore string
+	canonicalizeDryRun    bool
+)
+
+// canonicalizeCmd represents the canonicalize command
+var canonicalizeCmd = &cobra.Command{
+	Use:   "canonicalize",
+	Short: "Canonicalise the links of the stored news",
+	Long: `Rewrite the links of the news stored before the links were canonicalised, like edition.cnn.com
+or AMP links, to their canonical form so sync does not crawl them again. A story already stored under
+its canonical link is merged with it. Run it once after upgrading`,
+	Run: func(cmd *cobra.Command, args []string) {
+		report, err := crawler.RunCanonicalize(datastoreName(canonicalizeDatastore), canonicalizeDryRun)
+		verb := "canonicalized"
+		if canonicalizeDryRun {
+			verb = "would canonicalize"
+		}
+		fmt.Printf("checked %d, %s: moved %d, merged %d\n", report.Checked, verb, report.Moved, report.Merged)
+		if err != nil {
+			slog.Error("error at canonicalizing news links", "error", err)
+		}
+	},
+}
+
+func init() {
+	canonicalizeCmd.Flags().StringVarP(&canonicalizeDatastore, "datastore", "d", helpers.DATASTORE_PG, "datastore to canonicalise, pg or sqlite3")
+	canonicalizeCmd.Flags().BoolVar(&canonicalizeDryRun, "dry-run", false, "only report what would be canonicalised")
+
+	rootCmd.AddCommand(canonicalizeCmd)
+}
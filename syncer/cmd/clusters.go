@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"ncrawler/internal/crawler"
+	"ncrawler/pkg/helpers"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	clustersSince      time.Duration
+	clustersMinSources int
+	clustersLimit      int
+)
+
+// clustersCmd represents the clusters command
+var clustersCmd = &cobra.Command{
+	Use:   "clusters",
+	Short: "Inspect the story clusters",
+	Long:  `Inspect the clusters grouping the articles of different sources about the same story`,
+	Run: func(cmd *cobra.Command, args []string) {
+		since := time.Now().Add(-clustersSince)
+		clusters, err := helpers.GetDbPool().Clusters.GetClusters(since, clustersMinSources, clustersLimit)
+		if err != nil {
+			slog.Error("error at getting clusters", "error", err)
+			return
+		}
+
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "id\tarticles\tsources\tfirst seen\tlast seen\theadline")
+		for _, c := range clusters {
+			fmt.Fprintf(tw, "%d\t%d\t%d\t%s\t%s\t%s\n",
+				c.Id, c.Size, c.Sources, c.FirstSeen.Format(time.DateTime), c.LastSeen.Format(time.DateTime), c.Headline)
+		}
+
+		if err := tw.Flush(); err != nil {
+			slog.Error("error at writing clusters", "error", err)
+		}
+	},
+}
+
+// clustersShowCmd represents the clusters show command
+var clustersShowCmd = &cobra.Command{
+	Use:   "show <id>",
+	Short: "List the articles of a cluster",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		id, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil {
+			slog.Error("error at parsing cluster id", "error", err)
+			return
+		}
+
+		members, err := helpers.GetDbPool().Clusters.GetClusterMembers(id)
+		if err != nil {
+			slog.Error("error at getting cluster members", "error", err)
+			return
+		}
+
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "source\tsimilarity\tlink")
+		for _, m := range members {
+			fmt.Fprintf(tw, "%s\t%.2f\t%s\n", m.SourceName, m.Similarity, m.SourceLink)
+		}
+
+		if err := tw.Flush(); err != nil {
+			slog.Error("error at writing cluster members", "error", err)
+		}
+	},
+}
+
+// clustersBuildCmd represents the clusters build command
+var clustersBuildCmd = &cobra.Command{
+	Use:   "build",
+	Short: "Cluster the recent articles",
+	Long:  `Add the recent articles that belong to no cluster yet to the story clusters`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := crawler.RunClustering(); err != nil {
+			slog.Error("error at clustering news", "error", err)
+		}
+	},
+}
+
+// clustersVerifyCmd represents the clusters verify command
+var clustersVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Recompute the verification status",
+	Long:  `Recompute the verification status of the recent articles from the sources reporting the same story`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := crawler.RunCorroboration(); err != nil {
+			slog.Error("error at corroborating news", "error", err)
+		}
+	},
+}
+
+func init() {
+	clustersCmd.Flags().DurationVarP(&clustersSince, "since", "s", 24*time.Hour, "list the clusters seen within this duration")
+	clustersCmd.Flags().IntVarP(&clustersMinSources, "min-sources", "m", 1, "list the clusters covered by at least this many sources")
+	clustersCmd.Flags().IntVarP(&clustersLimit, "limit", "l", 20, "number of clusters to list")
+
+	clustersCmd.AddCommand(clustersShowCmd)
+	clustersCmd.AddCommand(clustersBuildCmd)
+	clustersCmd.AddCommand(clustersVerifyCmd)
+	rootCmd.AddCommand(clustersCmd)
+}
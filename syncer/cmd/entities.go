@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"ncrawler/internal/crawler"
+	"ncrawler/pkg/helpers"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	entitiesSince time.Duration
+	entitiesType  string
+	entitiesLimit int
+)
+
+// entitiesCmd represents the entities command
+var entitiesCmd = &cobra.Command{
+	Use:   "entities",
+	Short: "Query the entity index",
+	Long:  `Query the people, organizations, locations and events mentioned in the news`,
+}
+
+// entitiesTopCmd represents the entities top command
+var entitiesTopCmd = &cobra.Command{
+	Use:   "top",
+	Short: "List the most mentioned entities",
+	Run: func(cmd *cobra.Command, args []string) {
+		since := time.Now().Add(-entitiesSince)
+		counts, err := helpers.GetDbPool().Entities.GetTopEntities(since, entitiesType, entitiesLimit)
+		if err != nil {
+			slog.Error("error at getting top entities", "error", err)
+			return
+		}
+
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "entity\ttype\tarticles\tlast seen\taliases")
+		for _, ec := range counts {
+			fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%s\n",
+				ec.Name, ec.Type, ec.Articles, ec.LastSeen.Format(time.DateTime), strings.Join(ec.Aliases, ", "))
+		}
+
+		if err := tw.Flush(); err != nil {
+			slog.Error("error at writing top entities", "error", err)
+		}
+	},
+}
+
+// entitiesNewsCmd represents the entities news command
+var entitiesNewsCmd = &cobra.Command{
+	Use:   "news <name>",
+	Short: "List the news mentioning an entity",
+	Long:  `List the latest news mentioning the entity, found by its name or one of its aliases`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		newsStories, err := helpers.GetDbPool().Entities.GetNewsByEntity(args[0], entitiesLimit)
+		if err != nil {
+			slog.Error("error at getting news of entity", "error", err)
+			return
+		}
+
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "source\theadline\tlink")
+		for _, news := range newsStories {
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", news.SourceName, news.Headline, news.SourceLink)
+		}
+
+		if err := tw.Flush(); err != nil {
+			slog.Error("error at writing news of entity", "error", err)
+		}
+	},
+}
+
+// entitiesExtractCmd represents the entities extract command
+var entitiesExtractCmd = &cobra.Command{
+	Use:   "extract",
+	Short: "Extract the entities of stored news",
+	Long:  `Extract the entities of the stored news that have none yet`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := crawler.RunEntities(entitiesLimit); err != nil {
+			slog.Error("error at extracting entities", "error", err)
+		}
+	},
+}
+
+func init() {
+	entitiesTopCmd.Flags().DurationVarP(&entitiesSince, "since", "s", 24*time.Hour, "count the news crawled within this duration")
+	entitiesTopCmd.Flags().StringVarP(&entitiesType, "type", "t", "", "only count entities of this type (person, organization, location, event)")
+	entitiesCmd.PersistentFlags().IntVarP(&entitiesLimit, "limit", "l", 20, "number of rows")
+
+	entitiesCmd.AddCommand(entitiesTopCmd)
+	entitiesCmd.AddCommand(entitiesNewsCmd)
+	entitiesCmd.AddCommand(entitiesExtractCmd)
+	rootCmd.AddCommand(entitiesCmd)
+}
@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"encoding/json"
+	"log/slog"
+	"os"
+
+	"ncrawler/internal/ai"
+	"ncrawler/internal/eval"
+	"ncrawler/pkg/helpers"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	evalDataset  string
+	evalModelA   string
+	evalPromptA  string
+	evalModelB   string
+	evalPromptB  string
+	evalJSONFile string
+	evalCategory string
+	evalLimit    int
+	evalBudget   int
+)
+
+// evalCmd represents the eval command
+var evalCmd = &cobra.Command{
+	Use:   "eval",
+	Short: "Evaluate the summary quality",
+	Long: `Run two prompt/model versions of the summarizer over a frozen set of
+stored articles and print a comparison of the automatic quality metrics`,
+	Run: func(cmd *cobra.Command, args []string) {
+		ds, err := eval.LoadDataset(evalDataset)
+		if err != nil {
+			slog.Error("error at loading dataset", "error", err)
+			return
+		}
+
+		optsA, err := ai.ResolveOptions(evalModelA, evalPromptA)
+		if err != nil {
+			slog.Error("error at resolving version a", "error", err)
+			return
+		}
+		optsB, err := ai.ResolveOptions(evalModelB, evalPromptB)
+		if err != nil {
+			slog.Error("error at resolving version b", "error", err)
+			return
+		}
+
+		optsA.MaxInputTokens = evalBudget
+		optsB.MaxInputTokens = evalBudget
+		// the cached summary of an article may be its frozen reference, so
+		// both versions always ask the model
+		optsA.NoCache = true
+		optsB.NoCache = true
+
+		report := eval.Compare(ds, optsA, optsB)
+		if err := report.WriteText(os.Stdout); err != nil {
+			slog.Error("error at writing report", "error", err)
+		}
+
+		if evalJSONFile != "" {
+			content, err := json.MarshalIndent(report, "", "  ")
+			if err != nil {
+				slog.Error("error at encoding report", "error", err)
+				return
+			}
+			if err := os.WriteFile(evalJSONFile, content, 0o644); err != nil {
+				slog.Error("error at saving report", "error", err)
+				return
+			}
+			slog.Info("report saved to", "file", evalJSONFile)
+		}
+	},
+}
+
+// evalFreezeCmd represents the eval freeze command
+var evalFreezeCmd = &cobra.Command{
+	Use:   "freeze",
+	Short: "Freeze stored articles into an evaluation dataset",
+	Long:  `Save the latest stored articles with their summaries as the evaluation dataset`,
+	Run: func(cmd *cobra.Command, args []string) {
+		dbPool := helpers.GetDbPool()
+		newsStories, err := dbPool.News.GetNews(evalCategory, evalLimit)
+		if err != nil {
+			slog.Error("error at getting news", "error", err)
+			return
+		}
+
+		if err := eval.NewDataset(newsStories).Save(evalDataset); err != nil {
+			slog.Error("error at saving dataset", "error", err)
+			return
+		}
+
+		slog.Info("dataset saved to", "file", evalDataset, "articles", len(newsStories))
+	},
+}
+
+func init() {
+	evalCmd.PersistentFlags().StringVarP(&evalDataset, "dataset", "d", "eval_dataset.json", "path of the evaluation dataset")
+
+	evalCmd.Flags().StringVar(&evalModelA, "model-a", "", "model of version a (default model when empty)")
+	evalCmd.Flags().StringVar(&evalPromptA, "prompt-a", ai.DEFAULT_PROMPT_VERSION, "prompt version or prompt file of version a")
+	evalCmd.Flags().StringVar(&evalModelB, "model-b", "", "model of version b (default model when empty)")
+	evalCmd.Flags().StringVar(&evalPromptB, "prompt-b", ai.DEFAULT_PROMPT_VERSION, "prompt version or prompt file of version b")
+	evalCmd.Flags().IntVar(&evalBudget, "max-input-tokens", ai.MAX_INPUT_TOKENS, "token budget of the summary request input")
+	evalCmd.Flags().StringVarP(&evalJSONFile, "output", "o", "", "also save the full report as JSON to this file")
+
+	evalFreezeCmd.Flags().StringVarP(&evalCategory, "category", "c", "", "which category to freeze")
+	evalFreezeCmd.Flags().IntVarP(&evalLimit, "limit", "l", 50, "number of articles to freeze")
+
+	evalCmd.AddCommand(evalFreezeCmd)
+	rootCmd.AddCommand(evalCmd)
+}
@@ -1,68 +1,115 @@
 package cmd
 
-// import (
-// 	"fmt"
-// 	"log/slog"
-// 	"os"
-// 	"strings"
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
 
-// 	"ncrawler/pkg/helpers"
+	"ncrawler/internal/dto"
+	"ncrawler/internal/export"
+	"ncrawler/pkg/helpers"
 
-// 	"github.com/gocarina/gocsv"
-// 	"github.com/spf13/cobra"
-// )
+	"github.com/spf13/cobra"
+)
 
-// var (
-// 	category string
-// 	limit    int
-// )
+var (
+	exportDatastore string
+	exportFormat    string
+	exportOutput    string
+	exportQuery     dto.NewsQuery
+	exportFrom      string
+	exportTo        string
+	exportLimit     int
+)
 
-// // exportCmd represents the export command
-// var exportCmd = &cobra.Command{
-// 	Use:   "export",
-// 	Short: "Export the news data",
-// 	Long:  `Export the news data to a file`,
-// 	Run: func(cmd *cobra.Command, args []string) {
-// 		err := exportCsv(category, limit)
-// 		if err != nil {
-// 			slog.Error("error at exporting news data", "error", err)
-// 		}
-// 	},
-// }
+// exportCmd represents the export command
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the news data",
+	Long: `Export the news data to stdout or a file as CSV, JSON, NDJSON, Markdown or Parquet.
+The news are read and written page by page, so large exports are streamed`,
+	Run: func(cmd *cobra.Command, args []string) {
+		q := exportQuery
 
-// func init() {
-// 	exportCmd.Flags().StringVarP(&category, "category", "c", "", "which category to export")
-// 	exportCmd.Flags().IntVarP(&limit, "limit", "l", 0, "number of news to export")
+		var err error
+		if q.From, err = parseSearchTime(exportFrom); err != nil {
+			slog.Error("error at parsing from", "error", err)
+			return
+		}
+		if q.To, err = parseSearchTime(exportTo); err != nil {
+			slog.Error("error at parsing to", "error", err)
+			return
+		}
 
-// 	rootCmd.AddCommand(exportCmd)
-// }
+		if err := exportNews(q); err != nil {
+			slog.Error("error at exporting news data", "error", err)
+		}
+	},
+}
 
-// func exportCsv(category string, limit int) error {
-// 	dbPool := helpers.GetDbPool()
-// 	newsStories, err := dbPool.News.GetNews(category, limit)
-// 	if err != nil {
-// 		return err
-// 	}
+func init() {
+	exportCmd.Flags().StringVarP(&exportDatastore, "datastore", "d", helpers.DATASTORE_PG, "datastore to export, pg or sqlite3")
+	exportCmd.Flags().StringVarP(&exportFormat, "format", "f", export.FORMAT_CSV, "export format, one of "+strings.Join(export.Formats, ", "))
+	exportCmd.Flags().StringVarP(&exportOutput, "output", "o", "", "file to write, stdout when empty or -")
+	exportCmd.Flags().StringSliceVarP(&exportQuery.Sources, "source", "s", nil, "source names")
+	exportCmd.Flags().StringSliceVarP(&exportQuery.Categories, "category", "c", nil, "categories")
+	exportCmd.Flags().StringVar(&exportFrom, "from", "", "earliest time, a date or an RFC 3339 time")
+	exportCmd.Flags().StringVar(&exportTo, "to", "", "time before which to stop, a date or an RFC 3339 time")
+	exportCmd.Flags().StringVarP(&exportQuery.Text, "text", "t", "", "search text")
+	exportCmd.Flags().StringVar(&exportQuery.Sort, "sort", dto.SORT_PUBLISHED, "sort by published or crawled time")
+	exportCmd.Flags().BoolVar(&exportQuery.Ascending, "asc", false, "oldest first")
+	exportCmd.Flags().IntVarP(&exportLimit, "limit", "l", 0, "number of news to export, all when 0")
 
-// 	// Get the current timestamp and replace invalid characters for Windows
-// 	timestamp := helpers.GetCurrentTimestamp()
-// 	safeTimestamp := strings.ReplaceAll(timestamp, ":", "-")
+	rootCmd.AddCommand(exportCmd)
+}
 
-// 	// generate file name with category, limit and timestamp
-// 	fileName := fmt.Sprintf("news-%s-%d-%s.csv", category, limit, safeTimestamp)
+// exportNews writes the news of the query, the format is checked before the
+// file is created so a bad flag leaves no empty file behind
+func exportNews(q dto.NewsQuery) error {
+	if !slices.Contains(export.Formats, exportFormat) {
+		return fmt.Errorf("unknown export format %s, use one of %s", exportFormat, strings.Join(export.Formats, ", "))
+	}
 
-// 	csvFile, err := os.OpenFile(fileName, os.O_RDWR|os.O_CREATE, os.ModePerm)
-// 	if err != nil {
-// 		return err
-// 	}
-// 	defer csvFile.Close()
+	var (
+		out  io.Writer = os.Stdout
+		file *os.File
+	)
+	if exportOutput != "" && exportOutput != "-" {
+		var err error
+		if file, err = os.Create(exportOutput); err != nil {
+			return err
+		}
+		// closes the file on the error returns, it is closed below once written
+		defer file.Close()
+		out = file
+	}
 
-// 	err = gocsv.MarshalFile(&newsStories, csvFile)
-// 	if err != nil {
-// 		return err
-// 	}
+	buf := bufio.NewWriter(out)
+	w, err := export.NewWriter(exportFormat, buf)
+	if err != nil {
+		return err
+	}
 
-// 	slog.Info("news data saved to", "file", fileName)
+	written, err := export.Export(q, exportLimit, func(q dto.NewsQuery) (dto.NewsPage, error) {
+		return helpers.QueryNews(exportDatastore, q)
+	}, w)
+	if err != nil {
+		return err
+	}
+	if err := buf.Flush(); err != nil {
+		return fmt.Errorf("error writing export: %w", err)
+	}
 
-// 	return nil
-// }
+	if file != nil {
+		if err := file.Close(); err != nil {
+			return fmt.Errorf("error closing export: %w", err)
+		}
+		slog.Info("news data saved to", "file", exportOutput, "count", written)
+	}
+
+	return nil
+}
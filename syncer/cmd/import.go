@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"ncrawler/internal/crawler"
+	"ncrawler/internal/importer"
+	"ncrawler/pkg/helpers"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	importFormat     string
+	importFromSqlite bool
+	importOptions    crawler.ImportOptions
+)
+
+// importCmd represents the import command
+var importCmd = &cobra.Command{
+	Use:   "import [file]",
+	Short: "Import news from a dump",
+	Long: `Import the news of a CSV, JSON or NDJSON dump, like the export writes, into a datastore.
+Gzip compressed dumps like the archives of prune are read too.
+The stories are validated, their links canonicalised and upserted by link.
+With --from-sqlite the stories of the local sqlite3 database are imported into Postgres`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if importFromSqlite {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.ExactArgs(1)(cmd, args)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		var (
+			report crawler.ImportReport
+			err    error
+		)
+		if importFromSqlite {
+			report, err = crawler.RunSqliteImport(importOptions)
+		} else {
+			report, err = importFile(args[0])
+		}
+
+		fmt.Printf("read %d, invalid %d, inserted %d, updated %d, skipped %d, entities %d, queued for summary %d\n",
+			report.Read, report.Invalid, report.Inserted, report.Updated, report.Skipped, report.Entities, report.Queued)
+		if err != nil {
+			slog.Error("error at importing news data", "error", err)
+		}
+	},
+}
+
+func init() {
+	importCmd.Flags().StringVarP(&importFormat, "format", "f", "", "dump format, one of "+strings.Join(importer.Formats, ", ")+", guessed from the file extension when empty")
+	importCmd.Flags().BoolVar(&importFromSqlite, "from-sqlite", false, "import the local sqlite3 database into pg")
+	importCmd.Flags().StringVarP(&importOptions.Datastore, "datastore", "d", helpers.DATASTORE_PG, "datastore to import into, pg or sqlite3")
+	importCmd.Flags().StringVar(&importOptions.Policy, "policy", crawler.MERGE_POLICY, "merge policy of the stored stories, keep, overwrite or fill_empty")
+	importCmd.Flags().BoolVar(&importOptions.Enrich, "enrich", false, "detect the language, extract the entities and queue the summaries of the imported stories")
+
+	rootCmd.AddCommand(importCmd)
+}
+
+// importFile imports the dump, - reads stdin
+func importFile(path string) (crawler.ImportReport, error) {
+	// archives written by prune are gzip compressed
+	name := path
+	compressed := strings.EqualFold(filepath.Ext(path), ".gz")
+	if compressed {
+		name = strings.TrimSuffix(path, filepath.Ext(path))
+	}
+
+	format := importFormat
+	if format == "" {
+		switch strings.ToLower(filepath.Ext(name)) {
+		case ".csv":
+			format = importer.FORMAT_CSV
+		case ".json":
+			format = importer.FORMAT_JSON
+		case ".ndjson", ".jsonl":
+			format = importer.FORMAT_NDJSON
+		default:
+			return crawler.ImportReport{}, fmt.Errorf("unknown format of %s, set --format", path)
+		}
+	}
+
+	var in io.Reader = os.Stdin
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return crawler.ImportReport{}, err
+		}
+		defer file.Close()
+		in = file
+	}
+	if compressed {
+		gz, err := gzip.NewReader(in)
+		if err != nil {
+			return crawler.ImportReport{}, fmt.Errorf("error reading %s: %w", path, err)
+		}
+		defer gz.Close()
+		in = gz
+	}
+
+	reader, err := importer.NewReader(format, bufio.NewReader(in))
+	if err != nil {
+		return crawler.ImportReport{}, err
+	}
+
+	return crawler.RunImport(reader, importOptions)
+}
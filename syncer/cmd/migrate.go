@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"text/tabwriter"
+
+	"ncrawler/internal/migrate"
+	"ncrawler/pkg/helpers"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	migrateDatastore string
+	migrateSteps     int
+)
+
+// migrateCmd represents the migrate command
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Migrate the database schema",
+	Long:  `Apply or revert the schema migrations embedded in the binary`,
+}
+
+// migrateUpCmd represents the migrate up command
+var migrateUpCmd = &cobra.Command{
+	Use:   "up",
+	Short: "Apply the pending migrations",
+	Run: func(cmd *cobra.Command, args []string) {
+		migrator, err := helpers.GetMigrator(migrateDatastore)
+		if err != nil {
+			slog.Error("error at loading migrations", "error", err)
+			return
+		}
+
+		applied, err := migrator.Up()
+		for _, m := range applied {
+			fmt.Printf("applied %d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			slog.Error("error at applying migrations", "error", err)
+			return
+		}
+		if len(applied) == 0 {
+			fmt.Println("schema is up to date")
+		}
+	},
+}
+
+// migrateDownCmd represents the migrate down command
+var migrateDownCmd = &cobra.Command{
+	Use:   "down",
+	Short: "Revert the last migrations",
+	Run: func(cmd *cobra.Command, args []string) {
+		migrator, err := helpers.GetMigrator(migrateDatastore)
+		if err != nil {
+			slog.Error("error at loading migrations", "error", err)
+			return
+		}
+
+		reverted, err := migrator.Down(migrateSteps)
+		for _, m := range reverted {
+			fmt.Printf("reverted %d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			slog.Error("error at reverting migrations", "error", err)
+		}
+	},
+}
+
+// migrateStatusCmd represents the migrate status command
+var migrateStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the applied and pending migrations",
+	Run: func(cmd *cobra.Command, args []string) {
+		migrator, err := helpers.GetMigrator(migrateDatastore)
+		if err != nil {
+			slog.Error("error at loading migrations", "error", err)
+			return
+		}
+
+		status, err := migrator.Status()
+		if err != nil {
+			slog.Error("error at getting schema status", "error", err)
+			return
+		}
+
+		fmt.Printf("version %d of %d", status.Current, status.Latest)
+		if status.Dirty {
+			fmt.Print(" (dirty)")
+		}
+		fmt.Println()
+
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "version\tname\tstatus")
+		printMigrations(tw, status.Applied, "applied")
+		printMigrations(tw, status.Pending, "pending")
+
+		if err := tw.Flush(); err != nil {
+			slog.Error("error at writing schema status", "error", err)
+		}
+	},
+}
+
+func printMigrations(tw *tabwriter.Writer, migrations []migrate.Migration, state string) {
+	for _, m := range migrations {
+		fmt.Fprintf(tw, "%d\t%s\t%s\n", m.Version, m.Name, state)
+	}
+}
+
+func init() {
+	migrateCmd.PersistentFlags().StringVarP(&migrateDatastore, "datastore", "d", helpers.DATASTORE_PG, "datastore to migrate, pg or sqlite3")
+	migrateDownCmd.Flags().IntVarP(&migrateSteps, "steps", "n", 1, "number of migrations to revert")
+
+	migrateCmd.AddCommand(migrateUpCmd)
+	migrateCmd.AddCommand(migrateDownCmd)
+	migrateCmd.AddCommand(migrateStatusCmd)
+	rootCmd.AddCommand(migrateCmd)
+}
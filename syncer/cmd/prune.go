@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"ncrawler/internal/crawler"
+	"ncrawler/pkg/helpers"
+
+	"github.com/spf13/cobra"
+)
+
+var pruneOptions crawler.PruneOptions
+
+// pruneCmd represents the prune command
+var pruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Apply the retention rules to the news",
+	Long: `Drop the story text of the old news keeping their summary and metadata, move the older news to
+the news_archive table or gzip compressed NDJSON files and purge the old revisions, according to the
+rules of ` + crawler.RETENTION_FILE + `. Sync prunes after every run as well`,
+	Run: func(cmd *cobra.Command, args []string) {
+		opts := pruneOptions
+		opts.Datastore = datastoreName(opts.Datastore)
+
+		report, err := crawler.RunPrune(opts)
+		if len(report.Results) > 0 {
+			printPruneReport(report)
+		}
+		if err != nil {
+			slog.Error("error at pruning news data", "error", err)
+			return
+		}
+
+		stripped, archived, revisions := report.Totals()
+		verb := "pruned"
+		if opts.DryRun {
+			verb = "would prune"
+		}
+		fmt.Printf("%s: stripped %d, archived %d, revisions %d\n", verb, stripped, archived, revisions)
+		if report.File != "" {
+			fmt.Printf("archived to %s\n", report.File)
+		}
+	},
+}
+
+// printPruneReport lists what was pruned of every source and category
+func printPruneReport(report crawler.PruneReport) {
+	days := func(n int) string {
+		if n <= 0 {
+			return "-"
+		}
+		return strconv.Itoa(n)
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "source\tcategory\tnews\tstory days\tarchive days\tmax revisions\tstripped\tarchived\trevisions")
+	for _, r := range report.Results {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%s\t%s\t%d\t%d\t%d\n", r.Source, r.Category, r.Count,
+			days(r.Rule.StoryDays), days(r.Rule.ArchiveDays), days(r.Rule.MaxRevisions), r.Stripped, r.Archived, r.Revisions)
+	}
+
+	if err := tw.Flush(); err != nil {
+		slog.Error("error at writing prune report", "error", err)
+	}
+}
+
+func init() {
+	pruneCmd.Flags().StringVarP(&pruneOptions.Datastore, "datastore", "d", helpers.DATASTORE_PG, "datastore to prune, pg or sqlite3")
+	pruneCmd.Flags().StringVar(&pruneOptions.ArchiveTo, "archive", crawler.ARCHIVE_TO, "where the old news are moved, table or ndjson")
+	pruneCmd.Flags().BoolVar(&pruneOptions.DryRun, "dry-run", false, "only report what would be pruned")
+
+	rootCmd.AddCommand(pruneCmd)
+}
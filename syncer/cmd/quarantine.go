@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"ncrawler/pkg/helpers"
+
+	"github.com/spf13/cobra"
+)
+
+var quarantineLimit int
+
+// quarantineCmd represents the quarantine command
+var quarantineCmd = &cobra.Command{
+	Use:   "quarantine",
+	Short: "Review the quarantined news",
+	Long:  `List and release the news held back because the story or its AI output looks like a prompt injection`,
+}
+
+// quarantineListCmd represents the quarantine list command
+var quarantineListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the quarantined news",
+	Run: func(cmd *cobra.Command, args []string) {
+		newsStories, err := helpers.GetDbPool().News.GetQuarantined(quarantineLimit)
+		if err != nil {
+			slog.Error("error at getting quarantined news", "error", err)
+			return
+		}
+
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "source\tlink\treasons")
+		for _, news := range newsStories {
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", news.SourceName, news.SourceLink, strings.Join(news.QuarantineReasons, "; "))
+		}
+
+		if err := tw.Flush(); err != nil {
+			slog.Error("error at writing quarantined news", "error", err)
+		}
+	},
+}
+
+// quarantineReleaseCmd represents the quarantine release command
+var quarantineReleaseCmd = &cobra.Command{
+	Use:   "release <source_link>...",
+	Short: "Publish reviewed news",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		dbPool := helpers.GetDbPool()
+		for _, link := range args {
+			released, err := dbPool.News.ReleaseQuarantine(link)
+			if err != nil {
+				slog.Error("error at releasing news", "error", err)
+				continue
+			}
+			if !released {
+				slog.Warn("news is not quarantined", "link", link)
+				continue
+			}
+			slog.Info("news released", "link", link)
+		}
+	},
+}
+
+func init() {
+	quarantineListCmd.Flags().IntVarP(&quarantineLimit, "limit", "l", 50, "number of news to list")
+
+	quarantineCmd.AddCommand(quarantineListCmd)
+	quarantineCmd.AddCommand(quarantineReleaseCmd)
+	rootCmd.AddCommand(quarantineCmd)
+}
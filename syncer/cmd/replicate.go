@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+
+	"ncrawler/internal/crawler"
+	"ncrawler/pkg/helpers"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	replicateFrom     string
+	replicateTo       string
+	replicateConflict string
+	replicateReset    bool
+)
+
+// replicateCmd represents the replicate command
+var replicateCmd = &cobra.Command{
+	Use:   "replicate",
+	Short: "Replicate the news between sqlite3 and Postgres",
+	Long: `Copy the news changed in one datastore since the last run into the other, like the news
+crawled offline into sqlite3 to Postgres. An interrupted replication resumes from its checkpoint`,
+	Run: func(cmd *cobra.Command, args []string) {
+		report, err := crawler.RunReplicate(datastoreName(replicateFrom), datastoreName(replicateTo), replicateConflict, replicateReset)
+		fmt.Printf("read %d, invalid %d, inserted %d, updated %d, skipped %d, conflicts %d, kept %d, flagged %d, entities %d\n",
+			report.Read, report.Invalid, report.Inserted, report.Updated, report.Skipped, report.Conflicts, report.Kept, report.Flagged, report.Entities)
+		if err != nil {
+			slog.Error("error at replicating news data", "error", err)
+		}
+	},
+}
+
+// datastoreName accepts sqlite for helpers.DATASTORE_SQLITE3
+func datastoreName(name string) string {
+	if name == "sqlite" {
+		return helpers.DATASTORE_SQLITE3
+	}
+
+	return name
+}
+
+func init() {
+	replicateCmd.Flags().StringVar(&replicateFrom, "from", helpers.DATASTORE_SQLITE3, "datastore to read the changes from, sqlite3 or pg")
+	replicateCmd.Flags().StringVar(&replicateTo, "to", helpers.DATASTORE_PG, "datastore to write the changes to, pg or sqlite3")
+	replicateCmd.Flags().StringVar(&replicateConflict, "conflict", crawler.CONFLICT_NEWEST, "story kept when changed in both datastores, newest, source or target")
+	replicateCmd.Flags().BoolVar(&replicateReset, "reset", false, "forget the checkpoint and replicate every story")
+
+	rootCmd.AddCommand(replicateCmd)
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"ncrawler/internal/crawler"
+	"ncrawler/pkg/helpers"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	retractionsSince time.Duration
+	retractionsLimit int
+)
+
+// retractionsCmd represents the retractions command
+var retractionsCmd = &cobra.Command{
+	Use:   "retractions",
+	Short: "List the retracted articles",
+	Long:  `List the articles deleted or unpublished by their publisher`,
+	Run: func(cmd *cobra.Command, args []string) {
+		newsStories, err := helpers.GetDbPool().News.GetRetracted(time.Now().Add(-retractionsSince), retractionsLimit)
+		if err != nil {
+			slog.Error("error at getting retracted news", "error", err)
+			return
+		}
+
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "retracted at\treason\tsource\theadline\tlink")
+		for _, news := range newsStories {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n",
+				news.RetractedAt.Format(time.DateTime), news.RetractionReason, news.SourceName, news.Headline, news.SourceLink)
+		}
+
+		if err := tw.Flush(); err != nil {
+			slog.Error("error at writing retracted news", "error", err)
+		}
+	},
+}
+
+// retractionsCheckCmd represents the retractions check command
+var retractionsCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Check the stored articles for retraction",
+	Long: `Fetch the links of the stored articles and flag those answering 404 or 410, redirecting to the
+root of their site or showing the removal notice of their site without the article, then report what
+changed. Run it from cron, sync does not check`,
+	Run: func(cmd *cobra.Command, args []string) {
+		report, err := crawler.RunRetractionCheck(retractionsLimit)
+		if err != nil {
+			slog.Error("error at checking news for retraction", "error", err)
+		}
+
+		fmt.Printf("checked %d articles, %d inconclusive, %d changed\n", report.Checked, report.Inconclusive, len(report.Changes))
+		if len(report.Changes) == 0 {
+			return
+		}
+
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "change\treason\tsource\theadline\tlink")
+		for _, c := range report.Changes {
+			change := "retracted"
+			if !c.Retracted {
+				change = "restored"
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", change, c.Reason, c.News.SourceName, c.News.Headline, c.News.SourceLink)
+		}
+
+		if err := tw.Flush(); err != nil {
+			slog.Error("error at writing retraction report", "error", err)
+		}
+	},
+}
+
+func init() {
+	retractionsCmd.PersistentFlags().IntVarP(&retractionsLimit, "limit", "l", 100, "number of articles to list or check")
+	retractionsCmd.Flags().DurationVarP(&retractionsSince, "since", "s", 7*24*time.Hour, "list the articles retracted within this duration")
+
+	retractionsCmd.AddCommand(retractionsCheckCmd)
+	rootCmd.AddCommand(retractionsCmd)
+}
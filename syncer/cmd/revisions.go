@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"ncrawler/internal/crawler"
+	"ncrawler/pkg/helpers"
+
+	"github.com/spf13/cobra"
+)
+
+// recrawlCmd represents the recrawl command
+var recrawlCmd = &cobra.Command{
+	Use:   "recrawl",
+	Short: "Check the recent articles for changes",
+	Long:  `Fetch the recent articles again and record a revision of those whose headline or story changed`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := crawler.RunRecrawl(); err != nil {
+			slog.Error("error at re-crawling news", "error", err)
+		}
+	},
+}
+
+// revisionsCmd represents the revisions command
+var revisionsCmd = &cobra.Command{
+	Use:   "revisions <link>",
+	Short: "Show the revisions of an article",
+	Long:  `Show the recorded versions of an article and the changes between them`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		revisions, err := helpers.GetDbPool().Revisions.GetRevisions(args[0])
+		if err != nil {
+			slog.Error("error at getting revisions", "error", err)
+			return
+		}
+		if len(revisions) == 0 {
+			fmt.Println("no revisions recorded")
+			return
+		}
+
+		for _, r := range revisions {
+			modified := "-"
+			if !r.ModifiedAt.IsZero() {
+				modified = r.ModifiedAt.Format(time.DateTime)
+			}
+			fmt.Printf("revision %d  recorded %s  modified %s\n", r.Revision, r.CreatedAt.Format(time.DateTime), modified)
+			fmt.Printf("%s\n", r.Headline)
+			if r.Diff != "" {
+				fmt.Printf("\n%s\n", r.Diff)
+			}
+			fmt.Println()
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(recrawlCmd)
+	rootCmd.AddCommand(revisionsCmd)
+}
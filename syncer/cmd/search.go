@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"ncrawler/internal/dto"
+	"ncrawler/pkg/helpers"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	searchDatastore string
+	searchQuery     dto.NewsQuery
+	searchFrom      string
+	searchTo        string
+)
+
+// searchCmd represents the search command
+var searchCmd = &cobra.Command{
+	Use:   "search [text]",
+	Short: "Search the stored news",
+	Long: `Search the stored news by text, source, category, time, language,
+verification status and entity. Pass the printed cursor to get the next page`,
+	Run: func(cmd *cobra.Command, args []string) {
+		q := searchQuery
+		q.Text = strings.Join(args, " ")
+
+		var err error
+		if q.From, err = parseSearchTime(searchFrom); err != nil {
+			slog.Error("error at parsing from", "error", err)
+			return
+		}
+		if q.To, err = parseSearchTime(searchTo); err != nil {
+			slog.Error("error at parsing to", "error", err)
+			return
+		}
+
+		page, err := helpers.QueryNews(searchDatastore, q)
+		if err != nil {
+			slog.Error("error at searching news", "error", err)
+			return
+		}
+
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "time\tsource\tcategory\theadline\tlink")
+		for _, news := range page.News {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n",
+				q.SortTime(news).Format(time.DateTime), news.SourceName, news.Category, news.Headline, news.SourceLink)
+		}
+
+		if err := tw.Flush(); err != nil {
+			slog.Error("error at writing news", "error", err)
+		}
+		if page.NextCursor != "" {
+			fmt.Printf("next page: --cursor %s\n", page.NextCursor)
+		}
+	},
+}
+
+// parseSearchTime reads a date or an RFC 3339 time, an empty value is the
+// zero time
+func parseSearchTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, nil
+	}
+
+	return time.Parse(time.RFC3339, value)
+}
+
+func init() {
+	searchCmd.Flags().StringVarP(&searchDatastore, "datastore", "d", helpers.DATASTORE_PG, "datastore to search, pg or sqlite3")
+	searchCmd.Flags().StringSliceVarP(&searchQuery.Sources, "source", "s", nil, "source names")
+	searchCmd.Flags().StringSliceVarP(&searchQuery.Categories, "category", "c", nil, "categories")
+	searchCmd.Flags().StringVar(&searchFrom, "from", "", "earliest time, a date or an RFC 3339 time")
+	searchCmd.Flags().StringVar(&searchTo, "to", "", "time before which to stop, a date or an RFC 3339 time")
+	searchCmd.Flags().StringVar(&searchQuery.Language, "language", "", "ISO 639-1 language code")
+	searchCmd.Flags().StringVar(&searchQuery.VerificationStatus, "status", "", "verification status, like VERIFIED")
+	searchCmd.Flags().StringVarP(&searchQuery.Entity, "entity", "e", "", "name or alias of a mentioned entity")
+	searchCmd.Flags().StringVar(&searchQuery.Sort, "sort", dto.SORT_PUBLISHED, "sort by published or crawled time")
+	searchCmd.Flags().BoolVar(&searchQuery.Ascending, "asc", false, "oldest first")
+	searchCmd.Flags().IntVarP(&searchQuery.Limit, "limit", "l", dto.DEFAULT_QUERY_LIMIT, "page size")
+	searchCmd.Flags().StringVar(&searchQuery.Cursor, "cursor", "", "cursor of the next page")
+
+	rootCmd.AddCommand(searchCmd)
+}
@@ -4,17 +4,20 @@ import (
 	"log/slog"
 
 	"ncrawler/internal/crawler"
+	"ncrawler/pkg/helpers"
 
 	"github.com/spf13/cobra"
 )
 
+var syncDatastore string
+
 // syncCmd represents the sync command
 var syncCmd = &cobra.Command{
 	Use:   "sync",
 	Short: "Sync the news data",
 	Long:  `Get the latest news and sync it with the database`,
 	Run: func(cmd *cobra.Command, args []string) {
-		crawler := crawler.GetCrawler()
+		crawler := crawler.GetCrawlerFor(syncDatastore)
 
 		slog.Info("Starting news crawler")
 		if err := crawler.Sync(); err != nil {
@@ -24,5 +27,7 @@ var syncCmd = &cobra.Command{
 }
 
 func init() {
+	syncCmd.Flags().StringVarP(&syncDatastore, "datastore", "d", helpers.DATASTORE_PG, "datastore to sync, pg or sqlite3 to crawl offline")
+
 	rootCmd.AddCommand(syncCmd)
 }
@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"log/slog"
+
+	"ncrawler/internal/crawler"
+	"ncrawler/internal/lang"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	translateTo    string
+	translateLimit int
+)
+
+// translateCmd represents the translate command
+var translateCmd = &cobra.Command{
+	Use:   "translate",
+	Short: "Translate the stored news",
+	Long:  `Translate the headline, summary and bullet points of stored news written in another language`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := crawler.RunTranslate(translateTo, translateLimit); err != nil {
+			slog.Error("error at translating news", "error", err)
+		}
+	},
+}
+
+func init() {
+	translateCmd.Flags().StringVarP(&translateTo, "to", "t", lang.ENGLISH, "language code to translate into (en, bn)")
+	translateCmd.Flags().IntVarP(&translateLimit, "limit", "l", 100, "number of news to translate")
+
+	rootCmd.AddCommand(translateCmd)
+}
@@ -0,0 +1,17 @@
+// Package db embeds the versioned schema migrations of the datastores
+package db
+
+import "embed"
+
+const (
+	// PG_MIGRATIONS is the directory of the Postgres migrations
+	PG_MIGRATIONS = "migrations/pg"
+	// SQLITE3_MIGRATIONS is the directory of the sqlite3 migrations
+	SQLITE3_MIGRATIONS = "migrations/sqlite3"
+)
+
+// Migrations holds the migrations of every dialect, named
+// <version>_<name>.up.sql and <version>_<name>.down.sql
+//
+//go:embed migrations
+var Migrations embed.FS
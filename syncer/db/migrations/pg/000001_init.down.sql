@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS public.news;
+DROP FUNCTION IF EXISTS public.get_bangladesh_time();
+DROP FUNCTION IF EXISTS public.handle_updated_at();
@@ -1,8 +1,9 @@
--- Set the timezone for the database session
-SET timezone = 'Asia/Dhaka';
+-- Baseline schema of the news table, written to also adopt a database
+-- created by hand from the former supabase.sql
 
--- Create the news table with RLS enabled and Bangladesh timezone
-CREATE TABLE public.news (
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE TABLE IF NOT EXISTS public.news (
     id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
     source_name VARCHAR(100) NOT NULL,
     category VARCHAR(50) NOT NULL,
@@ -18,17 +19,12 @@ CREATE TABLE public.news (
     updated_at TIMESTAMP WITH TIME ZONE DEFAULT (now() AT TIME ZONE 'Asia/Dhaka') NOT NULL
 );
 
--- Create indexes for common query patterns
-CREATE INDEX idx_news_source_category_created 
+CREATE INDEX IF NOT EXISTS idx_news_source_category_created
     ON public.news(source_name, category, created_at DESC);
 
-CREATE INDEX idx_news_headline 
+CREATE INDEX IF NOT EXISTS idx_news_headline
     ON public.news USING GIN (headline gin_trgm_ops);
 
--- Enable the pg_trgm extension for better text search
-CREATE EXTENSION IF NOT EXISTS pg_trgm;
-
--- Create updated_at trigger with Bangladesh timezone
 CREATE OR REPLACE FUNCTION public.handle_updated_at()
 RETURNS TRIGGER AS $$
 BEGIN
@@ -45,36 +41,37 @@ BEGIN
 END;
 $$ LANGUAGE plpgsql;
 
+DROP TRIGGER IF EXISTS on_news_updated ON public.news;
 CREATE TRIGGER on_news_updated
     BEFORE UPDATE ON public.news
     FOR EACH ROW
     EXECUTE FUNCTION handle_updated_at();
 
--- Enable Row Level Security (RLS)
 ALTER TABLE public.news ENABLE ROW LEVEL SECURITY;
 
--- Create policies for different operations
+DROP POLICY IF EXISTS "Allow public read access" ON public.news;
 CREATE POLICY "Allow public read access"
     ON public.news
     FOR SELECT
     TO public
     USING (true);
 
+DROP POLICY IF EXISTS "Allow authenticated insert" ON public.news;
 CREATE POLICY "Allow authenticated insert"
     ON public.news
     FOR INSERT
     TO authenticated
     WITH CHECK (true);
 
+DROP POLICY IF EXISTS "Allow authenticated update" ON public.news;
 CREATE POLICY "Allow authenticated update"
     ON public.news
     FOR UPDATE
     TO authenticated
     USING (auth.uid() IN (
-        SELECT auth.uid() 
-        FROM auth.users 
+        SELECT auth.uid()
+        FROM auth.users
         WHERE auth.users.id = auth.uid()
     ));
 
--- Comments for better documentation
-COMMENT ON TABLE public.news IS 'Table storing news articles with metadata (Bangladesh timezone)';
\ No newline at end of file
+COMMENT ON TABLE public.news IS 'Table storing news articles with metadata';
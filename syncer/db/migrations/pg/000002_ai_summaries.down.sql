@@ -0,0 +1,21 @@
+DROP TABLE IF EXISTS public.ai_batch_items;
+DROP TABLE IF EXISTS public.ai_batches;
+DROP TABLE IF EXISTS public.sync_runs;
+DROP TABLE IF EXISTS public.ai_usage;
+DROP TABLE IF EXISTS public.ai_summary_cache;
+DROP TABLE IF EXISTS public.news_translations;
+
+DROP POLICY IF EXISTS "Allow public read access" ON public.news;
+CREATE POLICY "Allow public read access"
+    ON public.news
+    FOR SELECT
+    TO public
+    USING (true);
+
+ALTER TABLE public.news
+    DROP COLUMN IF EXISTS quarantine_reasons,
+    DROP COLUMN IF EXISTS quarantined,
+    DROP COLUMN IF EXISTS unsupported_claims,
+    DROP COLUMN IF EXISTS consistency_score,
+    DROP COLUMN IF EXISTS summary_method,
+    DROP COLUMN IF EXISTS language;
@@ -0,0 +1,132 @@
+-- Summary quality, prompt-injection quarantine and the AI bookkeeping tables
+
+ALTER TABLE public.news
+    ADD COLUMN IF NOT EXISTS language VARCHAR(8),
+    ADD COLUMN IF NOT EXISTS summary_method VARCHAR(20),
+    ADD COLUMN IF NOT EXISTS consistency_score REAL,
+    ADD COLUMN IF NOT EXISTS unsupported_claims TEXT[],
+    ADD COLUMN IF NOT EXISTS quarantined BOOLEAN DEFAULT false NOT NULL,
+    ADD COLUMN IF NOT EXISTS quarantine_reasons TEXT[];
+
+CREATE INDEX IF NOT EXISTS idx_news_language
+    ON public.news(language);
+
+CREATE INDEX IF NOT EXISTS idx_news_consistency_score
+    ON public.news(consistency_score);
+
+CREATE INDEX IF NOT EXISTS idx_news_quarantined
+    ON public.news(created_at DESC)
+    WHERE quarantined;
+
+DROP POLICY IF EXISTS "Allow public read access" ON public.news;
+CREATE POLICY "Allow public read access"
+    ON public.news
+    FOR SELECT
+    TO public
+    USING (NOT quarantined);
+
+-- Headline, summary and bullet points of the news in other languages
+CREATE TABLE IF NOT EXISTS public.news_translations (
+    source_link TEXT NOT NULL REFERENCES public.news(source_link) ON DELETE CASCADE,
+    language VARCHAR(8) NOT NULL,
+    headline TEXT NOT NULL,
+    summary TEXT,
+    bullet_points TEXT[],
+    model VARCHAR(100),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL,
+    PRIMARY KEY (source_link, language)
+);
+
+ALTER TABLE public.news_translations ENABLE ROW LEVEL SECURITY;
+
+-- Translations are visible when their news is
+DROP POLICY IF EXISTS "Allow public read access" ON public.news_translations;
+CREATE POLICY "Allow public read access"
+    ON public.news_translations
+    FOR SELECT
+    TO public
+    USING (EXISTS (
+        SELECT 1
+        FROM public.news
+        WHERE news.source_link = news_translations.source_link
+    ));
+
+-- Cache of AI summaries keyed by a hash of the story, prompt and model
+CREATE TABLE IF NOT EXISTS public.ai_summary_cache (
+    cache_key TEXT PRIMARY KEY,
+    model VARCHAR(100) NOT NULL,
+    prompt_version VARCHAR(100) NOT NULL,
+    response JSONB NOT NULL,
+    hits INTEGER DEFAULT 0 NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL,
+    last_hit_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Token usage and cost of every AI request
+CREATE TABLE IF NOT EXISTS public.ai_usage (
+    id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    run_id VARCHAR(64),
+    source_name VARCHAR(100),
+    source_link TEXT,
+    model VARCHAR(100) NOT NULL,
+    prompt_tokens INTEGER NOT NULL,
+    completion_tokens INTEGER NOT NULL,
+    cost_usd DOUBLE PRECISION NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_ai_usage_created
+    ON public.ai_usage(created_at);
+
+-- Counters of every sync run
+CREATE TABLE IF NOT EXISTS public.sync_runs (
+    id VARCHAR(64) PRIMARY KEY,
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    finished_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    fetched INTEGER NOT NULL,
+    summarized INTEGER NOT NULL,
+    extractive INTEGER NOT NULL,
+    failed INTEGER NOT NULL,
+    inconsistent INTEGER NOT NULL,
+    inserted INTEGER NOT NULL,
+    quarantined INTEGER NOT NULL DEFAULT 0,
+    cache_hits BIGINT NOT NULL,
+    cache_misses BIGINT NOT NULL,
+    prompt_tokens BIGINT NOT NULL,
+    completion_tokens BIGINT NOT NULL,
+    cost_usd DOUBLE PRECISION NOT NULL
+);
+
+ALTER TABLE public.sync_runs
+    ADD COLUMN IF NOT EXISTS quarantined INTEGER NOT NULL DEFAULT 0;
+
+-- Summarization batches submitted to the OpenAI Batch API
+CREATE TABLE IF NOT EXISTS public.ai_batches (
+    id VARCHAR(64) PRIMARY KEY,
+    provider_batch_id VARCHAR(100),
+    input_file_id VARCHAR(100),
+    output_file_id VARCHAR(100),
+    error_file_id VARCHAR(100),
+    file_path TEXT NOT NULL,
+    model VARCHAR(100) NOT NULL,
+    prompt_version VARCHAR(100) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    request_count INTEGER NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS public.ai_batch_items (
+    batch_id VARCHAR(64) NOT NULL REFERENCES public.ai_batches(id) ON DELETE CASCADE,
+    news_id BIGINT NOT NULL REFERENCES public.news(id) ON DELETE CASCADE,
+    PRIMARY KEY (batch_id, news_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_ai_batch_items_news
+    ON public.ai_batch_items(news_id);
+
+COMMENT ON COLUMN public.news.summary_method IS 'llm when the summary was written by the AI, extractive when made of sentences of the story';
+COMMENT ON COLUMN public.news.consistency_score IS 'Share of the numbers, dates and quotes of the summary found in the story';
+COMMENT ON COLUMN public.news.unsupported_claims IS 'Facts of the summary not found in the story, possibly hallucinated';
+COMMENT ON COLUMN public.news.quarantined IS 'Hidden from public reads until reviewed, the story or its AI output looks like a prompt injection';
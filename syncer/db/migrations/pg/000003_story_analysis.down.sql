@@ -0,0 +1,13 @@
+DROP TABLE IF EXISTS public.story_cluster_members;
+DROP TABLE IF EXISTS public.story_clusters;
+DROP TABLE IF EXISTS public.news_entities;
+DROP TABLE IF EXISTS public.entities;
+
+ALTER TABLE public.news
+    DROP COLUMN IF EXISTS duplicate_similarity,
+    DROP COLUMN IF EXISTS duplicate_of,
+    DROP COLUMN IF EXISTS simhash,
+    DROP COLUMN IF EXISTS disputed_facts,
+    DROP COLUMN IF EXISTS corroborating_sources,
+    DROP COLUMN IF EXISTS model_verification_status,
+    DROP COLUMN IF EXISTS verification_status;
@@ -0,0 +1,116 @@
+-- Entities, story clusters, corroboration and near duplicates
+
+ALTER TABLE public.news
+    ADD COLUMN IF NOT EXISTS verification_status VARCHAR(20),
+    ADD COLUMN IF NOT EXISTS model_verification_status VARCHAR(20),
+    ADD COLUMN IF NOT EXISTS corroborating_sources INTEGER,
+    ADD COLUMN IF NOT EXISTS disputed_facts TEXT[],
+    ADD COLUMN IF NOT EXISTS simhash BIGINT,
+    ADD COLUMN IF NOT EXISTS duplicate_of TEXT,
+    ADD COLUMN IF NOT EXISTS duplicate_similarity REAL;
+
+CREATE INDEX IF NOT EXISTS idx_news_verification_status
+    ON public.news(verification_status);
+
+CREATE INDEX IF NOT EXISTS idx_news_duplicate_of
+    ON public.news(duplicate_of)
+    WHERE duplicate_of <> '';
+
+-- People, organizations, locations and events under their canonical name
+CREATE TABLE IF NOT EXISTS public.entities (
+    id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    name TEXT NOT NULL,
+    type VARCHAR(20) NOT NULL,
+    aliases TEXT[],
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL,
+    UNIQUE (name, type)
+);
+
+-- Entities mentioned in each news story
+CREATE TABLE IF NOT EXISTS public.news_entities (
+    source_link TEXT NOT NULL REFERENCES public.news(source_link) ON DELETE CASCADE,
+    entity_id BIGINT NOT NULL REFERENCES public.entities(id) ON DELETE CASCADE,
+    mentions INTEGER NOT NULL DEFAULT 1,
+    PRIMARY KEY (source_link, entity_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_news_entities_entity
+    ON public.news_entities(entity_id);
+
+CREATE INDEX IF NOT EXISTS idx_entities_name
+    ON public.entities(lower(name));
+
+-- Articles of different sources about the same story
+CREATE TABLE IF NOT EXISTS public.story_clusters (
+    id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    headline TEXT NOT NULL,
+    representative_link TEXT NOT NULL,
+    first_seen TIMESTAMP WITH TIME ZONE NOT NULL,
+    last_seen TIMESTAMP WITH TIME ZONE NOT NULL,
+    size INTEGER NOT NULL,
+    centroid JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_story_clusters_last_seen
+    ON public.story_clusters(last_seen DESC);
+
+CREATE TABLE IF NOT EXISTS public.story_cluster_members (
+    source_link TEXT PRIMARY KEY REFERENCES public.news(source_link) ON DELETE CASCADE,
+    cluster_id BIGINT NOT NULL REFERENCES public.story_clusters(id) ON DELETE CASCADE,
+    similarity REAL NOT NULL,
+    added_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_story_cluster_members_cluster
+    ON public.story_cluster_members(cluster_id);
+
+ALTER TABLE public.entities ENABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS "Allow public read access" ON public.entities;
+CREATE POLICY "Allow public read access"
+    ON public.entities
+    FOR SELECT
+    TO public
+    USING (true);
+
+ALTER TABLE public.news_entities ENABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS "Allow public read access" ON public.news_entities;
+CREATE POLICY "Allow public read access"
+    ON public.news_entities
+    FOR SELECT
+    TO public
+    USING (EXISTS (
+        SELECT 1
+        FROM public.news
+        WHERE news.source_link = news_entities.source_link
+    ));
+
+ALTER TABLE public.story_clusters ENABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS "Allow public read access" ON public.story_clusters;
+CREATE POLICY "Allow public read access"
+    ON public.story_clusters
+    FOR SELECT
+    TO public
+    USING (true);
+
+ALTER TABLE public.story_cluster_members ENABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS "Allow public read access" ON public.story_cluster_members;
+CREATE POLICY "Allow public read access"
+    ON public.story_cluster_members
+    FOR SELECT
+    TO public
+    USING (EXISTS (
+        SELECT 1
+        FROM public.news
+        WHERE news.source_link = story_cluster_members.source_link
+    ));
+
+COMMENT ON COLUMN public.news.verification_status IS 'VERIFIED when reported by independent sources of the same story cluster, DISPUTED when they report different numbers';
+COMMENT ON COLUMN public.news.model_verification_status IS 'Verification status guessed by the AI from the story alone';
+COMMENT ON COLUMN public.news.simhash IS 'SimHash of the story, stories a few bits apart are near duplicates';
+COMMENT ON COLUMN public.news.duplicate_of IS 'Source link of the earlier story this one nearly duplicates, like republished wire copy';
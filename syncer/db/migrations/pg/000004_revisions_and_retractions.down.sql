@@ -0,0 +1,19 @@
+DROP POLICY IF EXISTS "Allow public read access" ON public.news;
+CREATE POLICY "Allow public read access"
+    ON public.news
+    FOR SELECT
+    TO public
+    USING (NOT quarantined);
+
+DROP TABLE IF EXISTS public.article_revisions;
+
+ALTER TABLE public.news
+    DROP COLUMN IF EXISTS retraction_checked_at,
+    DROP COLUMN IF EXISTS retraction_reason,
+    DROP COLUMN IF EXISTS retracted_at,
+    DROP COLUMN IF EXISTS retracted,
+    DROP COLUMN IF EXISTS last_checked_at,
+    DROP COLUMN IF EXISTS revised_at,
+    DROP COLUMN IF EXISTS modified_at,
+    DROP COLUMN IF EXISTS revision,
+    DROP COLUMN IF EXISTS content_hash;
@@ -0,0 +1,52 @@
+-- Article revisions found by re-crawling and retractions by the publisher
+
+ALTER TABLE public.news
+    ADD COLUMN IF NOT EXISTS content_hash VARCHAR(64),
+    ADD COLUMN IF NOT EXISTS revision INTEGER DEFAULT 1 NOT NULL,
+    ADD COLUMN IF NOT EXISTS modified_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN IF NOT EXISTS revised_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN IF NOT EXISTS last_checked_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN IF NOT EXISTS retracted BOOLEAN DEFAULT false NOT NULL,
+    ADD COLUMN IF NOT EXISTS retracted_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN IF NOT EXISTS retraction_reason VARCHAR(20),
+    ADD COLUMN IF NOT EXISTS retraction_checked_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_news_retracted
+    ON public.news(retracted_at DESC)
+    WHERE retracted;
+
+-- Versions of the news stories changed after they were first crawled
+CREATE TABLE IF NOT EXISTS public.article_revisions (
+    source_link TEXT NOT NULL REFERENCES public.news(source_link) ON DELETE CASCADE,
+    revision INTEGER NOT NULL,
+    headline TEXT NOT NULL,
+    story TEXT NOT NULL,
+    content_hash VARCHAR(64),
+    diff TEXT,
+    modified_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL,
+    PRIMARY KEY (source_link, revision)
+);
+
+ALTER TABLE public.article_revisions ENABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS "Allow public read access" ON public.article_revisions;
+CREATE POLICY "Allow public read access"
+    ON public.article_revisions
+    FOR SELECT
+    TO public
+    USING (EXISTS (
+        SELECT 1
+        FROM public.news
+        WHERE news.source_link = article_revisions.source_link
+    ));
+
+DROP POLICY IF EXISTS "Allow public read access" ON public.news;
+CREATE POLICY "Allow public read access"
+    ON public.news
+    FOR SELECT
+    TO public
+    USING (NOT quarantined AND NOT retracted);
+
+COMMENT ON COLUMN public.news.revised_at IS 'When a re-crawl found the story changed, the versions are in article_revisions';
+COMMENT ON COLUMN public.news.retracted IS 'Deleted or unpublished by the publisher: the link answers 404 or 410, redirects to the home page or shows a removal notice';
@@ -0,0 +1,25 @@
+CREATE OR REPLACE FUNCTION public.handle_updated_at()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.updated_at = (now() AT TIME ZONE 'Asia/Dhaka');
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP INDEX IF EXISTS public.idx_news_published;
+
+ALTER TABLE public.news
+    ALTER COLUMN created_at SET DEFAULT (now() AT TIME ZONE 'Asia/Dhaka'),
+    ALTER COLUMN updated_at SET DEFAULT (now() AT TIME ZONE 'Asia/Dhaka');
+
+ALTER TABLE public.news
+    DROP COLUMN IF EXISTS published_at;
+
+ALTER TABLE public.news DISABLE TRIGGER on_news_updated;
+
+UPDATE public.news
+SET
+    created_at = created_at + interval '6 hours',
+    updated_at = updated_at + interval '6 hours';
+
+ALTER TABLE public.news ENABLE TRIGGER on_news_updated;
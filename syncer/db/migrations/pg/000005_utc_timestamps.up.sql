@@ -0,0 +1,36 @@
+-- Publish time of the stories and UTC defaults instead of the Asia/Dhaka
+-- wall clock. Rows crawled before are shifted to UTC.
+
+-- Every row stored so far holds the Asia/Dhaka wall clock stored as UTC, 6
+-- hours ahead. The trigger is disabled so updated_at is shifted instead of
+-- set to now.
+ALTER TABLE public.news DISABLE TRIGGER on_news_updated;
+
+UPDATE public.news
+SET
+    created_at = created_at - interval '6 hours',
+    updated_at = updated_at - interval '6 hours';
+
+ALTER TABLE public.news ENABLE TRIGGER on_news_updated;
+
+ALTER TABLE public.news
+    ADD COLUMN IF NOT EXISTS published_at TIMESTAMP WITH TIME ZONE;
+
+ALTER TABLE public.news
+    ALTER COLUMN created_at SET DEFAULT now(),
+    ALTER COLUMN updated_at SET DEFAULT now();
+
+CREATE INDEX IF NOT EXISTS idx_news_published
+    ON public.news((COALESCE(published_at, created_at)) DESC);
+
+CREATE OR REPLACE FUNCTION public.handle_updated_at()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.updated_at = now();
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+COMMENT ON TABLE public.news IS 'Table storing news articles with metadata, times in UTC';
+COMMENT ON COLUMN public.news.published_at IS 'When the publisher first published the story, NULL when the source does not tell';
+COMMENT ON COLUMN public.news.created_at IS 'When the story was crawled';
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS public.idx_news_language;
+DROP INDEX IF EXISTS public.idx_news_created_link;
+DROP INDEX IF EXISTS public.idx_news_published_link;
+
+CREATE INDEX IF NOT EXISTS idx_news_published
+    ON public.news((COALESCE(published_at, created_at)) DESC);
+
+DROP INDEX IF EXISTS public.idx_news_search;
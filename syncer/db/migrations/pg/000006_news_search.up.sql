@@ -0,0 +1,15 @@
+-- Full-text search and keyset pagination of the news queries
+
+CREATE INDEX IF NOT EXISTS idx_news_search
+    ON public.news USING GIN (to_tsvector('simple', headline || ' ' || story));
+
+DROP INDEX IF EXISTS public.idx_news_published;
+
+CREATE INDEX IF NOT EXISTS idx_news_published_link
+    ON public.news((COALESCE(published_at, created_at)) DESC, source_link DESC);
+
+CREATE INDEX IF NOT EXISTS idx_news_created_link
+    ON public.news(created_at DESC, source_link DESC);
+
+CREATE INDEX IF NOT EXISTS idx_news_language
+    ON public.news(language);
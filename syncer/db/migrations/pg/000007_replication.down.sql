@@ -0,0 +1 @@
+DROP INDEX IF EXISTS public.idx_news_updated_link;
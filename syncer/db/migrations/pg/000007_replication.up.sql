@@ -0,0 +1,4 @@
+-- Change tracking of the news replicated to and from sqlite3
+
+CREATE INDEX IF NOT EXISTS idx_news_updated_link
+    ON public.news(updated_at, source_link);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS public.idx_news_source_category_created_link;
+DROP TABLE IF EXISTS public.news_archive;
@@ -0,0 +1,19 @@
+-- News moved out of the news table by the retention rules, the news column
+-- holds the story as exported so it can be imported back
+
+CREATE TABLE IF NOT EXISTS public.news_archive (
+    source_link TEXT PRIMARY KEY,
+    source_name VARCHAR(100) NOT NULL,
+    category VARCHAR(50) NOT NULL,
+    crawled_at TIMESTAMP WITH TIME ZONE,
+    archived_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL,
+    news JSONB NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_news_archive_source_category
+    ON public.news_archive(source_name, category, crawled_at);
+
+CREATE INDEX IF NOT EXISTS idx_news_source_category_created_link
+    ON public.news(source_name, category, created_at, source_link);
+
+COMMENT ON TABLE public.news_archive IS 'News past their retention, moved out of the news table by the prune command';
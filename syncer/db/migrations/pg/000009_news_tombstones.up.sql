@@ -0,0 +1,9 @@
+-- Links of the news deleted by the retention rules, like the news archived to
+-- NDJSON files, so they are not crawled again
+
+CREATE TABLE IF NOT EXISTS public.news_tombstones (
+    source_link TEXT PRIMARY KEY,
+    deleted_at TIMESTAMP WITH TIME ZONE DEFAULT now() NOT NULL
+);
+
+COMMENT ON TABLE public.news_tombstones IS 'Links of the news deleted by the prune command, skipped by the crawlers';
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS public.idx_ai_usage_batch_link;
+
+ALTER TABLE public.ai_usage
+    DROP COLUMN IF EXISTS batch_id;
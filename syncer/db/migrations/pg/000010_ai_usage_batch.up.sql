@@ -0,0 +1,11 @@
+-- Usage of the Batch API requests keyed by batch, so a batch applied again
+-- after a crash is not charged twice
+
+ALTER TABLE public.ai_usage
+    ADD COLUMN IF NOT EXISTS batch_id VARCHAR(64);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_ai_usage_batch_link
+    ON public.ai_usage(batch_id, source_link)
+    WHERE batch_id IS NOT NULL;
+
+COMMENT ON COLUMN public.ai_usage.batch_id IS 'Batch of a Batch API request, its usage is recorded once per batch and article';
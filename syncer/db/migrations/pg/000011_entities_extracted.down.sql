@@ -0,0 +1,2 @@
+ALTER TABLE public.news
+    DROP COLUMN IF EXISTS entities_extracted_at;
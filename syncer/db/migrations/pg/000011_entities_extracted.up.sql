@@ -0,0 +1,16 @@
+-- Time the entities of a news story were extracted, so the stories mentioning
+-- no entity are not extracted again by every entities run
+
+ALTER TABLE public.news
+    ADD COLUMN IF NOT EXISTS entities_extracted_at TIMESTAMP WITH TIME ZONE;
+
+UPDATE public.news
+SET entities_extracted_at = now()
+WHERE entities_extracted_at IS NULL
+AND EXISTS (
+    SELECT 1
+    FROM public.news_entities ne
+    WHERE ne.source_link = news.source_link
+);
+
+COMMENT ON COLUMN public.news.entities_extracted_at IS 'Time the entities were extracted, null until the entities command or sync extracted them';
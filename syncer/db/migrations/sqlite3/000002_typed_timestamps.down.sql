@@ -0,0 +1,7 @@
+UPDATE news
+SET published_at = strftime('%Y-%m-%dT%H:%M:%S+06:00', crawled_at, '+6 hours')
+WHERE published_at IS NULL
+AND crawled_at IS NOT NULL;
+
+ALTER TABLE news DROP COLUMN crawled_at;
+ALTER TABLE news DROP COLUMN modified_at;
@@ -0,0 +1,10 @@
+ALTER TABLE news ADD COLUMN modified_at TEXT;
+ALTER TABLE news ADD COLUMN crawled_at TEXT;
+
+-- The legacy rows hold the crawl time as an Asia/Dhaka RFC3339 published_at,
+-- it is moved to crawled_at in UTC
+UPDATE news
+SET
+  crawled_at = strftime('%Y-%m-%dT%H:%M:%SZ', published_at),
+  published_at = NULL
+WHERE published_at NOT LIKE '%Z';
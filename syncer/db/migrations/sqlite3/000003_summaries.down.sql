@@ -0,0 +1,3 @@
+ALTER TABLE news DROP COLUMN language;
+ALTER TABLE news DROP COLUMN bullet_points;
+ALTER TABLE news DROP COLUMN summary;
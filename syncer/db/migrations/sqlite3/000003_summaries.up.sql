@@ -0,0 +1,3 @@
+ALTER TABLE news ADD COLUMN summary TEXT;
+ALTER TABLE news ADD COLUMN bullet_points TEXT;
+ALTER TABLE news ADD COLUMN language TEXT;
@@ -0,0 +1,9 @@
+DROP TRIGGER news_fts_update;
+DROP TRIGGER news_fts_delete;
+DROP TRIGGER news_fts_insert;
+DROP TABLE news_fts;
+DROP INDEX idx_news_crawled;
+DROP INDEX idx_news_published;
+DROP TABLE news_entities;
+DROP TABLE entities;
+ALTER TABLE news DROP COLUMN verification_status;
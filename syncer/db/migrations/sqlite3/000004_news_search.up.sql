@@ -0,0 +1,49 @@
+-- Columns, entities and full-text index of the news queries, the news_fts
+-- table needs a binary built with the sqlite_fts5 tag
+ALTER TABLE news ADD COLUMN verification_status TEXT;
+
+-- Rows stored before the times were typed hold the zero time as text
+UPDATE news SET published_at = NULL WHERE published_at LIKE '0001-01-01%';
+UPDATE news SET modified_at = NULL WHERE modified_at LIKE '0001-01-01%';
+
+CREATE TABLE entities (
+  id INTEGER PRIMARY KEY,
+  name TEXT NOT NULL,
+  type TEXT NOT NULL,
+  aliases TEXT,
+  UNIQUE (name, type)
+);
+
+CREATE TABLE news_entities (
+  source_link TEXT NOT NULL,
+  entity_id INTEGER NOT NULL REFERENCES entities(id) ON DELETE CASCADE,
+  mentions INTEGER NOT NULL DEFAULT 1,
+  PRIMARY KEY (source_link, entity_id)
+);
+
+CREATE INDEX idx_news_entities_entity ON news_entities(entity_id);
+CREATE INDEX idx_news_published ON news(COALESCE(published_at, crawled_at, ''), source_link);
+CREATE INDEX idx_news_crawled ON news(COALESCE(crawled_at, ''), source_link);
+
+CREATE VIRTUAL TABLE news_fts USING fts5(
+  headline,
+  story,
+  content='news',
+  content_rowid='rowid',
+  tokenize="unicode61 remove_diacritics 0"
+);
+
+INSERT INTO news_fts(news_fts) VALUES ('rebuild');
+
+CREATE TRIGGER news_fts_insert AFTER INSERT ON news BEGIN
+  INSERT INTO news_fts(rowid, headline, story) VALUES (new.rowid, new.headline, new.story);
+END;
+
+CREATE TRIGGER news_fts_delete AFTER DELETE ON news BEGIN
+  INSERT INTO news_fts(news_fts, rowid, headline, story) VALUES ('delete', old.rowid, old.headline, old.story);
+END;
+
+CREATE TRIGGER news_fts_update AFTER UPDATE OF headline, story ON news BEGIN
+  INSERT INTO news_fts(news_fts, rowid, headline, story) VALUES ('delete', old.rowid, old.headline, old.story);
+  INSERT INTO news_fts(rowid, headline, story) VALUES (new.rowid, new.headline, new.story);
+END;
@@ -0,0 +1,7 @@
+DROP TABLE replication_checkpoints;
+DROP TRIGGER news_updated_at_update;
+DROP TRIGGER news_updated_at_insert;
+DROP INDEX idx_news_updated;
+ALTER TABLE news DROP COLUMN updated_at;
+ALTER TABLE news DROP COLUMN revision;
+ALTER TABLE news DROP COLUMN content_hash;
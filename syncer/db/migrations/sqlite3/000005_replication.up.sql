@@ -0,0 +1,25 @@
+-- Change tracking of the news replicated to and from Postgres. updated_at is
+-- kept by the triggers with milliseconds so it sorts as text
+ALTER TABLE news ADD COLUMN content_hash TEXT;
+ALTER TABLE news ADD COLUMN revision INTEGER;
+ALTER TABLE news ADD COLUMN updated_at TEXT;
+
+UPDATE news SET updated_at = strftime('%Y-%m-%dT%H:%M:%fZ', 'now');
+
+CREATE INDEX idx_news_updated ON news(updated_at, source_link);
+
+CREATE TRIGGER news_updated_at_insert AFTER INSERT ON news BEGIN
+  UPDATE news SET updated_at = strftime('%Y-%m-%dT%H:%M:%fZ', 'now') WHERE rowid = new.rowid;
+END;
+
+CREATE TRIGGER news_updated_at_update AFTER UPDATE ON news WHEN new.updated_at IS old.updated_at BEGIN
+  UPDATE news SET updated_at = strftime('%Y-%m-%dT%H:%M:%fZ', 'now') WHERE rowid = new.rowid;
+END;
+
+-- Last change replicated in each direction
+CREATE TABLE replication_checkpoints (
+  name TEXT PRIMARY KEY,
+  updated_at TEXT NOT NULL,
+  source_link TEXT NOT NULL,
+  replicated_at TEXT NOT NULL
+);
@@ -0,0 +1,12 @@
+-- News moved out of the news table by the retention rules, the news column
+-- holds the story as exported so it can be imported back
+CREATE TABLE news_archive (
+  source_link TEXT PRIMARY KEY,
+  source_name TEXT,
+  category TEXT,
+  crawled_at TEXT,
+  archived_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
+  news TEXT NOT NULL
+);
+
+CREATE INDEX idx_news_archive_source_category ON news_archive(source_name, category, crawled_at);
@@ -0,0 +1,5 @@
+ALTER TABLE news DROP COLUMN retraction_reason;
+ALTER TABLE news DROP COLUMN retracted_at;
+ALTER TABLE news DROP COLUMN retracted;
+ALTER TABLE news DROP COLUMN quarantine_reasons;
+ALTER TABLE news DROP COLUMN quarantined;
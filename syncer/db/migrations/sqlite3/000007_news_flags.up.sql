@@ -0,0 +1,6 @@
+-- Quarantine and retraction of the news, set on Postgres and replicated
+ALTER TABLE news ADD COLUMN quarantined INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE news ADD COLUMN quarantine_reasons TEXT;
+ALTER TABLE news ADD COLUMN retracted INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE news ADD COLUMN retracted_at TEXT;
+ALTER TABLE news ADD COLUMN retraction_reason TEXT;
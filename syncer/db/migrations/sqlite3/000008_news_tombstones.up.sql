@@ -0,0 +1,5 @@
+-- Links of the news deleted by the retention rules, so they are not crawled again
+CREATE TABLE news_tombstones (
+  source_link TEXT PRIMARY KEY,
+  deleted_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now'))
+);
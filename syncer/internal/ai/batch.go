@@ -0,0 +1,227 @@
+package ai
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"ncrawler/internal/dto"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+const (
+	BATCH_STATUS_PREPARED = "prepared"
+	BATCH_STATUS_APPLIED  = "applied"
+)
+
+// BatchClient is the part of the OpenAI API the batches use, implemented by
+// the OpenAI client and by stubs
+type BatchClient interface {
+	CreateFileBytes(ctx context.Context, request openai.FileBytesRequest) (openai.File, error)
+	CreateBatch(ctx context.Context, request openai.CreateBatchRequest) (openai.BatchResponse, error)
+	RetrieveBatch(ctx context.Context, batchID string) (openai.BatchResponse, error)
+	ListBatch(ctx context.Context, after *string, limit *int) (openai.ListBatchResponse, error)
+	GetFileContent(ctx context.Context, fileID string) (openai.RawResponse, error)
+}
+
+// NewBatchClient returns the OpenAI client of the batches, honouring BaseURL
+func NewBatchClient() BatchClient {
+	return newClient()
+}
+
+// BatchResult is the outcome of one request of a batch
+type BatchResult struct {
+	CustomID         string
+	Summary          *ArticleSummary
+	PromptTokens     int
+	CompletionTokens int
+	Error            string
+}
+
+// batchOutputLine is a line of the output file of a batch
+type batchOutputLine struct {
+	CustomID string `json:"custom_id"`
+	Response *struct {
+		StatusCode int                           `json:"status_code"`
+		Body       openai.ChatCompletionResponse `json:"body"`
+	} `json:"response"`
+	Error *struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// WriteBatchFile writes a Batch API JSONL request file summarizing the news,
+// every request is identified by the id of its news. Stories that do not fit
+// in the input token budget are left out as they need the map-reduce of
+// GetSummaryWithOptions; the ids of the written news are returned.
+func WriteBatchFile(path string, newsStories []dto.News, opts Options) ([]string, error) {
+	var (
+		req openai.UploadBatchFileRequest
+		ids []string
+	)
+
+	for _, news := range newsStories {
+		story := prepareStory(news.Story)
+		if EstimateTokens(opts.Model, hardenSystemPrompt(opts.SystemPrompt)+userMessage(news, story)) > opts.inputBudget() {
+			continue
+		}
+
+		req.AddChatCompletion(news.Id, openai.ChatCompletionRequest{
+			Model: opts.Model,
+			Messages: []openai.ChatCompletionMessage{
+				{Role: openai.ChatMessageRoleSystem, Content: hardenSystemPrompt(opts.SystemPrompt)},
+				{Role: openai.ChatMessageRoleUser, Content: userMessage(news, story)},
+			},
+			ResponseFormat: getResponseFormat(),
+			Temperature:    0.0,
+		})
+		ids = append(ids, news.Id)
+	}
+
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	if err := os.WriteFile(path, req.MarshalJSONL(), 0o644); err != nil {
+		return nil, fmt.Errorf("error writing batch file: %w", err)
+	}
+
+	return ids, nil
+}
+
+// UploadBatchFile uploads the request file, the id of the uploaded file is returned
+func UploadBatchFile(client BatchClient, path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("error reading batch file: %w", err)
+	}
+
+	file, err := client.CreateFileBytes(context.Background(), openai.FileBytesRequest{
+		Name:    path,
+		Bytes:   content,
+		Purpose: openai.PurposeBatch,
+	})
+	if err != nil {
+		return "", fmt.Errorf("error uploading batch file: %w", err)
+	}
+
+	return file.ID, nil
+}
+
+// CreateBatch creates a provider batch of the uploaded file, tagged with the
+// id of our batch
+func CreateBatch(client BatchClient, inputFileID, batchID string) (openai.Batch, error) {
+	resp, err := client.CreateBatch(context.Background(), openai.CreateBatchRequest{
+		InputFileID:      inputFileID,
+		Endpoint:         openai.BatchEndpointChatCompletions,
+		CompletionWindow: "24h",
+		Metadata:         map[string]any{BATCH_METADATA_KEY: batchID},
+	})
+	if err != nil {
+		return openai.Batch{}, fmt.Errorf("error creating batch: %w", err)
+	}
+
+	return resp.Batch, nil
+}
+
+// FindBatch looks for the provider batch created for our batch among the
+// BATCH_LOOKUP_LIMIT latest ones, nil when there is none
+func FindBatch(client BatchClient, batchID string) (*openai.Batch, error) {
+	limit := BATCH_LOOKUP_LIMIT
+	resp, err := client.ListBatch(context.Background(), nil, &limit)
+	if err != nil {
+		return nil, fmt.Errorf("error listing batches: %w", err)
+	}
+
+	for _, pb := range resp.Data {
+		if id, _ := pb.Metadata[BATCH_METADATA_KEY].(string); id == batchID {
+			return &pb, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// RetrieveBatch returns the current state of the batch
+func RetrieveBatch(client BatchClient, batchID string) (openai.Batch, error) {
+	resp, err := client.RetrieveBatch(context.Background(), batchID)
+	if err != nil {
+		return openai.Batch{}, fmt.Errorf("error retrieving batch %s: %w", batchID, err)
+	}
+
+	return resp.Batch, nil
+}
+
+// GetBatchResults downloads and decodes the output file of a batch
+func GetBatchResults(client BatchClient, outputFileID string) ([]BatchResult, error) {
+	content, err := client.GetFileContent(context.Background(), outputFileID)
+	if err != nil {
+		return nil, fmt.Errorf("error downloading batch output: %w", err)
+	}
+	defer content.Close()
+
+	var results []BatchResult
+	scanner := bufio.NewScanner(content)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var ol batchOutputLine
+		if err := json.Unmarshal(line, &ol); err != nil {
+			return nil, fmt.Errorf("error decoding batch output line: %w", err)
+		}
+		results = append(results, ol.toResult())
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading batch output: %w", err)
+	}
+
+	return results, nil
+}
+
+func (ol batchOutputLine) toResult() BatchResult {
+	res := BatchResult{CustomID: ol.CustomID}
+
+	switch {
+	case ol.Error != nil:
+		res.Error = fmt.Sprintf("%s: %s", ol.Error.Code, ol.Error.Message)
+	case ol.Response == nil:
+		res.Error = "no response"
+	case ol.Response.StatusCode != 200:
+		res.Error = fmt.Sprintf("status code %d", ol.Response.StatusCode)
+	case len(ol.Response.Body.Choices) == 0:
+		res.Error = "no choices in the completion response"
+	default:
+		res.PromptTokens = ol.Response.Body.Usage.PromptTokens
+		res.CompletionTokens = ol.Response.Body.Usage.CompletionTokens
+
+		as := &ArticleSummary{}
+		if err := json.Unmarshal([]byte(ol.Response.Body.Choices[0].Message.Content), as); err != nil {
+			res.Error = fmt.Sprintf("error decoding summary: %s", err)
+		} else {
+			res.Summary = as
+		}
+	}
+
+	return res
+}
+
+// RecordBatchUsage accounts the usage of a batch request made for the news
+// at the BATCH_PRICE_FACTOR of the regular price, and caches its summary. The
+// usage is recorded once per batch and news, so a batch applied again after
+// a crash is not charged twice.
+func RecordBatchUsage(opts Options, batchID string, news dto.News, res BatchResult) {
+	recordUsage(opts, batchID, news, res.PromptTokens, res.CompletionTokens, BATCH_PRICE_FACTOR)
+	if res.Summary != nil {
+		putCachedSummary(CacheKey(news, opts), opts, res.Summary)
+	}
+}
@@ -0,0 +1,236 @@
+package ai
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"ncrawler/internal/dto"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// stubBatchAPI is a Batch API keeping its files and batches in memory. A
+// batch completes on its first poll, its output answers every request of
+// the input file with a summary.
+type stubBatchAPI struct {
+	mu      sync.Mutex
+	files   map[string][]byte
+	batches []openai.Batch
+	creates int
+}
+
+func newStubBatchAPI(t *testing.T) (*stubBatchAPI, BatchClient) {
+	api := &stubBatchAPI{files: map[string][]byte{}}
+	srv := httptest.NewServer(api)
+	t.Cleanup(srv.Close)
+
+	previous := BaseURL
+	BaseURL = srv.URL + "/v1"
+	t.Cleanup(func() { BaseURL = previous })
+
+	return api, NewBatchClient()
+}
+
+func (api *stubBatchAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+
+	path := strings.TrimPrefix(r.URL.Path, "/v1")
+	switch {
+	case r.Method == http.MethodPost && path == "/files":
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		content, _ := io.ReadAll(file)
+		id := fmt.Sprintf("file-%d", len(api.files)+1)
+		api.files[id] = content
+		writeJSON(w, openai.File{ID: id, Purpose: string(openai.PurposeBatch)})
+
+	case r.Method == http.MethodGet && strings.HasPrefix(path, "/files/") && strings.HasSuffix(path, "/content"):
+		content, ok := api.files[strings.TrimSuffix(strings.TrimPrefix(path, "/files/"), "/content")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write(content)
+
+	case r.Method == http.MethodPost && path == "/batches":
+		var req openai.CreateBatchRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		api.creates++
+		b := openai.Batch{
+			ID:          fmt.Sprintf("batch-%d", len(api.batches)+1),
+			InputFileID: req.InputFileID,
+			Status:      "validating",
+			Metadata:    req.Metadata,
+		}
+		api.batches = append(api.batches, b)
+		writeJSON(w, b)
+
+	case r.Method == http.MethodGet && path == "/batches":
+		writeJSON(w, map[string]any{"object": "list", "data": api.batches})
+
+	case r.Method == http.MethodGet && strings.HasPrefix(path, "/batches/"):
+		for i, b := range api.batches {
+			if b.ID != strings.TrimPrefix(path, "/batches/") {
+				continue
+			}
+			if b.OutputFileID == nil {
+				output := fmt.Sprintf("file-%d", len(api.files)+1)
+				api.files[output] = api.answer(api.files[b.InputFileID])
+				b.Status, b.OutputFileID = "completed", &output
+				api.batches[i] = b
+			}
+			writeJSON(w, b)
+			return
+		}
+		http.NotFound(w, r)
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// answer writes the output file of the input file
+func (api *stubBatchAPI) answer(input []byte) []byte {
+	var out strings.Builder
+	for _, line := range strings.Split(strings.TrimSpace(string(input)), "\n") {
+		var req struct {
+			CustomID string `json:"custom_id"`
+		}
+		if err := json.Unmarshal([]byte(line), &req); err != nil {
+			continue
+		}
+		summary, _ := json.Marshal(ArticleSummary{Summary: "summary of " + req.CustomID, KeyPoints: []string{"point"}})
+		body := openai.ChatCompletionResponse{
+			Choices: []openai.ChatCompletionChoice{{Message: openai.ChatCompletionMessage{Content: string(summary)}}},
+			Usage:   openai.Usage{PromptTokens: 100, CompletionTokens: 20},
+		}
+		resp, _ := json.Marshal(map[string]any{
+			"custom_id": req.CustomID,
+			"response":  map[string]any{"status_code": 200, "body": body},
+		})
+		out.Write(resp)
+		out.WriteString("\n")
+	}
+
+	return []byte(out.String())
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func TestBatchSubmitPollApplyResume(t *testing.T) {
+	api, client := newStubBatchAPI(t)
+
+	news := []dto.News{
+		{Id: "a", SourceName: "CNN", Headline: "First", Story: "The first story."},
+		{Id: "b", SourceName: "BBC", Headline: "Second", Story: "The second story."},
+	}
+	path := filepath.Join(t.TempDir(), "batch.jsonl")
+	ids, err := WriteBatchFile(path, news, DefaultOptions())
+	if err != nil {
+		t.Fatalf("WriteBatchFile: %v", err)
+	}
+	if len(ids) != 2 {
+		t.Fatalf("WriteBatchFile wrote %d requests, want 2", len(ids))
+	}
+
+	// submit
+	found, err := FindBatch(client, "our-batch")
+	if err != nil {
+		t.Fatalf("FindBatch: %v", err)
+	}
+	if found != nil {
+		t.Fatalf("FindBatch found %s before the batch was submitted", found.ID)
+	}
+	inputFileID, err := UploadBatchFile(client, path)
+	if err != nil {
+		t.Fatalf("UploadBatchFile: %v", err)
+	}
+	created, err := CreateBatch(client, inputFileID, "our-batch")
+	if err != nil {
+		t.Fatalf("CreateBatch: %v", err)
+	}
+
+	// resume after a restart that lost the provider batch id
+	resumed, err := FindBatch(client, "our-batch")
+	if err != nil {
+		t.Fatalf("FindBatch after restart: %v", err)
+	}
+	if resumed == nil || resumed.ID != created.ID || resumed.InputFileID != inputFileID {
+		t.Fatalf("FindBatch after restart = %+v, want batch %s of file %s", resumed, created.ID, inputFileID)
+	}
+	if api.creates != 1 {
+		t.Fatalf("provider batches created = %d, want 1", api.creates)
+	}
+
+	// poll
+	polled, err := RetrieveBatch(client, resumed.ID)
+	if err != nil {
+		t.Fatalf("RetrieveBatch: %v", err)
+	}
+	if polled.Status != "completed" || polled.OutputFileID == nil {
+		t.Fatalf("RetrieveBatch = status %s output %v, want a completed batch with output", polled.Status, polled.OutputFileID)
+	}
+
+	// apply
+	results, err := GetBatchResults(client, *polled.OutputFileID)
+	if err != nil {
+		t.Fatalf("GetBatchResults: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("GetBatchResults returned %d results, want 2", len(results))
+	}
+	for _, res := range results {
+		if res.Error != "" || res.Summary == nil {
+			t.Fatalf("result %s failed: %s", res.CustomID, res.Error)
+		}
+		if want := "summary of " + res.CustomID; res.Summary.Summary != want {
+			t.Errorf("summary of %s = %q, want %q", res.CustomID, res.Summary.Summary, want)
+		}
+		if res.PromptTokens != 100 || res.CompletionTokens != 20 {
+			t.Errorf("usage of %s = %d/%d, want 100/20", res.CustomID, res.PromptTokens, res.CompletionTokens)
+		}
+	}
+}
+
+func TestBatchResultErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want string
+	}{
+		{"request error", `{"custom_id":"a","error":{"code":"invalid","message":"bad"}}`, "invalid: bad"},
+		{"status code", `{"custom_id":"a","response":{"status_code":500,"body":{}}}`, "status code 500"},
+		{"no choices", `{"custom_id":"a","response":{"status_code":200,"body":{"choices":[]}}}`, "no choices in the completion response"},
+		{"malformed summary", `{"custom_id":"a","response":{"status_code":200,"body":{"choices":[{"message":{"content":"{"}}]}}}`, "error decoding summary"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ol batchOutputLine
+			if err := json.Unmarshal([]byte(tt.line), &ol); err != nil {
+				t.Fatalf("decoding line: %v", err)
+			}
+			res := ol.toResult()
+			if res.Summary != nil || !strings.HasPrefix(res.Error, tt.want) {
+				t.Errorf("toResult() = summary %v error %q, want error %q", res.Summary, res.Error, tt.want)
+			}
+		})
+	}
+}
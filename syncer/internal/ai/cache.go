@@ -0,0 +1,85 @@
+package ai
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+
+	"ncrawler/internal/dto"
+	"ncrawler/pkg/helpers"
+)
+
+var (
+	cacheHits   atomic.Int64
+	cacheMisses atomic.Int64
+)
+
+// CacheStats returns the number of summary cache hits and misses so far
+func CacheStats() (hits, misses int64) {
+	return cacheHits.Load(), cacheMisses.Load()
+}
+
+// CacheKey hashes the normalised story text with the prompt and model, so an
+// article that is re-fetched or re-inserted with the same text is summarized
+// once. The system prompt is hashed as sent, with the guard prompt, so editing
+// either without renaming the version does not return stale summaries.
+func CacheKey(news dto.News, opts Options) string {
+	story := strings.ToLower(strings.Join(strings.Fields(news.Story), " "))
+
+	h := sha256.New()
+	for _, part := range []string{story, opts.PromptVersion, hardenSystemPrompt(opts.SystemPrompt), opts.Model} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// getCachedSummary returns the cached summary of the key, nil on a miss.
+// Cache errors are logged and treated as a miss.
+func getCachedSummary(key string) *ArticleSummary {
+	response, found, err := helpers.GetDbPool().SummaryCache.GetSummary(key)
+	if err != nil {
+		slog.Error("failed to read summary cache", "error", err)
+	}
+	if !found {
+		cacheMisses.Add(1)
+		return nil
+	}
+
+	as := &ArticleSummary{}
+	if err := json.Unmarshal(response, as); err != nil {
+		slog.Error("failed to decode cached summary", "error", err)
+		cacheMisses.Add(1)
+		return nil
+	}
+
+	cacheHits.Add(1)
+	return as
+}
+
+// CacheSummary caches a summary generated with DeferCache, summaries served
+// from the cache or cached already are left alone
+func CacheSummary(as ArticleSummary) {
+	if as.cacheKey == "" {
+		return
+	}
+
+	putCachedSummary(as.cacheKey, as.cacheOpts, &as)
+}
+
+// putCachedSummary stores the summary under the key, errors are only logged
+func putCachedSummary(key string, opts Options, as *ArticleSummary) {
+	response, err := json.Marshal(as)
+	if err != nil {
+		slog.Error("failed to encode summary for cache", "error", err)
+		return
+	}
+
+	if err := helpers.GetDbPool().SummaryCache.PutSummary(key, opts.Model, opts.PromptVersion, response); err != nil {
+		slog.Error("failed to write summary cache", "error", err)
+	}
+}
@@ -0,0 +1,110 @@
+package ai
+
+import (
+	"strings"
+
+	"ncrawler/pkg/helpers"
+)
+
+// DUPLICATE_PARAGRAPH_SIMILARITY is the word shingle similarity above which
+// a paragraph is considered a repeat of an earlier one
+const DUPLICATE_PARAGRAPH_SIMILARITY = 0.8
+
+// TrimDuplicateParagraphs removes empty paragraphs and paragraphs that
+// repeat an earlier one, such as pull quotes and repeated captions
+func TrimDuplicateParagraphs(paragraphs []string) []string {
+	var (
+		kept     []string
+		shingles []map[string]struct{}
+	)
+
+	for _, p := range paragraphs {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+
+		s := wordShingles(p)
+		duplicate := false
+		for _, prev := range shingles {
+			if jaccard(s, prev) >= DUPLICATE_PARAGRAPH_SIMILARITY {
+				duplicate = true
+				break
+			}
+		}
+		if duplicate {
+			continue
+		}
+
+		kept = append(kept, p)
+		shingles = append(shingles, s)
+	}
+
+	return kept
+}
+
+// ChunkParagraphs groups the paragraphs into chunks of at most maxTokens, as
+// estimated by EstimateTokens. A paragraph longer than maxTokens is split at
+// sentence boundaries.
+func ChunkParagraphs(model string, paragraphs []string, maxTokens int) []string {
+	var (
+		chunks  []string
+		current []string
+		tokens  int
+	)
+
+	flush := func() {
+		if len(current) > 0 {
+			chunks = append(chunks, strings.Join(current, "\n"))
+			current, tokens = nil, 0
+		}
+	}
+
+	for _, p := range paragraphs {
+		units := []string{p}
+		if EstimateTokens(model, p) > maxTokens {
+			units = helpers.SplitSentences(p)
+		}
+
+		for _, u := range units {
+			n := EstimateTokens(model, u)
+			if tokens+n > maxTokens {
+				flush()
+			}
+			current = append(current, u)
+			tokens += n
+		}
+	}
+	flush()
+
+	return chunks
+}
+
+func wordShingles(text string) map[string]struct{} {
+	words := strings.Fields(strings.ToLower(text))
+	shingles := make(map[string]struct{})
+	if len(words) < 3 {
+		shingles[strings.Join(words, " ")] = struct{}{}
+		return shingles
+	}
+	for i := 0; i+3 <= len(words); i++ {
+		shingles[strings.Join(words[i:i+3], " ")] = struct{}{}
+	}
+
+	return shingles
+}
+
+func jaccard(a, b map[string]struct{}) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+
+	var common int
+	for k := range a {
+		if _, ok := b[k]; ok {
+			common++
+		}
+	}
+
+	return float64(common) / float64(len(a)+len(b)-common)
+}
@@ -0,0 +1,21 @@
+package ai
+
+import "github.com/sashabaranov/go-openai"
+
+// BaseURL overrides the address of the OpenAI API when set, e.g. to run
+// against a local stub server
+var BaseURL string
+
+func newClient() *openai.Client {
+	config := openai.DefaultConfig(OPENAI_API_KEY)
+	if BaseURL != "" {
+		config.BaseURL = BaseURL
+	}
+
+	return openai.NewClientWithConfig(config)
+}
+
+// IsConfigured reports whether an API key is set
+func IsConfigured() bool {
+	return OPENAI_API_KEY != ""
+}
@@ -0,0 +1,166 @@
+package ai
+
+import (
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"ncrawler/internal/dto"
+)
+
+// injectionPatterns match text that tries to instruct the model rather than
+// report news
+var injectionPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)\b(?:ignore|disregard|forget|override)\b[^.\n]{0,40}\b(?:previous|prior|above|earlier|all|any|system)\b[^.\n]{0,20}\b(?:instructions?|prompts?|rules|directions|context)\b`),
+	regexp.MustCompile(`(?i)\byou are (?:now|no longer) (?:an? |the )?(?:ai|assistant|chatbot|language model|gpt|dan)\b`),
+	regexp.MustCompile(`(?i)\b(?:new|updated|real) (?:system )?instructions?\s*:`),
+	regexp.MustCompile(`(?i)(?:^|\n)\s*(?:system|assistant|user)\s*:`),
+	regexp.MustCompile(`(?i)\b(?:respond|reply|answer|output) (?:only )?with (?:the following|this) json\b`),
+	regexp.MustCompile(`<\|[a-z_]+\|>`),
+	regexp.MustCompile(`(?i)</?\s*(?:article|system|instructions?)\s*>`),
+}
+
+var urlRegex = regexp.MustCompile(`(?i)\bhttps?://[^\s"'<>)]+|\bwww\.[^\s"'<>)]+`)
+
+// genericCategories are topics that need not appear in the story to be grounded
+var genericCategories = map[string]struct{}{}
+
+func init() {
+	for _, c := range strings.Split(`world,international,politics,economy,business,finance,markets,
+		health,science,technology,sports,culture,entertainment,environment,climate,conflict,war,
+		security,diplomacy,international relations,human rights,law,crime,justice,elections,
+		society,education,energy,trade,migration,disaster,weather,religion,asia,europe,africa,
+		middle east,americas,oceania,south asia,bangladesh,opinion,analysis`, ",") {
+		genericCategories[strings.TrimSpace(c)] = struct{}{}
+	}
+}
+
+// FindInjections returns the instruction-like snippets found in the text
+func FindInjections(text string) []string {
+	var findings []string
+	for _, re := range injectionPatterns {
+		for _, m := range re.FindAllString(text, -1) {
+			findings = append(findings, strings.TrimSpace(m))
+		}
+	}
+
+	return findings
+}
+
+// sanitizeUntrusted removes instruction-like snippets and delimiter tags
+// from scraped text and caps its length to MAX_STORY_CHARS
+func sanitizeUntrusted(text string) string {
+	for _, re := range injectionPatterns {
+		text = re.ReplaceAllString(text, " [removed] ")
+	}
+
+	if utf8.RuneCountInString(text) > MAX_STORY_CHARS {
+		text = string([]rune(text)[:MAX_STORY_CHARS])
+	}
+
+	return text
+}
+
+// prepareStory hardens the scraped story and trims its repeated paragraphs
+func prepareStory(story string) string {
+	return strings.Join(TrimDuplicateParagraphs(strings.Split(sanitizeUntrusted(story), "\n")), "\n")
+}
+
+// hardenSystemPrompt tells the model to treat the delimited article as data
+func hardenSystemPrompt(prompt string) string {
+	return prompt + "\n" + GUARD_PROMPT
+}
+
+// CheckGrounding returns the parts of the AI output that are not grounded in
+// the news: URLs missing from the source, an unexpected verification status
+// and instruction-like text. Ungrounded categories are dropped by
+// GroundedCategories instead.
+func CheckGrounding(news dto.News, as ArticleSummary) []string {
+	source := groundingSource(news)
+	output := as.Summary + "\n" + strings.Join(as.KeyPoints, "\n") + "\n" + strings.Join(as.Metadata.Categories, "\n")
+
+	var issues []string
+	for _, u := range urlRegex.FindAllString(output, -1) {
+		if !strings.Contains(source, strings.ToLower(strings.TrimRight(u, ".,;"))) {
+			issues = append(issues, fmt.Sprintf("url not in source: %s", u))
+		}
+	}
+
+	switch as.Metadata.VerificationStatus {
+	case "", "VERIFIED", "DEVELOPING", "UNVERIFIED", "DISPUTED", "PENDING":
+	default:
+		issues = append(issues, fmt.Sprintf("unexpected verification status: %s", as.Metadata.VerificationStatus))
+	}
+
+	for _, f := range FindInjections(output) {
+		issues = append(issues, fmt.Sprintf("instruction in output: %s", f))
+	}
+
+	return issues
+}
+
+// groundingSource is the lower cased text of the news the AI output must be
+// grounded in
+func groundingSource(news dto.News) string {
+	return strings.ToLower(news.Headline + "\n" + news.Story + "\n" + news.MetaDescription + "\n" + news.MetaKeywords + "\n" + news.SourceLink)
+}
+
+// GroundedCategories returns the categories that are generic topics or found
+// in the text of the news, the others are dropped
+func GroundedCategories(news dto.News, categories []string) []string {
+	source := groundingSource(news)
+
+	grounded := make([]string, 0, len(categories))
+	for _, c := range categories {
+		if !isGroundedCategory(c, source) {
+			slog.Warn("dropping ungrounded category", "headline", news.Headline, "category", c)
+			continue
+		}
+		grounded = append(grounded, c)
+	}
+
+	return grounded
+}
+
+func isGroundedCategory(category, source string) bool {
+	c := strings.ToLower(strings.TrimSpace(category))
+	if c == "" || len(c) > MAX_CATEGORY_CHARS {
+		return false
+	}
+	if _, ok := genericCategories[c]; ok {
+		return true
+	}
+
+	for _, w := range strings.FieldsFunc(c, func(r rune) bool { return r == ' ' || r == '-' || r == '/' || r == '&' || r == ',' }) {
+		if len(w) < 4 {
+			continue
+		}
+		// compare on a short stem so "elections" is grounded by "election"
+		stem := w
+		if len(stem) > 5 {
+			stem = stem[:5]
+		}
+		if strings.Contains(source, stem) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Inspect returns the reasons to hold the news for review: instruction-like
+// text in the headline or story and, when given, AI output that is not
+// grounded in the news
+func Inspect(news dto.News, as *ArticleSummary) []string {
+	var reasons []string
+	for _, f := range FindInjections(news.Headline + "\n" + news.Story) {
+		reasons = append(reasons, fmt.Sprintf("instruction in story: %s", f))
+	}
+	if as != nil {
+		reasons = append(reasons, CheckGrounding(news, *as)...)
+	}
+
+	return reasons
+}
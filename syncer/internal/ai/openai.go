@@ -4,7 +4,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log/slog"
+	"strings"
+
 	"ncrawler/internal/dto"
+	"ncrawler/internal/lang"
 
 	"github.com/sashabaranov/go-openai"
 )
@@ -17,37 +21,145 @@ type ArticleSummary struct {
 	} `json:"metadata"`
 	Summary   string   `json:"summary"`
 	KeyPoints []string `json:"key_points"`
+
+	// cacheKey and cacheOpts are set on a generated summary whose caching
+	// was deferred
+	cacheKey  string
+	cacheOpts Options
 }
 
 func GetSummary(news dto.News) (*ArticleSummary, error) {
+	return GetSummaryWithOptions(news, DefaultOptions())
+}
+
+// GetSummaryWithOptions summarizes the news with the given model and prompt.
+// A summary of the same story, prompt and model is served from the cache
+// unless NoCache is set, and the spend caps may switch the model or refuse
+// the request with ErrBudgetExceeded. Repeated paragraphs are trimmed from
+// the story first, and a story that does not fit in the input token budget
+// is condensed chunk by chunk before the final summary is generated
+// (map-reduce).
+func GetSummaryWithOptions(news dto.News, opts Options) (*ArticleSummary, error) {
+	key := CacheKey(news, opts)
+	if !opts.NoCache {
+		if as := getCachedSummary(key); as != nil {
+			return as, nil
+		}
+	}
+
+	budgeted, err := applyBudget(opts)
+	if err != nil {
+		return nil, err
+	}
+	if budgeted.Model != opts.Model {
+		opts, key = budgeted, CacheKey(news, budgeted)
+		if !opts.NoCache {
+			if as := getCachedSummary(key); as != nil {
+				return as, nil
+			}
+		}
+	}
+
 	ctx := context.Background()
-	client := openai.NewClient(OPENAI_API_KEY)
+	client := newClient()
+
+	budget := opts.inputBudget()
+	story := prepareStory(news.Story)
+	for round := 1; EstimateTokens(opts.Model, hardenSystemPrompt(opts.SystemPrompt)+userMessage(news, story)) > budget; round++ {
+		if round > MAX_REDUCE_ROUNDS {
+			return nil, fmt.Errorf("story still exceeds %d tokens after %d reduce rounds", budget, MAX_REDUCE_ROUNDS)
+		}
+
+		condensed, err := condenseStory(ctx, client, opts, news, story)
+		if err != nil {
+			return nil, err
+		}
+		story = condensed
+	}
+
 	// setup the messages
 	messages := []openai.ChatCompletionMessage{
-		{Role: openai.ChatMessageRoleSystem, Content: SYSTEM_PROMPT},
-		{Role: openai.ChatMessageRoleUser, Content: fmt.Sprintf("Source: %s\nHeadline: %s\nStory: %s", news.SourceName, news.Headline, news.Story)},
+		{Role: openai.ChatMessageRoleSystem, Content: hardenSystemPrompt(opts.SystemPrompt)},
+		{Role: openai.ChatMessageRoleUser, Content: userMessage(news, story)},
+	}
+
+	content, err := complete(ctx, client, opts, news, messages, getResponseFormat())
+	if err != nil {
+		return nil, err
 	}
 
+	as := &ArticleSummary{}
+	if err := json.Unmarshal([]byte(content), as); err != nil {
+		return nil, fmt.Errorf("error decoding summary: %w", err)
+	}
+
+	if opts.DeferCache {
+		as.cacheKey, as.cacheOpts = key, opts
+		return as, nil
+	}
+	putCachedSummary(key, opts, as)
+
+	return as, nil
+}
+
+// userMessage wraps the untrusted headline and story in <article> tags
+func userMessage(news dto.News, story string) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Source: %s\n", news.SourceName)
+	if news.Language != "" && news.Language != lang.ENGLISH {
+		fmt.Fprintf(&sb, "Language: %s\n", lang.Name(news.Language))
+	}
+	fmt.Fprintf(&sb, "<article>\nHeadline: %s\nStory: %s\n</article>", sanitizeUntrusted(news.Headline), story)
+
+	return sb.String()
+}
+
+// condenseStory splits the story into chunks that fit the budget, condenses
+// every chunk into notes (map) and joins the notes into a shorter story
+func condenseStory(ctx context.Context, client *openai.Client, opts Options, news dto.News, story string) (string, error) {
+	chunkBudget := min(CHUNK_TOKENS, opts.inputBudget()-EstimateTokens(opts.Model, CHUNK_PROMPT)-EstimateTokens(opts.Model, news.Headline)-64)
+	chunks := ChunkParagraphs(opts.Model, strings.Split(story, "\n"), chunkBudget)
+	slog.Info("condensing long story", "headline", news.Headline, "chunks", len(chunks))
+
+	notes := make([]string, 0, len(chunks))
+	for i, chunk := range chunks {
+		messages := []openai.ChatCompletionMessage{
+			{Role: openai.ChatMessageRoleSystem, Content: hardenSystemPrompt(CHUNK_PROMPT)},
+			{Role: openai.ChatMessageRoleUser, Content: fmt.Sprintf("<article>\nHeadline: %s\nPart %d of %d:\n%s\n</article>", sanitizeUntrusted(news.Headline), i+1, len(chunks), chunk)},
+		}
+
+		content, err := complete(ctx, client, opts, news, messages, nil)
+		if err != nil {
+			return "", fmt.Errorf("error condensing part %d: %w", i+1, err)
+		}
+		notes = append(notes, strings.TrimSpace(content))
+	}
+
+	return strings.Join(notes, "\n"), nil
+}
+
+// complete sends the messages to the chat completion API, records the token
+// usage for the news and returns the content of the first choice
+func complete(ctx context.Context, client *openai.Client, opts Options, news dto.News, messages []openai.ChatCompletionMessage, format *openai.ChatCompletionResponseFormat) (string, error) {
 	resp, err := client.CreateChatCompletion(
 		ctx,
 		openai.ChatCompletionRequest{
-			Model:          "gpt-4o-mini-2024-07-18",
+			Model:          opts.Model,
 			Messages:       messages,
-			ResponseFormat: getResponseFormat(),
+			ResponseFormat: format,
 			Temperature:    0.0,
 		},
 	)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
+	recordUsage(opts, "", news, resp.Usage.PromptTokens, resp.Usage.CompletionTokens, 1)
 
-	as := &ArticleSummary{}
-	err = json.Unmarshal([]byte(resp.Choices[0].Message.Content), as)
-	if err != nil {
-		fmt.Println(err)
+	if len(resp.Choices) == 0 {
+		return "", fmt.Errorf("no choices in the completion response")
 	}
 
-	return as, nil
+	return resp.Choices[0].Message.Content, nil
 }
 
 func getResponseFormat() *openai.ChatCompletionResponseFormat {
@@ -0,0 +1,232 @@
+package ai
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"ncrawler/internal/dto"
+	"ncrawler/pkg/helpers"
+)
+
+// Price is the cost in USD of one million tokens of a model
+type Price struct {
+	InputPerMillion  float64 `json:"input_per_million"`
+	OutputPerMillion float64 `json:"output_per_million"`
+}
+
+// prices are matched by model name prefix, the longest prefix wins. They can
+// be overridden or extended with a PRICES_FILE holding a JSON object of
+// model prefix to price.
+var prices = map[string]Price{
+	"gpt-4o":        {InputPerMillion: 2.50, OutputPerMillion: 10.00},
+	"gpt-4o-mini":   {InputPerMillion: 0.15, OutputPerMillion: 0.60},
+	"gpt-4-turbo":   {InputPerMillion: 10.00, OutputPerMillion: 30.00},
+	"gpt-4":         {InputPerMillion: 30.00, OutputPerMillion: 60.00},
+	"gpt-3.5-turbo": {InputPerMillion: 0.50, OutputPerMillion: 1.50},
+}
+
+var loadPricesOnce sync.Once
+
+var (
+	promptTokens     atomic.Int64
+	completionTokens atomic.Int64
+	spentMicroUSD    atomic.Int64
+)
+
+// UsageStats returns the tokens used and the money spent so far
+func UsageStats() (prompt, completion int64, costUSD float64) {
+	return promptTokens.Load(), completionTokens.Load(), float64(spentMicroUSD.Load()) / 1e6
+}
+
+// loadPrices merges the prices of PRICES_FILE into the price table
+func loadPrices() {
+	content, err := os.ReadFile(PRICES_FILE)
+	if errors.Is(err, os.ErrNotExist) {
+		return
+	}
+	if err != nil {
+		slog.Error("failed to read prices file", "file", PRICES_FILE, "error", err)
+		return
+	}
+
+	overrides := map[string]Price{}
+	if err := json.Unmarshal(content, &overrides); err != nil {
+		slog.Error("failed to decode prices file", "file", PRICES_FILE, "error", err)
+		return
+	}
+	for model, price := range overrides {
+		prices[model] = price
+	}
+}
+
+// GetPrice returns the price of the model, found is false for an unknown model
+func GetPrice(model string) (price Price, found bool) {
+	loadPricesOnce.Do(loadPrices)
+
+	bestLen := 0
+	for prefix, p := range prices {
+		if strings.HasPrefix(model, prefix) && len(prefix) > bestLen {
+			price, bestLen, found = p, len(prefix), true
+		}
+	}
+
+	return price, found
+}
+
+// Cost returns the cost in USD of the tokens used with the model
+func Cost(model string, prompt, completion int) float64 {
+	price, found := GetPrice(model)
+	if !found {
+		slog.Warn("no price for model, cost is not accounted", "model", model)
+	}
+
+	return float64(prompt)*price.InputPerMillion/1e6 + float64(completion)*price.OutputPerMillion/1e6
+}
+
+// recordUsage accounts the usage of one request made for the news, the
+// cost is multiplied by the price factor. The usage of a batch request is
+// only accounted once per batch.
+func recordUsage(opts Options, batchID string, news dto.News, prompt, completion int, priceFactor float64) {
+	cost := Cost(opts.Model, prompt, completion) * priceFactor
+
+	recorded, err := helpers.GetDbPool().AIUsage.AddUsage(dto.AIUsage{
+		RunID:            opts.RunID,
+		BatchID:          batchID,
+		SourceName:       news.SourceName,
+		SourceLink:       news.SourceLink,
+		Model:            opts.Model,
+		PromptTokens:     prompt,
+		CompletionTokens: completion,
+		CostUSD:          cost,
+	})
+	if err != nil {
+		slog.Error("failed to record ai usage", "error", err)
+	}
+	if err == nil && !recorded {
+		return
+	}
+
+	promptTokens.Add(int64(prompt))
+	completionTokens.Add(int64(completion))
+	spentMicroUSD.Add(int64(cost * 1e6))
+}
+
+// ErrBudgetExceeded is returned when a budget cap is reached and there is no
+// cheaper model to fall back to
+var ErrBudgetExceeded = errors.New("ai budget exceeded")
+
+// applyBudget checks the spend of the current UTC day and month against
+// DAILY_BUDGET_USD and MONTHLY_BUDGET_USD. When a cap is reached the options
+// are switched to BUDGET_FALLBACK_MODEL, or ErrBudgetExceeded is returned
+// when there is no fallback model or it is already in use.
+func applyBudget(opts Options) (Options, error) {
+	if DAILY_BUDGET_USD <= 0 && MONTHLY_BUDGET_USD <= 0 {
+		return opts, nil
+	}
+
+	for _, c := range budgetCaps() {
+		if c.limit <= 0 {
+			continue
+		}
+
+		spend, err := c.since()
+		if err != nil {
+			return opts, fmt.Errorf("error checking %s budget: %w", c.name, err)
+		}
+		if spend < c.limit {
+			continue
+		}
+
+		if BUDGET_FALLBACK_MODEL == "" || opts.Model == BUDGET_FALLBACK_MODEL {
+			return opts, fmt.Errorf("%w: %s spend %.2f USD of %.2f USD", ErrBudgetExceeded, c.name, spend, c.limit)
+		}
+
+		slog.Warn("budget reached, switching to fallback model", "budget", c.name, "spend", spend, "model", BUDGET_FALLBACK_MODEL)
+		opts.Model = BUDGET_FALLBACK_MODEL
+	}
+
+	return opts, nil
+}
+
+// budgetCap is a spend cap with the spend it is checked against
+type budgetCap struct {
+	name  string
+	limit float64
+	since func() (float64, error)
+}
+
+// budgetCaps returns the daily and monthly caps of the current UTC day
+func budgetCaps() []budgetCap {
+	now := helpers.GetCurrentTime().UTC()
+
+	return []budgetCap{
+		{"daily", DAILY_BUDGET_USD, func() (float64, error) {
+			return helpers.GetDbPool().AIUsage.GetSpend(helpers.StartOfDay(now))
+		}},
+		{"monthly", MONTHLY_BUDGET_USD, func() (float64, error) {
+			return helpers.GetDbPool().AIUsage.GetSpend(helpers.StartOfMonth(now))
+		}},
+	}
+}
+
+// remainingBudget returns the money left under the tightest cap, +Inf when
+// no cap is set
+func remainingBudget() (float64, error) {
+	remaining := math.Inf(1)
+	for _, c := range budgetCaps() {
+		if c.limit <= 0 {
+			continue
+		}
+
+		spend, err := c.since()
+		if err != nil {
+			return 0, fmt.Errorf("error checking %s budget: %w", c.name, err)
+		}
+		remaining = min(remaining, c.limit-spend)
+	}
+
+	return remaining, nil
+}
+
+// BatchBudget applies the spend caps to a batch of the news: the options may
+// switch to BUDGET_FALLBACK_MODEL and the news are cut to the ones whose
+// estimated cost at the batch price fits in the remaining budget.
+// ErrBudgetExceeded is returned when none fits.
+func BatchBudget(opts Options, newsStories []dto.News) (Options, []dto.News, error) {
+	opts, err := applyBudget(opts)
+	if err != nil {
+		return opts, nil, err
+	}
+	remaining, err := remainingBudget()
+	if err != nil {
+		return opts, nil, err
+	}
+
+	var estimated float64
+	for i, news := range newsStories {
+		prompt := EstimateTokens(opts.Model, hardenSystemPrompt(opts.SystemPrompt)+userMessage(news, prepareStory(news.Story)))
+		estimated += Cost(opts.Model, prompt, BATCH_COMPLETION_TOKENS) * BATCH_PRICE_FACTOR
+		if estimated > remaining {
+			if i == 0 {
+				return opts, nil, fmt.Errorf("%w: %.2f USD left", ErrBudgetExceeded, remaining)
+			}
+			slog.Warn("batch cut to the remaining budget", "requests", i, "pending", len(newsStories), "remaining_usd", remaining)
+			return opts, newsStories[:i], nil
+		}
+	}
+
+	return opts, newsStories, nil
+}
+
+// CheckBudget applies the spend caps to the options like a single request,
+// see applyBudget
+func CheckBudget(opts Options) (Options, error) {
+	return applyBudget(opts)
+}
@@ -0,0 +1,85 @@
+package ai
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// prompts holds the built-in system prompts keyed by version
+var prompts = map[string]string{
+	DEFAULT_PROMPT_VERSION: SYSTEM_PROMPT,
+}
+
+// Options selects the model and system prompt used for a summary
+type Options struct {
+	Model         string
+	PromptVersion string
+	SystemPrompt  string
+	// MaxInputTokens is the token budget of the request input
+	MaxInputTokens int
+	// NoCache skips the summary cache lookup, the new summary is still cached
+	NoCache bool
+	// DeferCache leaves the new summary out of the cache until it is passed
+	// to CacheSummary, like once it passed the fact check
+	DeferCache bool
+	// RunID attributes the usage of the requests to a sync run
+	RunID string
+}
+
+// DefaultOptions returns the model and prompt used by the crawler
+func DefaultOptions() Options {
+	return Options{
+		Model:          DEFAULT_MODEL,
+		PromptVersion:  DEFAULT_PROMPT_VERSION,
+		SystemPrompt:   SYSTEM_PROMPT,
+		MaxInputTokens: MAX_INPUT_TOKENS,
+	}
+}
+
+// ResolveOptions builds Options from a model name and a prompt reference.
+// The prompt reference is either a built-in version (e.g. "v1") or a path
+// to a file holding the system prompt, in which case the file name
+// without extension is used as the version.
+func ResolveOptions(model, prompt string) (Options, error) {
+	opts := DefaultOptions()
+	if model != "" {
+		opts.Model = model
+	}
+	if prompt == "" {
+		return opts, nil
+	}
+
+	if p, ok := prompts[prompt]; ok {
+		opts.PromptVersion = prompt
+		opts.SystemPrompt = p
+		return opts, nil
+	}
+
+	content, err := os.ReadFile(prompt)
+	if err != nil {
+		return Options{}, fmt.Errorf("unknown prompt version or file %q: %w", prompt, err)
+	}
+
+	opts.PromptVersion = strings.TrimSuffix(filepath.Base(prompt), filepath.Ext(prompt))
+	opts.SystemPrompt = string(content)
+
+	return opts, nil
+}
+
+// Label returns a short human readable name of the options
+func (o Options) Label() string {
+	return fmt.Sprintf("%s/%s", o.PromptVersion, o.Model)
+}
+
+// inputBudget returns the token budget of the request input, bounded by the
+// context window of the model
+func (o Options) inputBudget() int {
+	budget := ContextWindow(o.Model) - OUTPUT_TOKENS_RESERVE
+	if o.MaxInputTokens > 0 && o.MaxInputTokens < budget {
+		budget = o.MaxInputTokens
+	}
+
+	return budget
+}
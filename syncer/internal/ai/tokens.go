@@ -0,0 +1,63 @@
+package ai
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// tokenizer describes how densely a model family tokenizes text on average
+type tokenizer struct {
+	// charsPerToken is the average number of ASCII characters per token
+	charsPerToken float64
+	// tokensPerRune is the average number of tokens per non ASCII character,
+	// scripts like Bangla take about one token per character or more
+	tokensPerRune float64
+	// contextWindow is the maximum number of tokens of a request
+	contextWindow int
+}
+
+// tokenizers are matched by model name prefix, the longest prefix wins
+var tokenizers = map[string]tokenizer{
+	"gpt-4o":        {charsPerToken: 4.2, tokensPerRune: 0.9, contextWindow: 128000},
+	"gpt-4o-mini":   {charsPerToken: 4.2, tokensPerRune: 0.9, contextWindow: 128000},
+	"gpt-4-turbo":   {charsPerToken: 3.8, tokensPerRune: 1.5, contextWindow: 128000},
+	"gpt-4":         {charsPerToken: 3.8, tokensPerRune: 1.5, contextWindow: 8192},
+	"gpt-3.5-turbo": {charsPerToken: 3.8, tokensPerRune: 1.5, contextWindow: 16385},
+}
+
+var defaultTokenizer = tokenizer{charsPerToken: 3.8, tokensPerRune: 1.5, contextWindow: 8192}
+
+func getTokenizer(model string) tokenizer {
+	best, bestLen := defaultTokenizer, 0
+	for prefix, t := range tokenizers {
+		if strings.HasPrefix(model, prefix) && len(prefix) > bestLen {
+			best, bestLen = t, len(prefix)
+		}
+	}
+
+	return best
+}
+
+// EstimateTokens estimates the number of tokens of the text for the model
+// from its character counts, it does not run the model tokenizer. The
+// estimate errs on the high side to keep requests inside the budget, the
+// usage recorded and priced comes from the API response.
+func EstimateTokens(model, text string) int {
+	t := getTokenizer(model)
+
+	var ascii, other int
+	for _, r := range text {
+		if r < utf8.RuneSelf {
+			ascii++
+		} else {
+			other++
+		}
+	}
+
+	return int(float64(ascii)/t.charsPerToken+float64(other)*t.tokensPerRune) + 1
+}
+
+// ContextWindow returns the maximum number of tokens of a request for the model
+func ContextWindow(model string) int {
+	return getTokenizer(model).contextWindow
+}
@@ -0,0 +1,68 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"ncrawler/internal/dto"
+	"ncrawler/internal/lang"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// translation is the response of a translation request
+type translation struct {
+	Headline  string   `json:"headline"`
+	Summary   string   `json:"summary"`
+	KeyPoints []string `json:"key_points"`
+}
+
+// Translate translates the headline, summary and bullet points of the news
+// into the target language, the original text of the news is left as is
+func Translate(news dto.News, target string, opts Options) (*dto.Translation, error) {
+	opts, err := applyBudget(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	client := newClient()
+
+	// the headline is scraped text, unlike the summary
+	source, err := json.Marshal(translation{
+		Headline:  sanitizeUntrusted(news.Headline),
+		Summary:   news.Summary,
+		KeyPoints: news.BulletPoints,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error encoding translation request: %w", err)
+	}
+
+	messages := []openai.ChatCompletionMessage{
+		{Role: openai.ChatMessageRoleSystem, Content: strings.ReplaceAll(TRANSLATE_PROMPT, "{language}", lang.Name(target))},
+		{Role: openai.ChatMessageRoleUser, Content: string(source)},
+	}
+
+	content, err := complete(ctx, client, opts, news, messages, &openai.ChatCompletionResponseFormat{
+		Type: openai.ChatCompletionResponseFormatTypeJSONObject,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var t translation
+	if err := json.Unmarshal([]byte(content), &t); err != nil {
+		return nil, fmt.Errorf("error decoding translation: %w", err)
+	}
+
+	return &dto.Translation{
+		SourceLink:   news.SourceLink,
+		Language:     target,
+		Headline:     t.Headline,
+		Summary:      t.Summary,
+		BulletPoints: t.KeyPoints,
+		Model:        opts.Model,
+	}, nil
+}
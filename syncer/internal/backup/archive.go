@@ -0,0 +1,193 @@
+// Package backup reads and writes the backup archives: gzip compressed
+// NDJSON starting with a header, then one line per row of a table and ending
+// with a trailer counting the rows and hashing the lines before it
+package backup
+
+import (
+	"bufio"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"maps"
+	"slices"
+	"time"
+)
+
+// ErrTruncated is returned for an archive without its trailer
+var ErrTruncated = errors.New("backup archive is truncated")
+
+// Header is the first line of an archive
+type Header struct {
+	Format    string    `json:"format"`
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	// Datastore is the datastore backed up and SchemaVersion its migration
+	Datastore     string `json:"datastore"`
+	SchemaVersion uint   `json:"schema_version"`
+}
+
+// Trailer is the last line of an archive
+type Trailer struct {
+	Rows   map[string]int `json:"rows"`
+	SHA256 string         `json:"sha256"`
+}
+
+// line is a line of an archive, the header, a row of a table or the trailer
+type line struct {
+	Header  *Header         `json:"header,omitempty"`
+	Table   string          `json:"table,omitempty"`
+	Row     json.RawMessage `json:"row,omitempty"`
+	Trailer *Trailer        `json:"trailer,omitempty"`
+}
+
+// Writer writes an archive
+type Writer struct {
+	gz   *gzip.Writer
+	buf  *bufio.Writer
+	hash hash.Hash
+	rows map[string]int
+}
+
+// NewWriter starts the archive with the header, the format and version are
+// set
+func NewWriter(w io.Writer, header Header) (*Writer, error) {
+	gz := gzip.NewWriter(w)
+	aw := &Writer{gz: gz, buf: bufio.NewWriter(gz), hash: sha256.New(), rows: map[string]int{}}
+
+	header.Format, header.Version = FORMAT, FORMAT_VERSION
+	if err := aw.writeLine(line{Header: &header}, true); err != nil {
+		return nil, err
+	}
+
+	return aw, nil
+}
+
+// Write adds a row of the table
+func (aw *Writer) Write(table string, row any) error {
+	data, err := json.Marshal(row)
+	if err != nil {
+		return fmt.Errorf("error encoding row of %s: %w", table, err)
+	}
+	aw.rows[table]++
+
+	return aw.writeLine(line{Table: table, Row: data}, true)
+}
+
+// Close ends the archive with the trailer, the underlying writer is not closed
+func (aw *Writer) Close() error {
+	trailer := Trailer{Rows: aw.rows, SHA256: hex.EncodeToString(aw.hash.Sum(nil))}
+	if err := aw.writeLine(line{Trailer: &trailer}, false); err != nil {
+		return err
+	}
+	if err := aw.buf.Flush(); err != nil {
+		return fmt.Errorf("error writing backup: %w", err)
+	}
+
+	return aw.gz.Close()
+}
+
+// Rows returns the number of rows written of every table
+func (aw *Writer) Rows() map[string]int {
+	return maps.Clone(aw.rows)
+}
+
+func (aw *Writer) writeLine(l line, hashed bool) error {
+	data, err := json.Marshal(l)
+	if err != nil {
+		return fmt.Errorf("error encoding backup line: %w", err)
+	}
+	data = append(data, '\n')
+
+	if hashed {
+		aw.hash.Write(data)
+	}
+	if _, err := aw.buf.Write(data); err != nil {
+		return fmt.Errorf("error writing backup: %w", err)
+	}
+
+	return nil
+}
+
+// Read checks the archive and passes every row to fn with its table, in the
+// order they were written. The rows are counted and hashed as they are read
+// and compared with the trailer at the end, so a damaged archive is only
+// known bad once read through: read it once with a fn decoding the rows
+// before restoring them.
+func Read(r io.Reader, fn func(table string, row json.RawMessage) error) (Header, Trailer, error) {
+	var (
+		header  Header
+		trailer Trailer
+	)
+
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return header, trailer, fmt.Errorf("error reading backup: %w", err)
+	}
+	defer gz.Close()
+
+	br := bufio.NewReader(gz)
+	h := sha256.New()
+	rows := map[string]int{}
+	for n := 1; ; n++ {
+		data, err := br.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			if len(data) == 0 {
+				return header, trailer, ErrTruncated
+			}
+			return header, trailer, fmt.Errorf("%w: line %d is cut", ErrTruncated, n)
+		}
+		if err != nil {
+			return header, trailer, fmt.Errorf("error reading backup line %d: %w", n, err)
+		}
+
+		var l line
+		if err := json.Unmarshal(data, &l); err != nil {
+			return header, trailer, fmt.Errorf("error decoding backup line %d: %w", n, err)
+		}
+
+		switch {
+		case n == 1:
+			if l.Header == nil || l.Header.Format != FORMAT {
+				return header, trailer, fmt.Errorf("not a backup archive")
+			}
+			if l.Header.Version < 1 || l.Header.Version > FORMAT_VERSION {
+				return header, trailer, fmt.Errorf("backup archive version %d is not supported, up to %d is", l.Header.Version, FORMAT_VERSION)
+			}
+			header = *l.Header
+		case l.Trailer != nil:
+			trailer = *l.Trailer
+			if _, err := br.ReadByte(); !errors.Is(err, io.EOF) {
+				return header, trailer, fmt.Errorf("backup archive has data after its trailer")
+			}
+			return header, trailer, check(trailer, rows, hex.EncodeToString(h.Sum(nil)))
+		case !slices.Contains(Tables, l.Table):
+			return header, trailer, fmt.Errorf("unknown table %q at backup line %d", l.Table, n)
+		default:
+			rows[l.Table]++
+			if err := fn(l.Table, l.Row); err != nil {
+				return header, trailer, fmt.Errorf("error at backup line %d: %w", n, err)
+			}
+		}
+		h.Write(data)
+	}
+}
+
+// check compares the rows counted and the hash of the lines read with the
+// trailer
+func check(trailer Trailer, rows map[string]int, sum string) error {
+	if sum != trailer.SHA256 {
+		return fmt.Errorf("backup archive checksum %s does not match %s", sum, trailer.SHA256)
+	}
+	for _, table := range Tables {
+		if rows[table] != trailer.Rows[table] {
+			return fmt.Errorf("backup archive holds %d rows of %s, its trailer counts %d", rows[table], table, trailer.Rows[table])
+		}
+	}
+
+	return nil
+}
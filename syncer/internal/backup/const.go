@@ -0,0 +1,30 @@
+package backup
+
+const (
+	// FORMAT identifies a backup archive
+	FORMAT = "ncrawler-backup"
+	// FORMAT_VERSION is the version of the archives written, archives of
+	// this version and the earlier ones are read
+	FORMAT_VERSION = 1
+
+	TABLE_NEWS            = "news"
+	TABLE_NEWS_ENTITIES   = "news_entities"
+	TABLE_REVISIONS       = "article_revisions"
+	TABLE_TRANSLATIONS    = "news_translations"
+	TABLE_NEWS_ARCHIVE    = "news_archive"
+	TABLE_SYNC_RUNS       = "sync_runs"
+	TABLE_CLUSTERS        = "story_clusters"
+	TABLE_CLUSTER_MEMBERS = "story_cluster_members"
+	TABLE_TOMBSTONES      = "news_tombstones"
+	TABLE_AI_USAGE        = "ai_usage"
+
+	// PAGE_SIZE is the number of rows read from the datastore and restored
+	// at a time
+	PAGE_SIZE = 500
+)
+
+// Tables are the tables of an archive
+var Tables = []string{
+	TABLE_NEWS, TABLE_NEWS_ENTITIES, TABLE_REVISIONS, TABLE_TRANSLATIONS, TABLE_NEWS_ARCHIVE, TABLE_SYNC_RUNS,
+	TABLE_CLUSTERS, TABLE_CLUSTER_MEMBERS, TABLE_TOMBSTONES, TABLE_AI_USAGE,
+}
@@ -0,0 +1,188 @@
+// Package cluster groups news stories of different sources about the same
+// event by the TF-IDF cosine similarity of their text within a time window
+package cluster
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"ncrawler/internal/dto"
+)
+
+var stopWords = map[string]struct{}{}
+
+func init() {
+	for _, w := range strings.Fields(`a about after an and are as at be been before but by can could did do
+	for from had has have he her his how if in into is it its more most new not of on one or our out over
+	said says she so than that the their them then there they this to two up was we were what when which
+	who will with would year years you also just like now may against amid`) {
+		stopWords[w] = struct{}{}
+	}
+}
+
+// Terms returns the normalized term frequencies of the headline and the lead
+// of the story
+func Terms(n dto.News) map[string]float64 {
+	counts := map[string]float64{}
+	for _, w := range words(n.Headline) {
+		counts[w] += HEADLINE_WEIGHT
+	}
+	lead := words(n.Story)
+	if len(lead) > LEAD_WORDS {
+		lead = lead[:LEAD_WORDS]
+	}
+	for _, w := range lead {
+		counts[w]++
+	}
+
+	return normalize(counts)
+}
+
+func words(text string) []string {
+	var ws []string
+	for _, w := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r) && !unicode.Is(unicode.Mn, r) && !unicode.Is(unicode.Mc, r)
+	}) {
+		if _, stop := stopWords[w]; !stop && len([]rune(w)) > 2 {
+			ws = append(ws, w)
+		}
+	}
+
+	return ws
+}
+
+func normalize(v map[string]float64) map[string]float64 {
+	var total float64
+	for _, x := range v {
+		total += x
+	}
+	if total == 0 {
+		return v
+	}
+	for k := range v {
+		v[k] /= total
+	}
+
+	return v
+}
+
+// Index holds the document frequencies of the recent news, used to weight
+// the terms by their inverse document frequency
+type Index struct {
+	docs int
+	df   map[string]int
+}
+
+// NewIndex counts the documents each term appears in
+func NewIndex(docs []map[string]float64) *Index {
+	ix := &Index{docs: len(docs), df: map[string]int{}}
+	for _, d := range docs {
+		for t := range d {
+			ix.df[t]++
+		}
+	}
+
+	return ix
+}
+
+func (ix *Index) idf(term string) float64 {
+	return math.Log(float64(ix.docs+1)/float64(ix.df[term]+1)) + 1
+}
+
+// Similarity returns the TF-IDF cosine similarity of two term vectors
+func (ix *Index) Similarity(a, b map[string]float64) float64 {
+	var dot, na, nb float64
+	for t, x := range a {
+		w := x * ix.idf(t)
+		na += w * w
+		if y, ok := b[t]; ok {
+			dot += w * y * ix.idf(t)
+		}
+	}
+	for t, y := range b {
+		w := y * ix.idf(t)
+		nb += w * w
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
+
+// Assign returns the most similar cluster still open at the given time, nil
+// when none reaches THRESHOLD
+func (ix *Index) Assign(clusters []*dto.StoryCluster, terms map[string]float64, at time.Time) (*dto.StoryCluster, float64) {
+	var (
+		best    *dto.StoryCluster
+		bestSim float64
+	)
+	for _, c := range clusters {
+		if at.Sub(c.LastSeen) > WINDOW {
+			continue
+		}
+		if sim := ix.Similarity(terms, c.Centroid); sim >= THRESHOLD && sim > bestSim {
+			best, bestSim = c, sim
+		}
+	}
+
+	return best, bestSim
+}
+
+// New opens a cluster with the news as its representative
+func New(n dto.News, terms map[string]float64, at time.Time) *dto.StoryCluster {
+	return &dto.StoryCluster{
+		Headline:           n.Headline,
+		RepresentativeLink: n.SourceLink,
+		FirstSeen:          at,
+		LastSeen:           at,
+		Size:               1,
+		Centroid:           trim(terms),
+	}
+}
+
+// Add moves the centroid of the cluster towards the terms of a new member
+func Add(c *dto.StoryCluster, terms map[string]float64, at time.Time) {
+	centroid := make(map[string]float64, len(c.Centroid)+len(terms))
+	n := float64(c.Size)
+	for t, x := range c.Centroid {
+		centroid[t] = x * n / (n + 1)
+	}
+	for t, x := range terms {
+		centroid[t] += x / (n + 1)
+	}
+
+	c.Centroid = trim(centroid)
+	c.Size++
+	if at.After(c.LastSeen) {
+		c.LastSeen = at
+	}
+}
+
+// trim keeps the CENTROID_TERMS heaviest terms
+func trim(v map[string]float64) map[string]float64 {
+	if len(v) <= CENTROID_TERMS {
+		return v
+	}
+
+	terms := make([]string, 0, len(v))
+	for t := range v {
+		terms = append(terms, t)
+	}
+	sort.Slice(terms, func(i, j int) bool {
+		if v[terms[i]] != v[terms[j]] {
+			return v[terms[i]] > v[terms[j]]
+		}
+		return terms[i] < terms[j]
+	})
+
+	trimmed := make(map[string]float64, CENTROID_TERMS)
+	for _, t := range terms[:CENTROID_TERMS] {
+		trimmed[t] = v[t]
+	}
+
+	return normalize(trimmed)
+}
@@ -0,0 +1,17 @@
+package cluster
+
+import "time"
+
+const (
+	// WINDOW is how long a cluster accepts new articles after its last one
+	WINDOW = 48 * time.Hour
+	// THRESHOLD is the cosine similarity needed to join a cluster
+	THRESHOLD = 0.3
+	// CENTROID_TERMS is the number of terms kept in a cluster centroid
+	CENTROID_TERMS = 60
+	// LEAD_WORDS limits the story text vectorized to its lead, where the
+	// facts of the event are
+	LEAD_WORDS = 300
+	// HEADLINE_WEIGHT boosts the terms of the headline
+	HEADLINE_WEIGHT = 3
+)
@@ -0,0 +1,21 @@
+package corroborate
+
+import "time"
+
+const (
+	VERIFIED   = "VERIFIED"
+	DEVELOPING = "DEVELOPING"
+	UNVERIFIED = "UNVERIFIED"
+	DISPUTED   = "DISPUTED"
+
+	// MIN_SOURCES is the number of independent sources needed to verify a story
+	MIN_SOURCES = 2
+	// DEVELOPING_WINDOW is how long a story reported by one source is
+	// developing before it becomes unverified
+	DEVELOPING_WINDOW = 12 * time.Hour
+	// DISPUTE_TOLERANCE is the relative difference between the numbers of two
+	// sources above which the fact is disputed
+	DISPUTE_TOLERANCE = 0.2
+	// FACT_KEY_DISTANCE is how many words after a number its noun is looked for
+	FACT_KEY_DISTANCE = 3
+)
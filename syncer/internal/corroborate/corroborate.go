@@ -0,0 +1,250 @@
+// Package corroborate computes the verification status of a story from the
+// articles of the independent sources reporting it
+package corroborate
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"ncrawler/internal/dto"
+	"ncrawler/internal/entities"
+)
+
+// Evidence is the verification status of a story with what supports it
+type Evidence struct {
+	Status  string
+	Sources int
+	// Disputed are the facts the sources report different numbers or names
+	// for
+	Disputed []string
+}
+
+var scales = map[string]float64{
+	"thousand": 1e3, "million": 1e6, "mn": 1e6, "billion": 1e9, "bn": 1e9,
+	"trillion": 1e12, "lakh": 1e5, "crore": 1e7,
+}
+
+// ignoredKeys follow numbers that are not facts of the event
+var ignoredKeys = map[string]struct{}{}
+
+func init() {
+	for _, w := range strings.Fields(`the and of in to for from on at by with was were are has have had
+	that this than more less least about around nearly some over under per cent percent
+	years year months month weeks week days day hours hour minutes minute seconds times
+	am pm gmt bst edt est ist local time`) {
+		ignoredKeys[w] = struct{}{}
+	}
+}
+
+// Assess returns the verification status of the story reported by the
+// articles, a cluster first seen at the given time. Near duplicates of
+// another story are copies, not independent sources. A story is disputed when
+// two sources report different numbers of the same thing or name different
+// people for the same title, verified when MIN_SOURCES independent sources
+// report it and developing or unverified when reported by one source only.
+func Assess(articles []dto.News, firstSeen time.Time) Evidence {
+	facts := map[string]map[string][]float64{} // key -> source -> values
+	people := map[string]map[string][]string{} // title -> source -> names
+	sources := map[string]struct{}{}
+	for _, a := range articles {
+		if a.DuplicateOf != "" {
+			continue
+		}
+		sources[a.SourceName] = struct{}{}
+		for key, values := range keyedNumbers(a.Headline + "\n" + a.Story) {
+			if facts[key] == nil {
+				facts[key] = map[string][]float64{}
+			}
+			facts[key][a.SourceName] = append(facts[key][a.SourceName], values...)
+		}
+		for title, names := range entities.Titled(a.Headline + "\n" + a.Story) {
+			if people[title] == nil {
+				people[title] = map[string][]string{}
+			}
+			people[title][a.SourceName] = append(people[title][a.SourceName], names...)
+		}
+	}
+
+	disputed := append(disputes(facts), nameDisputes(people)...)
+	sort.Strings(disputed)
+
+	ev := Evidence{Sources: len(sources), Disputed: disputed}
+	switch {
+	case len(ev.Disputed) > 0:
+		ev.Status = DISPUTED
+	case ev.Sources >= MIN_SOURCES:
+		ev.Status = VERIFIED
+	case time.Since(firstSeen) < DEVELOPING_WINDOW:
+		ev.Status = DEVELOPING
+	default:
+		ev.Status = UNVERIFIED
+	}
+
+	return ev
+}
+
+// disputes compares the numbers each source gives for the same key. Sources
+// giving several numbers for a key are ambiguous and left out.
+func disputes(facts map[string]map[string][]float64) []string {
+	var disputed []string
+	for key, bySource := range facts {
+		var (
+			names  []string
+			values = map[string]float64{}
+		)
+		for source, vs := range bySource {
+			if v, ok := single(vs); ok {
+				names = append(names, source)
+				values[source] = v
+			}
+		}
+		if len(names) < 2 {
+			continue
+		}
+		sort.Strings(names)
+
+		lo, hi := names[0], names[0]
+		for _, n := range names[1:] {
+			if values[n] < values[lo] {
+				lo = n
+			}
+			if values[n] > values[hi] {
+				hi = n
+			}
+		}
+		if (values[hi]-values[lo])/values[hi] > DISPUTE_TOLERANCE {
+			disputed = append(disputed, fmt.Sprintf("%s: %s (%s) vs %s (%s)",
+				key, formatNumber(values[lo]), lo, formatNumber(values[hi]), hi))
+		}
+	}
+	sort.Strings(disputed)
+
+	return disputed
+}
+
+// nameDisputes compares the people each source names for the same title, a
+// name agrees with a longer one holding all its words, like "Yunus" with
+// "Muhammad Yunus". Sources naming several people for a title are ambiguous
+// and left out.
+func nameDisputes(people map[string]map[string][]string) []string {
+	var disputed []string
+	for title, bySource := range people {
+		var (
+			sources []string
+			named   = map[string]string{}
+		)
+		for source, names := range bySource {
+			if name, ok := singleName(names); ok {
+				sources = append(sources, source)
+				named[source] = name
+			}
+		}
+		sort.Strings(sources)
+
+	pairs:
+		for i, a := range sources {
+			for _, b := range sources[i+1:] {
+				if !sameName(named[a], named[b]) {
+					disputed = append(disputed, fmt.Sprintf("%s: %s (%s) vs %s (%s)", title, named[a], a, named[b], b))
+					break pairs
+				}
+			}
+		}
+	}
+
+	return disputed
+}
+
+// singleName returns the longest of the names when they all name the same
+// person
+func singleName(names []string) (string, bool) {
+	longest := names[0]
+	for _, n := range names[1:] {
+		if !sameName(n, longest) {
+			return "", false
+		}
+		if len(n) > len(longest) {
+			longest = n
+		}
+	}
+
+	return longest, true
+}
+
+// sameName reports whether the words of one name are all in the other
+func sameName(a, b string) bool {
+	wa, wb := strings.Fields(strings.ToLower(a)), strings.Fields(strings.ToLower(b))
+	if len(wa) > len(wb) {
+		wa, wb = wb, wa
+	}
+	for _, w := range wa {
+		if !slices.Contains(wb, w) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// single returns the value when all the values are the same
+func single(values []float64) (float64, bool) {
+	for _, v := range values[1:] {
+		if v != values[0] {
+			return 0, false
+		}
+	}
+
+	return values[0], values[0] > 0
+}
+
+// keyedNumbers returns the numbers of the text by the noun that follows them,
+// like 128 for "people" in "at least 128 people were killed". Years are
+// left out.
+func keyedNumbers(text string) map[string][]float64 {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return unicode.IsSpace(r) || r == '(' || r == ')' || r == '"' || r == '“' || r == '”'
+	})
+
+	found := map[string][]float64{}
+	for i, w := range words {
+		v, err := strconv.ParseFloat(strings.ReplaceAll(strings.Trim(w, ".,;:"), ",", ""), 64)
+		if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+			continue
+		}
+
+		j := i + 1
+		if j < len(words) {
+			if scale, ok := scales[strings.Trim(words[j], ".,;:")]; ok {
+				v *= scale
+				j++
+			}
+		}
+		if v == math.Trunc(v) && v >= 1900 && v <= 2100 && j == i+1 {
+			continue
+		}
+
+		for k := j; k < len(words) && k < j+FACT_KEY_DISTANCE; k++ {
+			key := strings.TrimFunc(words[k], func(r rune) bool { return !unicode.IsLetter(r) })
+			if _, ignored := ignoredKeys[key]; ignored || len(key) < 3 {
+				if strings.ContainsAny(words[k], ".,;:") {
+					break
+				}
+				continue
+			}
+			found[key] = append(found[key], v)
+			break
+		}
+	}
+
+	return found
+}
+
+func formatNumber(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
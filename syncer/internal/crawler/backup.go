@@ -0,0 +1,431 @@
+package crawler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"time"
+
+	"ncrawler/internal/backup"
+	"ncrawler/internal/definition"
+	"ncrawler/internal/dto"
+	"ncrawler/pkg/helpers"
+)
+
+// backupTables are the tables each datastore keeps, the rows of the other
+// tables of an archive are skipped on restore
+var backupTables = map[string][]string{
+	helpers.DATASTORE_PG:      backup.Tables,
+	helpers.DATASTORE_SQLITE3: {backup.TABLE_NEWS, backup.TABLE_NEWS_ENTITIES, backup.TABLE_NEWS_ARCHIVE, backup.TABLE_TOMBSTONES},
+}
+
+// BackupReport counts the rows backed up or restored of every table
+type BackupReport struct {
+	Header backup.Header
+	Rows   map[string]int
+	// Skipped counts the rows of the tables the datastore does not keep
+	Skipped map[string]int
+}
+
+// RunBackup writes the news, their entities, revisions and translations, the
+// archived news and the sync runs of the datastore to a backup archive at
+// path. The archive is written next to it first, so a failed backup leaves
+// no archive behind.
+func RunBackup(datastore, path string) (BackupReport, error) {
+	report := BackupReport{Rows: map[string]int{}, Skipped: map[string]int{}}
+	if _, ok := backupTables[datastore]; !ok {
+		return report, fmt.Errorf("unknown datastore %s", datastore)
+	}
+	if err := checkSchema(datastore); err != nil {
+		return report, err
+	}
+
+	migrator, err := helpers.GetMigrator(datastore)
+	if err != nil {
+		return report, err
+	}
+	status, err := migrator.Status()
+	if err != nil {
+		return report, err
+	}
+	store, err := helpers.GetBackupStore(datastore)
+	if err != nil {
+		return report, err
+	}
+
+	tmp := path + ".tmp"
+	file, err := os.Create(tmp)
+	if err != nil {
+		return report, fmt.Errorf("error creating backup: %w", err)
+	}
+	defer os.Remove(tmp)
+	defer file.Close()
+
+	header := backup.Header{CreatedAt: time.Now().UTC(), Datastore: datastore, SchemaVersion: status.Current}
+	w, err := backup.NewWriter(file, header)
+	if err != nil {
+		return report, err
+	}
+	if err := writeBackup(store, w); err != nil {
+		return report, err
+	}
+	if err := w.Close(); err != nil {
+		return report, err
+	}
+	if err := file.Sync(); err != nil {
+		return report, fmt.Errorf("error syncing backup: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return report, fmt.Errorf("error closing backup: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return report, fmt.Errorf("error saving backup: %w", err)
+	}
+
+	report.Header, report.Rows = header, w.Rows()
+	slog.Info("news data backed up", "datastore", datastore, "file", path, "news", report.Rows[backup.TABLE_NEWS])
+
+	return report, nil
+}
+
+// writeBackup writes the news page by page, each page followed by the rows
+// referencing it so they can be restored in order. The clusters come first
+// as their members reference both.
+func writeBackup(store definition.BackupStore, w *backup.Writer) error {
+	var afterID int64
+	for {
+		clusters, err := store.GetClustersAfter(afterID, backup.PAGE_SIZE)
+		if err != nil {
+			return err
+		}
+		if len(clusters) == 0 {
+			break
+		}
+		afterID = clusters[len(clusters)-1].Id
+
+		if err := writeRows(w, backup.TABLE_CLUSTERS, clusters); err != nil {
+			return err
+		}
+	}
+
+	var afterLink string
+	for {
+		newsStories, err := store.GetNewsAfter(afterLink, backup.PAGE_SIZE)
+		if err != nil {
+			return err
+		}
+		if len(newsStories) == 0 {
+			break
+		}
+
+		links := make([]string, len(newsStories))
+		for i, news := range newsStories {
+			links[i] = news.SourceLink
+		}
+		afterLink = links[len(links)-1]
+
+		if err := writeRows(w, backup.TABLE_NEWS, newsStories); err != nil {
+			return err
+		}
+		newsEntities, err := store.GetNewsEntitiesByLinks(links)
+		if err != nil {
+			return err
+		}
+		if err := writeRows(w, backup.TABLE_NEWS_ENTITIES, newsEntities); err != nil {
+			return err
+		}
+		revisions, err := store.GetRevisionsByLinks(links)
+		if err != nil {
+			return err
+		}
+		if err := writeRows(w, backup.TABLE_REVISIONS, revisions); err != nil {
+			return err
+		}
+		translations, err := store.GetTranslationsByLinks(links)
+		if err != nil {
+			return err
+		}
+		if err := writeRows(w, backup.TABLE_TRANSLATIONS, translations); err != nil {
+			return err
+		}
+		members, err := store.GetClusterMembersByLinks(links)
+		if err != nil {
+			return err
+		}
+		if err := writeRows(w, backup.TABLE_CLUSTER_MEMBERS, members); err != nil {
+			return err
+		}
+	}
+
+	afterLink = ""
+	for {
+		archived, err := store.GetArchivedNewsAfter(afterLink, backup.PAGE_SIZE)
+		if err != nil {
+			return err
+		}
+		if len(archived) == 0 {
+			break
+		}
+		afterLink = archived[len(archived)-1].News.SourceLink
+
+		if err := writeRows(w, backup.TABLE_NEWS_ARCHIVE, archived); err != nil {
+			return err
+		}
+	}
+
+	afterLink = ""
+	for {
+		tombstones, err := store.GetTombstonesAfter(afterLink, backup.PAGE_SIZE)
+		if err != nil {
+			return err
+		}
+		if len(tombstones) == 0 {
+			break
+		}
+		afterLink = tombstones[len(tombstones)-1].SourceLink
+
+		if err := writeRows(w, backup.TABLE_TOMBSTONES, tombstones); err != nil {
+			return err
+		}
+	}
+
+	afterID = 0
+	for {
+		usage, err := store.GetAIUsageAfter(afterID, backup.PAGE_SIZE)
+		if err != nil {
+			return err
+		}
+		if len(usage) == 0 {
+			break
+		}
+		afterID = usage[len(usage)-1].Id
+
+		if err := writeRows(w, backup.TABLE_AI_USAGE, usage); err != nil {
+			return err
+		}
+	}
+
+	runs, err := store.GetSyncRuns()
+	if err != nil {
+		return err
+	}
+
+	return writeRows(w, backup.TABLE_SYNC_RUNS, runs)
+}
+
+func writeRows[T any](w *backup.Writer, table string, rows []T) error {
+	for _, row := range rows {
+		if err := w.Write(table, row); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RunRestore loads the backup archive at path into the datastore, which must
+// be migrated and empty. The archive is read through and checked against its
+// checksum and row counts before anything is restored, and the rows of every
+// table are counted again once restored. A restore that fails midway empties
+// the restored tables again, so it can simply be run again. A backup of a
+// newer schema of the same datastore is refused. The tables the datastore
+// does not keep, like the revisions in sqlite3, are skipped. With verifyOnly
+// the archive is only checked.
+func RunRestore(datastore, path string, verifyOnly bool) (report BackupReport, err error) {
+	report = BackupReport{Rows: map[string]int{}, Skipped: map[string]int{}}
+
+	header, trailer, err := readBackup(path, func(table string, row json.RawMessage) error {
+		return restoreRows(nil, table, []json.RawMessage{row})
+	})
+	report.Header = header
+	if err != nil {
+		return report, err
+	}
+	if verifyOnly {
+		report.Rows = trailer.Rows
+		return report, nil
+	}
+
+	tables, ok := backupTables[datastore]
+	if !ok {
+		return report, fmt.Errorf("unknown datastore %s", datastore)
+	}
+	if err := checkSchema(datastore); err != nil {
+		return report, err
+	}
+	if err := checkBackupSchema(datastore, header); err != nil {
+		return report, err
+	}
+	store, err := helpers.GetBackupStore(datastore)
+	if err != nil {
+		return report, err
+	}
+	for _, table := range tables {
+		count, err := store.CountRows(table)
+		if err != nil {
+			return report, err
+		}
+		if count > 0 {
+			return report, fmt.Errorf("the %s datastore is not empty, %s holds %d rows", datastore, table, count)
+		}
+	}
+
+	// the tables were empty, so a failed restore empties them again
+	defer func() {
+		if err == nil {
+			return
+		}
+		if clearErr := store.ClearTables(tables); clearErr != nil {
+			err = errors.Join(err, clearErr)
+			return
+		}
+		slog.Warn("restore failed, the restored rows were removed", "datastore", datastore)
+	}()
+
+	r := &restorer{store: store, tables: tables, report: &report}
+	if _, _, err := readBackup(path, r.add); err != nil {
+		return report, err
+	}
+	if err := r.flush(); err != nil {
+		return report, err
+	}
+
+	for _, table := range tables {
+		count, err := store.CountRows(table)
+		if err != nil {
+			return report, err
+		}
+		if count != trailer.Rows[table] {
+			return report, fmt.Errorf("restored %d rows of %s, the backup holds %d", count, table, trailer.Rows[table])
+		}
+	}
+	slog.Info("news data restored", "datastore", datastore, "file", path, "news", report.Rows[backup.TABLE_NEWS])
+
+	return report, nil
+}
+
+// checkBackupSchema refuses a backup of a newer schema of the datastore, its
+// rows may hold data the current schema does not keep. A backup of an older
+// schema is restored with a warning, a backup of the other datastore is
+// restored through the common news format.
+func checkBackupSchema(datastore string, header backup.Header) error {
+	if header.Datastore != datastore {
+		return nil
+	}
+
+	migrator, err := helpers.GetMigrator(datastore)
+	if err != nil {
+		return err
+	}
+	status, err := migrator.Status()
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case header.SchemaVersion > status.Current:
+		return fmt.Errorf("the backup is of schema version %d, newer than version %d of the %s datastore", header.SchemaVersion, status.Current, datastore)
+	case header.SchemaVersion < status.Current:
+		slog.Warn("restoring a backup of an older schema", "datastore", datastore, "backup", header.SchemaVersion, "current", status.Current)
+	}
+
+	return nil
+}
+
+// readBackup reads the archive at path through
+func readBackup(path string, fn func(table string, row json.RawMessage) error) (backup.Header, backup.Trailer, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return backup.Header{}, backup.Trailer{}, err
+	}
+	defer file.Close()
+
+	return backup.Read(file, fn)
+}
+
+// restorer restores the rows of an archive a page at a time, a page holds
+// the rows of one table so they are restored in the order of the archive
+type restorer struct {
+	store  definition.BackupStore
+	tables []string
+	report *BackupReport
+
+	table string
+	rows  []json.RawMessage
+}
+
+func (r *restorer) add(table string, row json.RawMessage) error {
+	if !slices.Contains(r.tables, table) {
+		r.report.Skipped[table]++
+		return nil
+	}
+
+	if table != r.table || len(r.rows) >= backup.PAGE_SIZE {
+		if err := r.flush(); err != nil {
+			return err
+		}
+		r.table = table
+	}
+	r.rows = append(r.rows, row)
+
+	return nil
+}
+
+func (r *restorer) flush() error {
+	if len(r.rows) == 0 {
+		return nil
+	}
+	if err := restoreRows(r.store, r.table, r.rows); err != nil {
+		return err
+	}
+	r.report.Rows[r.table] += len(r.rows)
+	r.rows = nil
+
+	return nil
+}
+
+// restoreRows decodes the rows of the table and restores them, with a nil
+// store the rows are only decoded
+func restoreRows(store definition.BackupStore, table string, rows []json.RawMessage) error {
+	switch table {
+	case backup.TABLE_NEWS:
+		return restore(rows, store, func(s definition.BackupStore, v []dto.News) error { return s.RestoreNews(v) })
+	case backup.TABLE_NEWS_ENTITIES:
+		return restore(rows, store, func(s definition.BackupStore, v []dto.NewsEntity) error { return s.RestoreNewsEntities(v) })
+	case backup.TABLE_REVISIONS:
+		return restore(rows, store, func(s definition.BackupStore, v []dto.Revision) error { return s.RestoreRevisions(v) })
+	case backup.TABLE_TRANSLATIONS:
+		return restore(rows, store, func(s definition.BackupStore, v []dto.Translation) error { return s.RestoreTranslations(v) })
+	case backup.TABLE_NEWS_ARCHIVE:
+		return restore(rows, store, func(s definition.BackupStore, v []dto.ArchivedNews) error { return s.RestoreArchivedNews(v) })
+	case backup.TABLE_SYNC_RUNS:
+		return restore(rows, store, func(s definition.BackupStore, v []dto.SyncRun) error { return s.RestoreSyncRuns(v) })
+	case backup.TABLE_CLUSTERS:
+		return restore(rows, store, func(s definition.BackupStore, v []dto.StoryCluster) error { return s.RestoreClusters(v) })
+	case backup.TABLE_CLUSTER_MEMBERS:
+		return restore(rows, store, func(s definition.BackupStore, v []dto.ClusterMember) error { return s.RestoreClusterMembers(v) })
+	case backup.TABLE_TOMBSTONES:
+		return restore(rows, store, func(s definition.BackupStore, v []dto.Tombstone) error { return s.RestoreTombstones(v) })
+	case backup.TABLE_AI_USAGE:
+		return restore(rows, store, func(s definition.BackupStore, v []dto.AIUsage) error { return s.RestoreAIUsage(v) })
+	}
+
+	return fmt.Errorf("unknown table %s", table)
+}
+
+func restore[T any](rows []json.RawMessage, store definition.BackupStore, fn func(definition.BackupStore, []T) error) error {
+	values := make([]T, len(rows))
+	for i, row := range rows {
+		if err := json.Unmarshal(row, &values[i]); err != nil {
+			return fmt.Errorf("error decoding row: %w", err)
+		}
+	}
+	if store == nil {
+		return nil
+	}
+
+	return fn(store, values)
+}
@@ -0,0 +1,307 @@
+package crawler
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"ncrawler/internal/ai"
+	"ncrawler/internal/dto"
+	"ncrawler/internal/factcheck"
+	"ncrawler/pkg/helpers"
+)
+
+// RunBatch summarizes the news stored without a summary through the Batch
+// API. Batches left open by a previous run are resumed first, then a new
+// batch of at most limit pending articles is submitted. With wait, the
+// batches are polled every pollInterval until they are applied, otherwise
+// every batch is advanced by one step so the command can run from cron. The
+// client talks to the Batch API, ai.NewBatchClient or a stub.
+func RunBatch(client ai.BatchClient, limit int, wait bool, pollInterval time.Duration) error {
+	dbPool := helpers.GetDbPool()
+	opts := ai.DefaultOptions()
+
+	batches, err := dbPool.AIBatches.GetOpenBatches()
+	if err != nil {
+		return err
+	}
+	if len(batches) > 0 {
+		slog.Info("resuming open batches", "count", len(batches))
+	}
+
+	batch, err := prepareBatch(opts, limit)
+	if err != nil {
+		return err
+	}
+	if batch != nil {
+		batches = append(batches, *batch)
+	}
+
+	for {
+		open := batches[:0]
+		for _, b := range batches {
+			err := advanceBatch(client, &b)
+			if errors.Is(err, errBatchHeld) {
+				slog.Warn("holding batch until the budget allows it", "batch", b.Id, "model", b.Model)
+				continue
+			}
+			if err != nil {
+				slog.Error("failed to advance batch", "batch", b.Id, "error", err)
+			}
+			if !isBatchClosed(b.Status) {
+				open = append(open, b)
+			}
+		}
+		batches = open
+
+		if len(batches) == 0 || !wait {
+			return nil
+		}
+
+		slog.Info("waiting for batches", "open", len(batches), "poll", pollInterval.String())
+		time.Sleep(pollInterval)
+	}
+}
+
+// prepareBatch writes the request file of the pending articles and records
+// the batch, nil is returned when there is nothing to summarize. The batch is
+// cut to the remaining budget and skipped when it is exhausted.
+func prepareBatch(opts ai.Options, limit int) (*dto.AIBatch, error) {
+	dbPool := helpers.GetDbPool()
+
+	pending, err := dbPool.News.GetPendingSummaries(limit)
+	if err != nil {
+		return nil, err
+	}
+	if len(pending) == 0 {
+		slog.Info("no pending articles to summarize")
+		return nil, nil
+	}
+
+	opts, pending, err = ai.BatchBudget(opts, pending)
+	if errors.Is(err, ai.ErrBudgetExceeded) {
+		slog.Warn("skipping new batch", "cause", err)
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return queueBatch(opts, pending)
+}
+
+// queueBatch writes the request file of the articles and records the batch,
+// which the next RunBatch submits. nil is returned when no article fits in a
+// batch request.
+func queueBatch(opts ai.Options, pending []dto.News) (*dto.AIBatch, error) {
+	dbPool := helpers.GetDbPool()
+
+	if err := os.MkdirAll(ai.BATCH_DIR, 0o755); err != nil {
+		return nil, fmt.Errorf("error creating batch directory: %w", err)
+	}
+
+	b := &dto.AIBatch{
+		Id:            helpers.GetRandomID(),
+		Model:         opts.Model,
+		PromptVersion: opts.PromptVersion,
+		Status:        ai.BATCH_STATUS_PREPARED,
+	}
+	b.FilePath = filepath.Join(ai.BATCH_DIR, b.Id+".jsonl")
+
+	ids, err := ai.WriteBatchFile(b.FilePath, pending, opts)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) < len(pending) {
+		slog.Warn("articles too long for a batch request are left for sync", "count", len(pending)-len(ids))
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	b.RequestCount = len(ids)
+
+	if err := dbPool.AIBatches.AddBatch(*b, ids); err != nil {
+		return nil, err
+	}
+	slog.Info("batch prepared", "batch", b.Id, "file", b.FilePath, "requests", b.RequestCount)
+
+	return b, nil
+}
+
+// errBatchHeld is returned for a prepared batch the budget does not allow to
+// submit yet, it is left for a later run
+var errBatchHeld = errors.New("batch held by the budget")
+
+// advanceBatch moves the batch one step further: a prepared batch is
+// submitted, a submitted batch is polled and a finished batch is applied
+func advanceBatch(client ai.BatchClient, b *dto.AIBatch) error {
+	dbPool := helpers.GetDbPool()
+
+	switch {
+	case b.Status == ai.BATCH_STATUS_PREPARED:
+		// a batch queued before the budget ran out waits for the next day or
+		// month, it is not submitted at a model the caps no longer allow
+		budgeted, err := ai.CheckBudget(ai.Options{Model: b.Model})
+		if errors.Is(err, ai.ErrBudgetExceeded) || (err == nil && budgeted.Model != b.Model) {
+			return errBatchHeld
+		}
+		if err != nil {
+			return err
+		}
+		if err := submitBatch(client, b); err != nil {
+			return err
+		}
+
+	case b.OutputFileID == "":
+		pb, err := ai.RetrieveBatch(client, b.ProviderBatchID)
+		if err != nil {
+			return err
+		}
+		b.Status = pb.Status
+		if pb.OutputFileID != nil {
+			b.OutputFileID = *pb.OutputFileID
+		}
+		if pb.ErrorFileID != nil {
+			b.ErrorFileID = *pb.ErrorFileID
+		}
+		slog.Info("batch polled", "batch", b.Id, "status", b.Status,
+			"completed", pb.RequestCounts.Completed, "failed", pb.RequestCounts.Failed, "total", pb.RequestCounts.Total)
+
+		// expired and cancelled batches may still hold partial results
+		switch {
+		case !isBatchFinished(b.Status):
+		case b.OutputFileID != "":
+			if err := applyBatch(client, b); err != nil {
+				return err
+			}
+		case b.Status == "completed":
+			// every request failed, there is only an error file
+			b.Status = ai.BATCH_STATUS_APPLIED
+		}
+
+	default:
+		if err := applyBatch(client, b); err != nil {
+			return err
+		}
+	}
+
+	return dbPool.AIBatches.UpdateBatch(*b)
+}
+
+// submitBatch submits a prepared batch once: a provider batch created for it
+// before a crash is taken over, and the uploaded file is saved before the
+// provider batch is created so a retry does not upload it again
+func submitBatch(client ai.BatchClient, b *dto.AIBatch) error {
+	pb, err := ai.FindBatch(client, b.Id)
+	if err != nil {
+		return err
+	}
+	if pb != nil {
+		b.ProviderBatchID, b.InputFileID, b.Status = pb.ID, pb.InputFileID, pb.Status
+		slog.Info("batch already submitted", "batch", b.Id, "provider_batch", b.ProviderBatchID)
+		return nil
+	}
+
+	if b.InputFileID == "" {
+		if b.InputFileID, err = ai.UploadBatchFile(client, b.FilePath); err != nil {
+			return err
+		}
+		if err := helpers.GetDbPool().AIBatches.UpdateBatch(*b); err != nil {
+			return err
+		}
+	}
+
+	created, err := ai.CreateBatch(client, b.InputFileID, b.Id)
+	if err != nil {
+		return err
+	}
+	b.ProviderBatchID, b.Status = created.ID, created.Status
+	slog.Info("batch submitted", "batch", b.Id, "provider_batch", b.ProviderBatchID)
+
+	return nil
+}
+
+// applyBatch saves the summaries of the batch output to the news rows. It
+// can run again when the batch could not be marked applied, the summaries
+// are saved the same and the usage is recorded once per batch.
+func applyBatch(client ai.BatchClient, b *dto.AIBatch) error {
+	dbPool := helpers.GetDbPool()
+
+	results, err := ai.GetBatchResults(client, b.OutputFileID)
+	if err != nil {
+		return err
+	}
+
+	ids := make([]string, 0, len(results))
+	for _, res := range results {
+		ids = append(ids, res.CustomID)
+	}
+	newsStories, err := dbPool.News.GetNewsByIDs(ids)
+	if err != nil {
+		return err
+	}
+	newsByID := make(map[string]dto.News, len(newsStories))
+	for _, n := range newsStories {
+		newsByID[n.Id] = n
+	}
+
+	opts := ai.DefaultOptions()
+	opts.Model, opts.PromptVersion = b.Model, b.PromptVersion
+
+	var applied, failed, quarantined int
+	for _, res := range results {
+		news, ok := newsByID[res.CustomID]
+		if !ok {
+			slog.Warn("batch result of unknown news", "batch", b.Id, "id", res.CustomID)
+			continue
+		}
+		ai.RecordBatchUsage(opts, b.Id, news, res)
+
+		if res.Summary == nil {
+			slog.Error("batch request failed", "batch", b.Id, "id", res.CustomID, "error", res.Error)
+			failed++
+			continue
+		}
+
+		check := factcheck.Verify(news.Headline+"\n"+news.Story, append([]string{res.Summary.Summary}, res.Summary.KeyPoints...)...)
+		news.Summary = res.Summary.Summary
+		news.BulletPoints = res.Summary.KeyPoints
+		news.MetaKeywords = strings.Join(ai.GroundedCategories(news, res.Summary.Metadata.Categories), ", ")
+		news.SummaryMethod = dto.SUMMARY_METHOD_LLM
+		news.ConsistencyScore = check.Score
+		news.UnsupportedClaims = check.UnsupportedTexts()
+		news.ModelVerificationStatus = res.Summary.Metadata.VerificationStatus
+		if quarantine(&news, res.Summary) {
+			quarantined++
+		}
+
+		if err := dbPool.News.UpdateSummary(news); err != nil {
+			return err
+		}
+		applied++
+	}
+
+	b.Status = ai.BATCH_STATUS_APPLIED
+	slog.Info("batch applied", "batch", b.Id, "applied", applied, "failed", failed, "quarantined", quarantined)
+
+	return nil
+}
+
+// isBatchFinished reports whether the provider stopped working on the batch
+func isBatchFinished(status string) bool {
+	switch status {
+	case "completed", "failed", "expired", "cancelled":
+		return true
+	}
+
+	return false
+}
+
+// isBatchClosed reports whether nothing more can be done with the batch
+func isBatchClosed(status string) bool {
+	return status == ai.BATCH_STATUS_APPLIED || (status != "completed" && isBatchFinished(status))
+}
@@ -0,0 +1,80 @@
+package crawler
+
+import (
+	"log/slog"
+
+	"ncrawler/internal/urlcanon"
+	"ncrawler/pkg/helpers"
+)
+
+// CanonicalizeReport is what a canonicalization did, or would do on a dry run
+type CanonicalizeReport struct {
+	Checked int
+	// Moved counts the stories stored under their canonical link, Merged
+	// the ones merged into a story already stored there
+	Moved  int
+	Merged int
+}
+
+// RunCanonicalize rewrites the links of the stories stored before the links
+// were canonicalised to their canonical form, so they are found again by the
+// crawlers. A story whose canonical link is already stored, like when it was
+// crawled again under it, is merged into that story. With dryRun nothing is
+// changed.
+func RunCanonicalize(datastore string, dryRun bool) (CanonicalizeReport, error) {
+	var report CanonicalizeReport
+	if err := checkSchema(datastore); err != nil {
+		return report, err
+	}
+	store, err := helpers.GetLinkStore(datastore)
+	if err != nil {
+		return report, err
+	}
+	newsStore, err := helpers.GetNewsStore(datastore)
+	if err != nil {
+		return report, err
+	}
+
+	var afterLink string
+	for {
+		links, err := store.GetLinksAfter(afterLink, CANONICALIZE_PAGE_SIZE)
+		if err != nil {
+			return report, err
+		}
+		if len(links) == 0 {
+			break
+		}
+		afterLink = links[len(links)-1]
+		report.Checked += len(links)
+
+		for _, link := range links {
+			canonical := urlcanon.Normalize(link)
+			if canonical == link {
+				continue
+			}
+
+			merged := false
+			if dryRun {
+				stored, err := newsStore.GetNewsByLinks([]string{canonical})
+				if err != nil {
+					return report, err
+				}
+				merged = len(stored) > 0
+			} else if merged, err = store.CanonicalizeLink(link, canonical); err != nil {
+				return report, err
+			}
+
+			if merged {
+				report.Merged++
+			} else {
+				report.Moved++
+			}
+		}
+	}
+
+	if !dryRun {
+		slog.Info("news links canonicalized", "datastore", datastore, "moved", report.Moved, "merged", report.Merged)
+	}
+
+	return report, nil
+}
@@ -0,0 +1,153 @@
+package crawler
+
+import (
+	"log/slog"
+	"time"
+
+	"ncrawler/internal/cluster"
+	"ncrawler/internal/corroborate"
+	"ncrawler/internal/dto"
+	"ncrawler/pkg/helpers"
+)
+
+// clusterResult tells what clusterNews did
+type clusterResult struct {
+	clustered int
+	opened    int
+	// touched are the clusters that got new news by id
+	touched map[int64]*dto.StoryCluster
+}
+
+// clusterNews adds the stored news to the open story clusters, a news story
+// unlike all of them opens a new cluster
+func clusterNews(newsStories []dto.News) (clusterResult, error) {
+	res := clusterResult{touched: map[int64]*dto.StoryCluster{}}
+
+	dbPool := helpers.GetDbPool()
+	now := time.Now()
+	since := now.Add(-cluster.WINDOW)
+
+	// the document frequencies come from the news of the window
+	corpus, err := dbPool.News.GetNewsSince(since)
+	if err != nil {
+		return res, err
+	}
+	docs := make([]map[string]float64, 0, len(corpus))
+	for _, n := range corpus {
+		docs = append(docs, cluster.Terms(n))
+	}
+	ix := cluster.NewIndex(docs)
+
+	open, err := dbPool.Clusters.GetOpenClusters(since)
+	if err != nil {
+		return res, err
+	}
+	clusters := make([]*dto.StoryCluster, 0, len(open))
+	for i := range open {
+		clusters = append(clusters, &open[i])
+	}
+
+	for _, n := range newsStories {
+		if n.Quarantined {
+			continue
+		}
+
+		var before dto.StoryCluster
+		terms := cluster.Terms(n)
+		c, sim := ix.Assign(clusters, terms, now)
+		opened := c == nil
+		if opened {
+			c, sim = cluster.New(n, terms, now), 1
+		} else {
+			before = *c
+			cluster.Add(c, terms, now)
+		}
+
+		added, err := dbPool.Clusters.AddClusterMember(c, dto.ClusterMember{SourceLink: n.SourceLink, Similarity: sim})
+		if err != nil {
+			return res, err
+		}
+		// the story is in a cluster already, like on a re-run
+		if !added {
+			if !opened {
+				*c = before
+			}
+			continue
+		}
+
+		if opened {
+			clusters = append(clusters, c)
+			res.opened++
+		}
+		res.clustered++
+		res.touched[c.Id] = c
+	}
+
+	return res, nil
+}
+
+// RunClustering clusters the news of the last cluster.WINDOW that belong to
+// no cluster yet
+func RunClustering() error {
+	newsStories, err := helpers.GetDbPool().Clusters.GetUnclusteredNews(time.Now().Add(-cluster.WINDOW))
+	if err != nil {
+		return err
+	}
+
+	res, err := clusterNews(newsStories)
+	if err != nil {
+		return err
+	}
+	slog.Info("news clustered", "clustered", res.clustered, "opened", res.opened)
+
+	return corroborateClusters(res.touched)
+}
+
+// corroborateClusters computes the verification status of the news of the
+// clusters from the sources reporting them
+func corroborateClusters(clusters map[int64]*dto.StoryCluster) error {
+	dbPool := helpers.GetDbPool()
+
+	for id, c := range clusters {
+		members, err := dbPool.Clusters.GetClusterNews(id)
+		if err != nil {
+			return err
+		}
+
+		links := make([]string, 0, len(members))
+		for _, n := range members {
+			links = append(links, n.SourceLink)
+		}
+
+		ev := corroborate.Assess(members, c.FirstSeen)
+		if err := dbPool.News.UpdateVerification(links, ev.Status, ev.Sources, ev.Disputed); err != nil {
+			return err
+		}
+		if ev.Status == corroborate.DISPUTED {
+			slog.Warn("sources dispute the story", "cluster", id, "headline", c.Headline, "facts", ev.Disputed)
+		}
+	}
+
+	return nil
+}
+
+// RunCorroboration recomputes the verification status of the news of the
+// clusters seen within cluster.WINDOW, so developing stories nobody else
+// reported become unverified
+func RunCorroboration() error {
+	open, err := helpers.GetDbPool().Clusters.GetOpenClusters(time.Now().Add(-cluster.WINDOW))
+	if err != nil {
+		return err
+	}
+
+	clusters := make(map[int64]*dto.StoryCluster, len(open))
+	for i := range open {
+		clusters[open[i].Id] = &open[i]
+	}
+	if err := corroborateClusters(clusters); err != nil {
+		return err
+	}
+	slog.Info("verification status updated", "clusters", len(clusters))
+
+	return nil
+}
@@ -0,0 +1,94 @@
+package crawler
+
+import (
+	"time"
+
+	"ncrawler/internal/dto"
+)
+
+const (
+	// SUMMARY_MODE_LLM summarizes with the AI only
+	SUMMARY_MODE_LLM = "llm"
+	// SUMMARY_MODE_EXTRACTIVE summarizes with the extractive summarizer only
+	SUMMARY_MODE_EXTRACTIVE = "extractive"
+	// SUMMARY_MODE_FALLBACK summarizes with the AI and falls back to the extractive summarizer
+	SUMMARY_MODE_FALLBACK = "fallback"
+
+	// SUMMARY_MODE selects how articles are summarized during sync
+	SUMMARY_MODE = SUMMARY_MODE_FALLBACK
+
+	// MIN_CONSISTENCY_SCORE is the share of supported facts below which a summary is regenerated
+	MIN_CONSISTENCY_SCORE = 0.8
+	// MAX_SUMMARY_ATTEMPTS is the number of times a summary is generated at most
+	MAX_SUMMARY_ATTEMPTS = 2
+
+	// TRANSLATE_TO_ENGLISH translates the summary of non English articles into English
+	TRANSLATE_TO_ENGLISH = true
+	// TRANSLATE_TO_BANGLA translates the summary of English articles into Bangla
+	TRANSLATE_TO_BANGLA = false
+
+	// MERGE_POLICY is how a crawled story already stored, like on a re-run,
+	// is merged: dto.MERGE_KEEP, dto.MERGE_OVERWRITE or dto.MERGE_FILL_EMPTY
+	MERGE_POLICY = dto.MERGE_FILL_EMPTY
+
+	// SKIP_DUPLICATE_ENRICHMENT reuses the summary of the original story for
+	// its near duplicates instead of summarizing them again
+	SKIP_DUPLICATE_ENRICHMENT = true
+
+	// RECRAWL_ENABLED checks the recent articles for changes after every sync
+	RECRAWL_ENABLED = true
+	// RECRAWL_WINDOW is the age up to which articles are checked for changes
+	RECRAWL_WINDOW = 24 * time.Hour
+	// RECRAWL_INTERVAL is how often an article is checked for changes
+	RECRAWL_INTERVAL = 2 * time.Hour
+	// RECRAWL_LIMIT is the number of articles checked per run
+	RECRAWL_LIMIT = 50
+	// RESUMMARIZE_REVISIONS summarizes the changed articles again
+	RESUMMARIZE_REVISIONS = true
+
+	// RETRACTION_WINDOW is the age up to which articles are checked for retraction
+	RETRACTION_WINDOW = 30 * 24 * time.Hour
+	// RETRACTION_INTERVAL is how often an article is checked for retraction
+	RETRACTION_INTERVAL = 24 * time.Hour
+	// RETRACTION_DELAY is the pause between two checks
+	RETRACTION_DELAY = time.Second
+
+	// IMPORT_BATCH_SIZE is the number of imported stories upserted at a time
+	IMPORT_BATCH_SIZE = 500
+
+	// REPLICATE_PAGE_SIZE is the number of changed stories replicated at a
+	// time, the checkpoint is saved after every page
+	REPLICATE_PAGE_SIZE = 500
+
+	// CONFLICT_NEWEST keeps the story with the highest revision, then the
+	// last updated one, when both datastores changed it
+	CONFLICT_NEWEST = "newest"
+	// CONFLICT_SOURCE always replaces the story of the target datastore
+	CONFLICT_SOURCE = "source"
+	// CONFLICT_TARGET keeps the story of the target datastore
+	CONFLICT_TARGET = "target"
+
+	// PRUNE_ENABLED applies the retention rules after every sync
+	PRUNE_ENABLED = true
+	// RETENTION_FILE optionally holds the retention rules as a JSON array of
+	// dto.RetentionRule, without it the news are kept forever
+	RETENTION_FILE = "retention.json"
+	// PRUNE_PAGE_SIZE is the number of news archived at a time
+	PRUNE_PAGE_SIZE = 500
+
+	// ARCHIVE_TABLE moves the news past their retention to the news_archive table
+	ARCHIVE_TABLE = "table"
+	// ARCHIVE_NDJSON moves the news past their retention to gzip compressed
+	// NDJSON files in ARCHIVE_DIR
+	ARCHIVE_NDJSON = "ndjson"
+	// ARCHIVE_TO is where the news past their retention are moved after a sync
+	ARCHIVE_TO = ARCHIVE_TABLE
+	// ARCHIVE_DIR is where the NDJSON archives are written
+	ARCHIVE_DIR = "archive"
+
+	// CANONICALIZE_PAGE_SIZE is the number of stored links canonicalised at a time
+	CANONICALIZE_PAGE_SIZE = 500
+
+	// BACKUP_DIR is where the backups are written when no file is given
+	BACKUP_DIR = "backups"
+)
@@ -1,25 +1,53 @@
 package crawler
 
 import (
+	"errors"
+	"fmt"
 	"log/slog"
 	"strings"
+	"time"
 
 	"ncrawler/internal/ai"
 	"ncrawler/internal/definition"
 	"ncrawler/internal/dto"
+	"ncrawler/internal/extractive"
+	"ncrawler/internal/factcheck"
+	"ncrawler/internal/lang"
+	"ncrawler/internal/revision"
 	"ncrawler/internal/sources/cnn"
+	"ncrawler/internal/urlcanon"
 	"ncrawler/pkg/helpers"
 )
 
 type crawler struct {
+	// datastore is where the news are synced, helpers.DATASTORE_PG or
+	// helpers.DATASTORE_SQLITE3 to crawl offline
+	datastore string
+	// llmPaused is set once the AI budget is exceeded during the sync
+	llmPaused bool
 }
 
 func GetCrawler() definition.Crawler {
-	return &crawler{}
+	return &crawler{datastore: helpers.DATASTORE_PG}
+}
+
+// GetCrawlerFor returns the crawler syncing the news to the datastore
+func GetCrawlerFor(datastore string) definition.Crawler {
+	return &crawler{datastore: datastore}
 }
 
 func (c *crawler) Sync() error {
+	if c.datastore != helpers.DATASTORE_PG {
+		return c.syncLocal()
+	}
+
+	if err := checkSchema(helpers.DATASTORE_PG); err != nil {
+		return err
+	}
+
 	sources := getSources()
+	report := newSyncReport()
+	defer report.Finish()
 
 	for _, source := range sources {
 		slog.Info("syncing news data")
@@ -28,41 +56,129 @@ func (c *crawler) Sync() error {
 			return err
 		}
 		slog.Info("news data fetched", "count", len(latestNews))
+		report.Fetched += len(latestNews)
 
-		// Sanitize the data
-		latestNews.Sanitize()
+		prepareNews(latestNews)
 		slog.Info("news data sanitized")
 
 		slog.Info("generating summary for news data")
 		// Generate Summary from the story with AI
+		var (
+			translations []dto.Translation
+			newsEntities []dto.NewsEntity
+		)
 		for i := range latestNews {
-			slog.Info("generating summary", "headline", latestNews[i].Headline)
-			summary, err := GenerateSummary(latestNews[i])
+			latestNews[i].Language = lang.Detect(latestNews[i].Headline + "\n" + latestNews[i].Story)
+
+			original, err := linkDuplicate(&latestNews[i], latestNews[:i])
 			if err != nil {
-				slog.Error("failed to generate summary", "error", err)
+				slog.Error("failed to look for near duplicates", "error", err)
+			}
+			if original != nil {
+				report.Duplicates++
+			}
+
+			if original != nil && SKIP_DUPLICATE_ENRICHMENT && original.Summary != "" {
+				reuseSummary(&latestNews[i], *original)
+				newsEntities = append(newsEntities, extractEntities(latestNews[i])...)
 				continue
 			}
 
-			latestNews[i].Summary = summary.Summary
-			latestNews[i].BulletPoints = summary.KeyPoints
-			latestNews[i].MetaKeywords = strings.Join(summary.Metadata.Categories, ", ")
+			slog.Info("generating summary", "headline", latestNews[i].Headline)
+			if err := c.summarize(&latestNews[i], report); err != nil {
+				slog.Error("failed to generate summary", "error", err)
+				report.Failed++
+			}
+
+			if !latestNews[i].Quarantined {
+				translations = append(translations, c.translate(latestNews[i], report.RunID)...)
+			}
+			newsEntities = append(newsEntities, extractEntities(latestNews[i])...)
 		}
 		slog.Info("summary generated")
 
 		// Save it to db
 		// Get a connection pool to the database
 		dbPool := helpers.GetDbPool()
-		err = dbPool.News.AddNewsStories(latestNews)
+		upserted, err := dbPool.News.AddNewsStories(latestNews, MERGE_POLICY)
 		if err != nil {
 			return err
 		}
 
-		slog.Info("news data synced to database", "inserted", len(latestNews))
+		slog.Info("news data synced to database", "inserted", upserted.Inserted, "updated", upserted.Updated, "skipped", upserted.Skipped)
+		report.Inserted += upserted.Inserted
+		report.Updated += upserted.Updated
+		report.Skipped += upserted.Skipped
+
+		if err := dbPool.Translations.AddTranslations(translations); err != nil {
+			slog.Error("failed to save translations", "error", err)
+		}
+		report.Translated += len(translations)
+
+		extracted := make([]string, len(latestNews))
+		for i, n := range latestNews {
+			extracted[i] = n.SourceLink
+		}
+		if err := dbPool.Entities.AddNewsEntities(newsEntities); err != nil {
+			slog.Error("failed to save entities", "error", err)
+		} else if err := dbPool.Entities.MarkEntitiesExtracted(extracted); err != nil {
+			slog.Error("failed to mark entities extracted", "error", err)
+		}
+		report.Entities += len(newsEntities)
+
+		clustered, err := clusterNews(latestNews)
+		if err != nil {
+			slog.Error("failed to cluster news", "error", err)
+		}
+		report.Clustered += clustered.clustered
+		report.Clusters += clustered.opened
+
+		if err := corroborateClusters(clustered.touched); err != nil {
+			slog.Error("failed to corroborate news", "error", err)
+		}
+	}
+
+	if RECRAWL_ENABLED {
+		if err := c.recrawl(report); err != nil {
+			slog.Error("failed to re-crawl news", "error", err)
+		}
+	}
+
+	if PRUNE_ENABLED {
+		if _, err := RunPrune(PruneOptions{Datastore: c.datastore, ArchiveTo: ARCHIVE_TO}); err != nil {
+			slog.Error("failed to prune news", "error", err)
+		}
 	}
 
 	return nil
 }
 
+// prepareNews sanitizes the crawled news and canonicalises their links
+func prepareNews(latestNews dto.NewsList) {
+	crawledAt := time.Now()
+	for i := range latestNews {
+		latestNews[i].CrawledAt = crawledAt
+	}
+	latestNews.Sanitize()
+	for i := range latestNews {
+		latestNews[i].SourceLink = urlcanon.Normalize(latestNews[i].SourceLink)
+		latestNews[i].Id = latestNews[i].SourceLink
+		latestNews[i].ContentHash = revision.ContentHash(latestNews[i])
+		latestNews[i].Revision = 1
+	}
+}
+
+// checkSchema refuses to write to a datastore whose schema migrations are
+// not all applied
+func checkSchema(datastore string) error {
+	migrator, err := helpers.GetMigrator(datastore)
+	if err != nil {
+		return err
+	}
+
+	return migrator.CheckCurrent()
+}
+
 // getSources returns a list of sources to scrape
 func getSources() []definition.Source {
 	return []definition.Source{
@@ -71,11 +187,136 @@ func getSources() []definition.Source {
 	}
 }
 
-func GenerateSummary(n dto.News) (ai.ArticleSummary, error) {
-	as, err := ai.GetSummary(n)
+func GenerateSummary(n dto.News, opts ai.Options) (ai.ArticleSummary, error) {
+	as, err := ai.GetSummaryWithOptions(n, opts)
 	if err != nil {
 		return ai.ArticleSummary{}, err
 	}
 
 	return *as, nil
 }
+
+// GenerateVerifiedSummary generates the summary and checks its facts against
+// the story. A summary scoring below MIN_CONSISTENCY_SCORE is regenerated up
+// to MAX_SUMMARY_ATTEMPTS times, bypassing the cache, and the most consistent
+// one is returned. Only a summary reaching MIN_CONSISTENCY_SCORE is cached.
+func GenerateVerifiedSummary(n dto.News, runID string) (ai.ArticleSummary, factcheck.Result, error) {
+	var (
+		best      ai.ArticleSummary
+		bestCheck factcheck.Result
+		lastErr   error
+		generated bool
+	)
+
+	opts := ai.DefaultOptions()
+	opts.RunID = runID
+	opts.DeferCache = true
+	for attempt := 1; attempt <= MAX_SUMMARY_ATTEMPTS; attempt++ {
+		opts.NoCache = attempt > 1
+		as, err := GenerateSummary(n, opts)
+		if errors.Is(err, ai.ErrBudgetExceeded) && !generated {
+			return ai.ArticleSummary{}, factcheck.Result{}, err
+		}
+		if err == nil && strings.TrimSpace(as.Summary) == "" {
+			err = fmt.Errorf("empty summary")
+		}
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		check := factcheck.Verify(n.Headline+"\n"+n.Story, append([]string{as.Summary}, as.KeyPoints...)...)
+		if !generated || check.Score > bestCheck.Score {
+			best, bestCheck, generated = as, check, true
+		}
+		if check.Score >= MIN_CONSISTENCY_SCORE {
+			break
+		}
+
+		slog.Warn("summary has unsupported claims", "headline", n.Headline, "attempt", attempt, "score", check.Score, "claims", check.UnsupportedTexts())
+	}
+
+	if !generated {
+		return ai.ArticleSummary{}, factcheck.Result{}, lastErr
+	}
+	if bestCheck.Score >= MIN_CONSISTENCY_SCORE {
+		ai.CacheSummary(best)
+	}
+
+	return best, bestCheck, nil
+}
+
+// summarize sets the summary and bullet points of the news according to
+// SUMMARY_MODE. In SUMMARY_MODE_FALLBACK the extractive summarizer is used
+// when the AI is not configured, fails or is out of budget, so every article
+// gets a summary. The story is checked for injections even when no summary
+// could be made.
+func (c *crawler) summarize(n *dto.News, report *SyncReport) error {
+	if SUMMARY_MODE != SUMMARY_MODE_EXTRACTIVE {
+		var err error
+		switch {
+		case !ai.IsConfigured():
+			err = fmt.Errorf("no AI provider configured")
+		case c.llmPaused:
+			err = ai.ErrBudgetExceeded
+		default:
+			var (
+				summary ai.ArticleSummary
+				check   factcheck.Result
+			)
+			summary, check, err = GenerateVerifiedSummary(*n, report.RunID)
+			if err == nil {
+				n.Summary = summary.Summary
+				n.BulletPoints = summary.KeyPoints
+				n.MetaKeywords = strings.Join(ai.GroundedCategories(*n, summary.Metadata.Categories), ", ")
+				n.SummaryMethod = dto.SUMMARY_METHOD_LLM
+				n.ConsistencyScore = check.Score
+				n.UnsupportedClaims = check.UnsupportedTexts()
+				n.ModelVerificationStatus = summary.Metadata.VerificationStatus
+
+				report.Summarized++
+				if check.Score < MIN_CONSISTENCY_SCORE {
+					report.Inconsistent++
+				}
+				if quarantine(n, &summary) {
+					report.Quarantined++
+				}
+				return nil
+			}
+			if errors.Is(err, ai.ErrBudgetExceeded) {
+				slog.Warn("pausing AI summary generation", "cause", err)
+				c.llmPaused = true
+			}
+		}
+
+		if SUMMARY_MODE == SUMMARY_MODE_LLM {
+			if quarantine(n, nil) {
+				report.Quarantined++
+			}
+			return err
+		}
+		slog.Warn("falling back to extractive summary", "headline", n.Headline, "cause", err)
+	}
+
+	es := extractive.Summarize(n.Story)
+	if es.Summary == "" {
+		if quarantine(n, nil) {
+			report.Quarantined++
+		}
+		return fmt.Errorf("story is empty")
+	}
+
+	n.Summary = es.Summary
+	n.BulletPoints = es.KeyPoints
+	n.SummaryMethod = dto.SUMMARY_METHOD_EXTRACTIVE
+	n.ConsistencyScore = 1
+	n.UnsupportedClaims = nil
+
+	report.Summarized++
+	report.Extractive++
+	if quarantine(n, nil) {
+		report.Quarantined++
+	}
+
+	return nil
+}
@@ -0,0 +1,54 @@
+package crawler
+
+import (
+	"log/slog"
+	"time"
+
+	"ncrawler/internal/dto"
+	"ncrawler/internal/fingerprint"
+	"ncrawler/pkg/helpers"
+)
+
+// linkDuplicate fingerprints the story and links it to the earlier story it
+// nearly duplicates, looked for among the news synced before it and the
+// stored news. The original story is returned, nil for an original story.
+func linkDuplicate(n *dto.News, earlier []dto.News) (*dto.News, error) {
+	n.Fingerprint = fingerprint.SimHash(n.Story)
+	if n.Fingerprint == 0 {
+		return nil, nil
+	}
+
+	var original *dto.News
+	for i := range earlier {
+		if earlier[i].DuplicateOf == "" && fingerprint.IsNearDuplicate(n.Fingerprint, earlier[i].Fingerprint) {
+			original = &earlier[i]
+			break
+		}
+	}
+	if original == nil {
+		var err error
+		original, err = helpers.GetDbPool().News.FindNearDuplicate(n.SourceLink, n.Fingerprint, time.Now().Add(-fingerprint.WINDOW), fingerprint.MAX_DISTANCE)
+		if err != nil || original == nil {
+			return nil, err
+		}
+	}
+
+	n.DuplicateOf = original.SourceLink
+	n.DuplicateSimilarity = fingerprint.Similarity(n.Fingerprint, original.Fingerprint)
+	slog.Info("near duplicate found", "link", n.SourceLink, "original", original.SourceLink, "similarity", n.DuplicateSimilarity)
+
+	return original, nil
+}
+
+// reuseSummary copies the AI enrichment of the original story to its near duplicate
+func reuseSummary(n *dto.News, original dto.News) {
+	n.Summary = original.Summary
+	n.BulletPoints = original.BulletPoints
+	n.MetaKeywords = original.MetaKeywords
+	n.SummaryMethod = original.SummaryMethod
+	n.ConsistencyScore = original.ConsistencyScore
+	n.UnsupportedClaims = original.UnsupportedClaims
+	n.ModelVerificationStatus = original.ModelVerificationStatus
+	n.Quarantined = original.Quarantined
+	n.QuarantineReasons = original.QuarantineReasons
+}
@@ -0,0 +1,55 @@
+package crawler
+
+import (
+	"log/slog"
+	"strings"
+
+	"ncrawler/internal/dto"
+	"ncrawler/internal/entities"
+	"ncrawler/internal/lang"
+	"ncrawler/pkg/helpers"
+)
+
+// extractEntities returns the entities mentioned in the news. The rules work
+// on English text, so stories in other languages are read from their summary.
+func extractEntities(n dto.News) []dto.NewsEntity {
+	text := n.Headline + "\n" + n.Story
+	if n.Language != "" && n.Language != lang.ENGLISH {
+		text = n.Summary + "\n" + strings.Join(n.BulletPoints, "\n")
+	}
+
+	found := entities.Extract(text)
+	for i := range found {
+		found[i].SourceLink = n.SourceLink
+	}
+
+	return found
+}
+
+// RunEntities extracts the entities of at most limit stored news stories
+// that have none yet
+func RunEntities(limit int) error {
+	dbPool := helpers.GetDbPool()
+
+	newsStories, err := dbPool.Entities.GetNewsWithoutEntities(limit)
+	if err != nil {
+		return err
+	}
+
+	var found []dto.NewsEntity
+	sourceLinks := make([]string, len(newsStories))
+	for i, n := range newsStories {
+		found = append(found, extractEntities(n)...)
+		sourceLinks[i] = n.SourceLink
+	}
+
+	if err := dbPool.Entities.AddNewsEntities(found); err != nil {
+		return err
+	}
+	if err := dbPool.Entities.MarkEntitiesExtracted(sourceLinks); err != nil {
+		return err
+	}
+	slog.Info("entities extracted", "news", len(newsStories), "mentions", len(found))
+
+	return nil
+}
@@ -0,0 +1,23 @@
+package crawler
+
+import (
+	"log/slog"
+
+	"ncrawler/internal/ai"
+	"ncrawler/internal/dto"
+)
+
+// quarantine holds the news for review when its story or summary looks
+// like a prompt injection, it reports whether the news was quarantined
+func quarantine(n *dto.News, as *ai.ArticleSummary) bool {
+	reasons := ai.Inspect(*n, as)
+	if len(reasons) == 0 {
+		return false
+	}
+
+	slog.Warn("news quarantined for review", "headline", n.Headline, "reasons", reasons)
+	n.Quarantined = true
+	n.QuarantineReasons = reasons
+
+	return true
+}
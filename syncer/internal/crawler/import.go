@@ -0,0 +1,179 @@
+package crawler
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"ncrawler/internal/ai"
+	"ncrawler/internal/definition"
+	"ncrawler/internal/dto"
+	"ncrawler/internal/importer"
+	"ncrawler/internal/lang"
+	"ncrawler/pkg/helpers"
+)
+
+// ImportOptions tell where and how the imported stories are stored
+type ImportOptions struct {
+	// Datastore is helpers.DATASTORE_PG or helpers.DATASTORE_SQLITE3
+	Datastore string
+	// Policy merges the stories already stored, like MERGE_POLICY
+	Policy string
+	// Enrich detects the language and extracts the entities of the stories,
+	// and queues an AI batch for the stories without summary on Postgres
+	Enrich bool
+}
+
+// ImportReport is what an import read and stored
+type ImportReport struct {
+	Read int
+	// Invalid counts the stories skipped by the validation
+	Invalid int
+	dto.UpsertResult
+	Entities int
+	Queued   int
+}
+
+// RunImport validates, canonicalises and upserts the stories of the reader
+// IMPORT_BATCH_SIZE at a time. Invalid stories are logged and skipped.
+func RunImport(reader importer.Reader, opts ImportOptions) (ImportReport, error) {
+	var report ImportReport
+
+	if err := checkSchema(opts.Datastore); err != nil {
+		return report, err
+	}
+	store, err := helpers.GetNewsStore(opts.Datastore)
+	if err != nil {
+		return report, err
+	}
+	entityStore, err := helpers.GetEntityStore(opts.Datastore)
+	if err != nil {
+		return report, err
+	}
+	if opts.Enrich && opts.Datastore != helpers.DATASTORE_PG {
+		slog.Warn("summaries are only queued on pg, the imported stories are not summarized")
+	}
+
+	now := time.Now()
+	batch := make([]dto.News, 0, IMPORT_BATCH_SIZE)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		err := importBatch(store, entityStore, batch, opts, &report)
+		batch = batch[:0]
+		return err
+	}
+
+	err = reader.ForEach(func(news dto.News) error {
+		report.Read++
+		importer.Normalize(&news, now)
+		if err := importer.Validate(news); err != nil {
+			slog.Warn("skipping invalid story", "record", report.Read, "link", news.SourceLink, "error", err)
+			report.Invalid++
+			return nil
+		}
+
+		batch = append(batch, news)
+		if len(batch) < IMPORT_BATCH_SIZE {
+			return nil
+		}
+		return flush()
+	})
+	if err != nil {
+		return report, err
+	}
+
+	return report, flush()
+}
+
+// importBatch upserts the stories and enriches them when asked
+func importBatch(store definition.NewsStore, entityStore definition.EntityStore, batch []dto.News, opts ImportOptions, report *ImportReport) error {
+	if opts.Enrich {
+		for i := range batch {
+			if batch[i].Language == "" {
+				batch[i].Language = lang.Detect(batch[i].Headline + "\n" + batch[i].Story)
+			}
+		}
+	}
+
+	upserted, err := store.AddNewsStories(batch, opts.Policy)
+	if err != nil {
+		return err
+	}
+	report.Add(upserted)
+	slog.Info("news data imported", "read", report.Read, "inserted", report.Inserted, "updated", report.Updated, "skipped", report.Skipped)
+
+	if err := importRetractions(batch, opts); err != nil {
+		return err
+	}
+	if !opts.Enrich {
+		return nil
+	}
+
+	var newsEntities []dto.NewsEntity
+	links := make([]string, 0, len(batch))
+	for _, n := range batch {
+		newsEntities = append(newsEntities, extractEntities(n)...)
+		links = append(links, n.SourceLink)
+	}
+	if err := entityStore.AddNewsEntities(newsEntities); err != nil {
+		return err
+	}
+	report.Entities += len(newsEntities)
+
+	if opts.Datastore != helpers.DATASTORE_PG {
+		return nil
+	}
+
+	pending, err := helpers.GetDbPool().News.GetPendingSummariesByLinks(links)
+	if err != nil {
+		return err
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+	b, err := queueBatch(ai.DefaultOptions(), pending)
+	if err != nil {
+		return fmt.Errorf("error queueing summaries: %w", err)
+	}
+	if b != nil {
+		report.Queued += b.RequestCount
+	}
+
+	return nil
+}
+
+// importRetractions flags the retracted stories of the batch on Postgres,
+// the upsert does not carry the flag
+func importRetractions(batch []dto.News, opts ImportOptions) error {
+	if opts.Datastore != helpers.DATASTORE_PG {
+		return nil
+	}
+
+	for _, n := range batch {
+		if !n.Retracted {
+			continue
+		}
+		if _, err := helpers.GetDbPool().News.UpdateRetraction(n.SourceLink, true, n.RetractionReason); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RunSqliteImport imports all the stories of the local sqlite3 database
+// into Postgres, the quarantined and retracted ones with their flags
+func RunSqliteImport(opts ImportOptions) (ImportReport, error) {
+	if err := checkSchema(helpers.DATASTORE_SQLITE3); err != nil {
+		return ImportReport{}, fmt.Errorf("error checking the sqlite3 schema: %w", err)
+	}
+	store, err := helpers.GetBackupStore(helpers.DATASTORE_SQLITE3)
+	if err != nil {
+		return ImportReport{}, err
+	}
+
+	opts.Datastore = helpers.DATASTORE_PG
+	return RunImport(importer.NewStoreReader(store), opts)
+}
@@ -0,0 +1,61 @@
+package crawler
+
+import (
+	"log/slog"
+
+	"ncrawler/internal/dto"
+	"ncrawler/internal/lang"
+	"ncrawler/pkg/helpers"
+)
+
+// syncLocal crawls the news into a local datastore without Postgres, like on
+// a laptop offline. Only the language and entities are detected, the news are
+// summarized, translated and clustered once replicated to Postgres.
+func (c *crawler) syncLocal() error {
+	if err := checkSchema(c.datastore); err != nil {
+		return err
+	}
+	store, err := helpers.GetNewsStore(c.datastore)
+	if err != nil {
+		return err
+	}
+	entityStore, err := helpers.GetEntityStore(c.datastore)
+	if err != nil {
+		return err
+	}
+
+	for _, source := range getSources() {
+		slog.Info("syncing news data", "datastore", c.datastore)
+		latestNews, err := source.GetLatest()
+		if err != nil {
+			return err
+		}
+		slog.Info("news data fetched", "count", len(latestNews))
+
+		prepareNews(latestNews)
+
+		var newsEntities []dto.NewsEntity
+		for i := range latestNews {
+			latestNews[i].Language = lang.Detect(latestNews[i].Headline + "\n" + latestNews[i].Story)
+			newsEntities = append(newsEntities, extractEntities(latestNews[i])...)
+		}
+
+		upserted, err := store.AddNewsStories(latestNews, MERGE_POLICY)
+		if err != nil {
+			return err
+		}
+		slog.Info("news data synced to database", "datastore", c.datastore, "inserted", upserted.Inserted, "updated", upserted.Updated, "skipped", upserted.Skipped)
+
+		if err := entityStore.AddNewsEntities(newsEntities); err != nil {
+			slog.Error("failed to save entities", "error", err)
+		}
+	}
+
+	if PRUNE_ENABLED {
+		if _, err := RunPrune(PruneOptions{Datastore: c.datastore, ArchiveTo: ARCHIVE_TO}); err != nil {
+			slog.Error("failed to prune news", "error", err)
+		}
+	}
+
+	return nil
+}
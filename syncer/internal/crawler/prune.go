@@ -0,0 +1,284 @@
+package crawler
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"ncrawler/internal/definition"
+	"ncrawler/internal/dto"
+	"ncrawler/internal/export"
+	"ncrawler/pkg/helpers"
+)
+
+// PruneOptions are where and how the news past their retention are pruned
+type PruneOptions struct {
+	Datastore string
+	// ArchiveTo is ARCHIVE_TABLE or ARCHIVE_NDJSON
+	ArchiveTo string
+	DryRun    bool
+}
+
+// PruneResult is what the retention rule of a source and category pruned,
+// or would prune on a dry run
+type PruneResult struct {
+	dto.SourceCategory
+	Rule      dto.RetentionRule
+	Stripped  int
+	Archived  int
+	Revisions int
+}
+
+// PruneReport is what a prune run did
+type PruneReport struct {
+	Results []PruneResult
+	// File is the NDJSON archive written, empty when none was
+	File string
+}
+
+// Totals sums the results of all the sources and categories
+func (r PruneReport) Totals() (stripped, archived, revisions int) {
+	for _, result := range r.Results {
+		stripped += result.Stripped
+		archived += result.Archived
+		revisions += result.Revisions
+	}
+
+	return stripped, archived, revisions
+}
+
+// LoadRetentionRules reads the retention rules of RETENTION_FILE, none when
+// the file does not exist
+func LoadRetentionRules() ([]dto.RetentionRule, error) {
+	content, err := os.ReadFile(RETENTION_FILE)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading retention file %s: %w", RETENTION_FILE, err)
+	}
+
+	var rules []dto.RetentionRule
+	if err := json.Unmarshal(content, &rules); err != nil {
+		return nil, fmt.Errorf("error decoding retention file %s: %w", RETENTION_FILE, err)
+	}
+
+	return rules, nil
+}
+
+// matchRetentionRule returns the rule of the source and category, a rule
+// naming the source wins over one naming the category, which wins over a
+// catch-all rule. Among equally specific rules the first one wins.
+func matchRetentionRule(rules []dto.RetentionRule, source, category string) (dto.RetentionRule, bool) {
+	var (
+		best  dto.RetentionRule
+		score = -1
+	)
+	for _, rule := range rules {
+		if rule.Source != "" && !strings.EqualFold(rule.Source, source) {
+			continue
+		}
+		if rule.Category != "" && !strings.EqualFold(rule.Category, category) {
+			continue
+		}
+
+		s := 0
+		if rule.Source != "" {
+			s += 2
+		}
+		if rule.Category != "" {
+			s++
+		}
+		if s > score {
+			best, score = rule, s
+		}
+	}
+
+	return best, score >= 0
+}
+
+// RunPrune applies the retention rules to the news of every source and
+// category: the news older than ArchiveDays are moved to the archive, the
+// story text of the younger ones older than StoryDays is dropped and only the
+// MaxRevisions latest revisions of each story are kept. With DryRun nothing
+// is changed and the report counts what would be.
+func RunPrune(opts PruneOptions) (PruneReport, error) {
+	var report PruneReport
+
+	if opts.ArchiveTo != ARCHIVE_TABLE && opts.ArchiveTo != ARCHIVE_NDJSON {
+		return report, fmt.Errorf("unknown archive %s, use %s or %s", opts.ArchiveTo, ARCHIVE_TABLE, ARCHIVE_NDJSON)
+	}
+	if err := checkSchema(opts.Datastore); err != nil {
+		return report, err
+	}
+
+	rules, err := LoadRetentionRules()
+	if err != nil {
+		return report, err
+	}
+	if len(rules) == 0 {
+		return report, nil
+	}
+
+	store, err := helpers.GetRetentionStore(opts.Datastore)
+	if err != nil {
+		return report, err
+	}
+	groups, err := store.GetSourceCategories()
+	if err != nil {
+		return report, err
+	}
+
+	archive := &ndjsonArchive{}
+	defer func() {
+		if err := archive.Close(); err != nil {
+			slog.Error("failed to close archive", "file", archive.path, "error", err)
+		}
+	}()
+
+	now := time.Now()
+	for _, g := range groups {
+		rule, ok := matchRetentionRule(rules, g.Source, g.Category)
+		if !ok || rule.Keeps() {
+			continue
+		}
+		result := PruneResult{SourceCategory: g, Rule: rule}
+
+		var archiveBefore time.Time
+		if rule.ArchiveDays > 0 {
+			archiveBefore = now.AddDate(0, 0, -rule.ArchiveDays)
+			result.Archived, err = pruneArchive(store, g, archiveBefore, opts, archive)
+			report.File = archive.path
+			if err != nil {
+				return report, err
+			}
+		}
+
+		if rule.StoryDays > 0 {
+			stripBefore := now.AddDate(0, 0, -rule.StoryDays)
+			if stripBefore.After(archiveBefore) {
+				result.Stripped, err = store.StripStories(g.Source, g.Category, archiveBefore, stripBefore, opts.DryRun)
+				if err != nil {
+					return report, err
+				}
+			}
+		}
+
+		if rule.MaxRevisions > 0 {
+			result.Revisions, err = store.PruneRevisions(g.Source, g.Category, rule.MaxRevisions, opts.DryRun)
+			if err != nil {
+				return report, err
+			}
+		}
+
+		report.Results = append(report.Results, result)
+	}
+
+	if !opts.DryRun {
+		stripped, archived, revisions := report.Totals()
+		slog.Info("news data pruned", "datastore", opts.Datastore, "stripped", stripped, "archived", archived, "revisions", revisions)
+	}
+
+	return report, nil
+}
+
+// pruneArchive moves the news of the source and category crawled before the
+// time to the archive page by page. The NDJSON archive is flushed to disk
+// before the news are deleted.
+func pruneArchive(store definition.RetentionStore, g dto.SourceCategory, before time.Time, opts PruneOptions, archive *ndjsonArchive) (int, error) {
+	var (
+		archived  int
+		afterLink string
+	)
+	for {
+		page, err := store.GetNewsCrawledBefore(g.Source, g.Category, before, afterLink, PRUNE_PAGE_SIZE)
+		if err != nil {
+			return archived, err
+		}
+		if len(page) == 0 {
+			return archived, nil
+		}
+		afterLink = page[len(page)-1].SourceLink
+
+		if opts.DryRun {
+			archived += len(page)
+			continue
+		}
+
+		var moved int
+		if opts.ArchiveTo == ARCHIVE_TABLE {
+			moved, err = store.ArchiveNews(page)
+		} else {
+			if err = archive.Write(page); err != nil {
+				return archived, err
+			}
+			links := make([]string, len(page))
+			for i, n := range page {
+				links[i] = n.SourceLink
+			}
+			moved, err = store.DeleteNews(links)
+		}
+		archived += moved
+		if err != nil {
+			return archived, err
+		}
+	}
+}
+
+// ndjsonArchive is a gzip compressed NDJSON file of ARCHIVE_DIR created on
+// the first write, the news are written like the export does so they can be
+// imported back
+type ndjsonArchive struct {
+	path string
+	file *os.File
+	gz   *gzip.Writer
+	w    export.Writer
+}
+
+// Write appends the news stories and flushes them to disk
+func (a *ndjsonArchive) Write(newsStories []dto.News) error {
+	if a.file == nil {
+		if err := os.MkdirAll(ARCHIVE_DIR, 0o755); err != nil {
+			return fmt.Errorf("error creating archive directory: %w", err)
+		}
+		path := filepath.Join(ARCHIVE_DIR, "news-"+time.Now().UTC().Format("20060102T150405Z")+".ndjson.gz")
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if err != nil {
+			return fmt.Errorf("error creating archive: %w", err)
+		}
+		a.path, a.file, a.gz = path, file, gzip.NewWriter(file)
+		if a.w, err = export.NewWriter(export.FORMAT_NDJSON, a.gz); err != nil {
+			return err
+		}
+	}
+
+	if err := a.w.Write(newsStories); err != nil {
+		return fmt.Errorf("error writing archive: %w", err)
+	}
+	if err := a.gz.Flush(); err != nil {
+		return fmt.Errorf("error writing archive: %w", err)
+	}
+	if err := a.file.Sync(); err != nil {
+		return fmt.Errorf("error syncing archive: %w", err)
+	}
+
+	return nil
+}
+
+// Close ends the archive, nothing is done when it was never written
+func (a *ndjsonArchive) Close() error {
+	if a.file == nil {
+		return nil
+	}
+
+	err := errors.Join(a.w.Close(), a.gz.Close(), a.file.Close())
+	a.file = nil
+
+	return err
+}
@@ -0,0 +1,128 @@
+package crawler
+
+import (
+	"log/slog"
+	"time"
+
+	"ncrawler/internal/definition"
+	"ncrawler/internal/dto"
+	"ncrawler/internal/fingerprint"
+	"ncrawler/internal/lang"
+	"ncrawler/internal/revision"
+	"ncrawler/internal/urlcanon"
+	"ncrawler/pkg/helpers"
+)
+
+// recrawl fetches the articles younger than RECRAWL_WINDOW again and records
+// a revision of those whose headline or story changed. An article is checked
+// at most every RECRAWL_INTERVAL.
+func (c *crawler) recrawl(report *SyncReport) error {
+	dbPool := helpers.GetDbPool()
+
+	now := time.Now()
+	candidates, err := dbPool.News.GetRecrawlCandidates(now.Add(-RECRAWL_WINDOW), now.Add(-RECRAWL_INTERVAL), RECRAWL_LIMIT)
+	if err != nil {
+		return err
+	}
+
+	bySource := map[string][]dto.News{}
+	for _, n := range candidates {
+		bySource[n.SourceName] = append(bySource[n.SourceName], n)
+	}
+
+	for _, source := range getSources() {
+		stored := bySource[source.GetName()]
+		if len(stored) == 0 {
+			continue
+		}
+
+		revised, err := c.recrawlSource(source, stored, report)
+		if err != nil {
+			slog.Error("failed to re-crawl news", "source", source.GetName(), "error", err)
+			continue
+		}
+		slog.Info("news re-crawled", "source", source.GetName(), "checked", len(stored), "revised", revised)
+	}
+
+	return nil
+}
+
+// recrawlSource fetches the stored articles of the source again and saves the
+// changed ones as a new revision, summarizing them again when
+// RESUMMARIZE_REVISIONS is set
+func (c *crawler) recrawlSource(source definition.Source, stored []dto.News, report *SyncReport) (int, error) {
+	dbPool := helpers.GetDbPool()
+
+	links := make([]string, 0, len(stored))
+	byLink := make(map[string]dto.News, len(stored))
+	for _, n := range stored {
+		links = append(links, n.SourceLink)
+		byLink[n.SourceLink] = n
+	}
+
+	fetched, err := source.GetNews(links)
+	if err != nil {
+		return 0, err
+	}
+	if err := dbPool.News.MarkChecked(links); err != nil {
+		return 0, err
+	}
+	fetched.Sanitize()
+
+	revised := 0
+	for _, n := range fetched {
+		old, ok := byLink[urlcanon.Normalize(n.SourceLink)]
+		if !ok || n.Story == "" {
+			continue
+		}
+		// the publisher reports no update since the stored version
+		if !n.ModifiedAt.IsZero() && n.ModifiedAt.Equal(old.ModifiedAt) {
+			continue
+		}
+
+		if old.ContentHash == "" {
+			old.ContentHash = revision.ContentHash(old)
+		}
+		n.ContentHash = revision.ContentHash(n)
+		if n.ContentHash == old.ContentHash {
+			continue
+		}
+
+		n.Id = old.Id
+		n.SourceLink = old.SourceLink
+		n.SourceName = old.SourceName
+		n.Revision = max(old.Revision, 1) + 1
+		n.Fingerprint = fingerprint.SimHash(n.Story)
+
+		if err := dbPool.Revisions.AddRevision(old, n, revision.Diff(old, n)); err != nil {
+			slog.Error("failed to save revision", "link", n.SourceLink, "error", err)
+			continue
+		}
+		slog.Info("news revised", "headline", n.Headline, "revision", n.Revision)
+		revised++
+		report.Revised++
+
+		if !RESUMMARIZE_REVISIONS {
+			continue
+		}
+		n.Language = lang.Detect(n.Headline + "\n" + n.Story)
+		if err := c.summarize(&n, report); err != nil {
+			slog.Error("failed to generate summary", "error", err)
+			report.Failed++
+			continue
+		}
+		if err := dbPool.News.UpdateSummary(n); err != nil {
+			slog.Error("failed to update summary", "link", n.SourceLink, "error", err)
+		}
+	}
+
+	return revised, nil
+}
+
+// RunRecrawl checks the recent articles of every source for changes
+func RunRecrawl() error {
+	report := newSyncReport()
+	defer report.Finish()
+
+	return (&crawler{}).recrawl(report)
+}
@@ -0,0 +1,242 @@
+package crawler
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"ncrawler/internal/definition"
+	"ncrawler/internal/dto"
+	"ncrawler/internal/fingerprint"
+	"ncrawler/internal/importer"
+	"ncrawler/internal/revision"
+	"ncrawler/pkg/helpers"
+)
+
+// ReplicationReport is what a replication read and stored
+type ReplicationReport struct {
+	Read    int
+	Invalid int
+	dto.UpsertResult
+	// Conflicts counts the stories changed in both datastores, Kept the
+	// conflicts won by the target which only got its empty fields filled
+	Conflicts int
+	Kept      int
+	// Flagged counts the stories whose quarantine or retraction changed,
+	// Entities the entity mentions copied along
+	Flagged  int
+	Entities int
+}
+
+// RunReplicate copies the stories changed in one datastore since the last
+// run into the other, from sqlite3 to Postgres or back. The checkpoint of
+// each direction is kept in the local sqlite3 database and saved after every
+// page, so an interrupted replication resumes where it stopped. A story
+// changed in both datastores is resolved by conflict, CONFLICT_NEWEST,
+// CONFLICT_SOURCE or CONFLICT_TARGET, and the losing version still fills the
+// empty fields of the winner so nothing is lost. With reset the replication
+// starts over from the first story. The entities of the stored stories are
+// copied along, and the quarantine and retraction decided by Postgres are
+// copied to sqlite3.
+func RunReplicate(from, to, conflict string, reset bool) (ReplicationReport, error) {
+	var report ReplicationReport
+
+	if from == to || (from != helpers.DATASTORE_SQLITE3 && to != helpers.DATASTORE_SQLITE3) {
+		return report, fmt.Errorf("replication runs between %s and %s", helpers.DATASTORE_SQLITE3, helpers.DATASTORE_PG)
+	}
+	if conflict != CONFLICT_NEWEST && conflict != CONFLICT_SOURCE && conflict != CONFLICT_TARGET {
+		return report, fmt.Errorf("unknown conflict resolution %s", conflict)
+	}
+	for _, datastore := range []string{from, to} {
+		if err := checkSchema(datastore); err != nil {
+			return report, fmt.Errorf("error checking the %s schema: %w", datastore, err)
+		}
+	}
+
+	source, err := helpers.GetNewsStore(from)
+	if err != nil {
+		return report, err
+	}
+	target, err := helpers.GetNewsStore(to)
+	if err != nil {
+		return report, err
+	}
+	sourceEntities, err := helpers.GetBackupStore(from)
+	if err != nil {
+		return report, err
+	}
+	targetEntities, err := helpers.GetEntityStore(to)
+	if err != nil {
+		return report, err
+	}
+
+	checkpoints := helpers.GetSqliteDb().Checkpoints
+	name := from + ">" + to
+	if reset {
+		if err := checkpoints.DeleteCheckpoint(name); err != nil {
+			return report, err
+		}
+	}
+	cp, err := checkpoints.GetCheckpoint(name)
+	if err != nil {
+		return report, err
+	}
+	if !cp.ReplicatedAt.IsZero() {
+		slog.Info("resuming replication", "from", from, "to", to, "after", cp.UpdatedAt, "last_run", cp.ReplicatedAt)
+	}
+
+	for {
+		changed, err := source.GetChangedNews(cp.UpdatedAt, cp.SourceLink, REPLICATE_PAGE_SIZE)
+		if err != nil {
+			return report, err
+		}
+		if len(changed) == 0 {
+			return report, nil
+		}
+
+		stored, err := replicatePage(changed, target, to, conflict, &report)
+		if err != nil {
+			return report, err
+		}
+		newsEntities, err := sourceEntities.GetNewsEntitiesByLinks(stored)
+		if err != nil {
+			return report, err
+		}
+		if err := targetEntities.AddNewsEntities(newsEntities); err != nil {
+			return report, err
+		}
+		report.Entities += len(newsEntities)
+
+		last := changed[len(changed)-1]
+		cp.UpdatedAt, cp.SourceLink, cp.ReplicatedAt = last.UpdatedAt, last.SourceLink, time.Now()
+		if err := checkpoints.SaveCheckpoint(cp); err != nil {
+			return report, err
+		}
+		slog.Info("news data replicated", "from", from, "to", to, "read", report.Read,
+			"inserted", report.Inserted, "updated", report.Updated, "conflicts", report.Conflicts)
+	}
+}
+
+// replicatePage stores a page of changed stories into the target datastore
+// to and returns the links of the stories the source version was stored of.
+// A replaced story whose text changed gets the next revision, which is
+// recorded in Postgres like a recrawled one.
+func replicatePage(changed []dto.News, target definition.NewsStore, to, conflict string, report *ReplicationReport) ([]string, error) {
+	now := time.Now()
+	valid := make([]dto.News, 0, len(changed))
+	links := make([]string, 0, len(changed))
+	for _, n := range changed {
+		report.Read++
+		importer.Normalize(&n, now)
+		if err := importer.Validate(n); err != nil {
+			slog.Warn("skipping invalid story", "link", n.SourceLink, "error", err)
+			report.Invalid++
+			continue
+		}
+		valid = append(valid, n)
+		links = append(links, n.SourceLink)
+	}
+
+	stored, err := target.GetNewsByLinks(links)
+	if err != nil {
+		return nil, err
+	}
+	storedByLink := make(map[string]dto.News, len(stored))
+	for _, n := range stored {
+		// stories stored before the revisions were tracked have no hash
+		importer.Normalize(&n, now)
+		storedByLink[n.SourceLink] = n
+	}
+
+	var replace, fill, revised []dto.News
+	var storedLinks []string
+	for _, n := range valid {
+		old, ok := storedByLink[n.SourceLink]
+		switch {
+		case !ok:
+			replace = append(replace, n)
+		case sameVersion(n, old):
+			report.Skipped++
+			continue
+		case !isConflict(n, old):
+			fill = append(fill, n)
+		case resolveConflict(n, old, conflict):
+			report.Conflicts++
+			if n.ContentHash != old.ContentHash {
+				n.Revision = max(n.Revision, old.Revision+1)
+				n.Fingerprint = fingerprint.SimHash(n.Story)
+				revised = append(revised, n)
+			}
+			replace = append(replace, n)
+		default:
+			report.Conflicts++
+			report.Kept++
+			fill = append(fill, n)
+			continue
+		}
+		storedLinks = append(storedLinks, n.SourceLink)
+	}
+
+	replaced, err := target.AddNewsStories(replace, dto.MERGE_REPLACE)
+	if err != nil {
+		return nil, err
+	}
+	report.Add(replaced)
+
+	if to == helpers.DATASTORE_PG {
+		revisions := helpers.GetDbPool().Revisions
+		for _, n := range revised {
+			old := storedByLink[n.SourceLink]
+			if err := revisions.RecordRevision(old, n, revision.Diff(old, n)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	filled, err := target.AddNewsStories(fill, dto.MERGE_FILL_EMPTY)
+	if err != nil {
+		return nil, err
+	}
+	report.Add(filled)
+
+	if to == helpers.DATASTORE_SQLITE3 {
+		flagged, err := helpers.GetSqliteDb().News.ReplicateFlags(valid)
+		if err != nil {
+			return nil, err
+		}
+		report.Flagged += flagged
+	}
+
+	return storedLinks, nil
+}
+
+// sameVersion tells whether both stories have the same text, revision,
+// summary and flags, like a story replicated back to the datastore it came from
+func sameVersion(n, old dto.News) bool {
+	return n.ContentHash == old.ContentHash && n.Revision == old.Revision &&
+		n.Summary == old.Summary && n.Language == old.Language &&
+		n.Quarantined == old.Quarantined && n.Retracted == old.Retracted
+}
+
+// isConflict tells whether both stories changed the text, revision or
+// summary, otherwise one only adds fields missing from the other
+func isConflict(n, old dto.News) bool {
+	return n.ContentHash != old.ContentHash || n.Revision != old.Revision ||
+		(n.Summary != "" && old.Summary != "" && n.Summary != old.Summary)
+}
+
+// resolveConflict tells whether the incoming story replaces the stored one
+func resolveConflict(n, old dto.News, conflict string) bool {
+	switch conflict {
+	case CONFLICT_SOURCE:
+		return true
+	case CONFLICT_TARGET:
+		return false
+	}
+
+	if n.Revision != old.Revision {
+		return n.Revision > old.Revision
+	}
+
+	return n.UpdatedAt.After(old.UpdatedAt)
+}
@@ -0,0 +1,120 @@
+package crawler
+
+import (
+	"log/slog"
+	"time"
+
+	"ncrawler/internal/ai"
+	"ncrawler/internal/dto"
+	"ncrawler/pkg/helpers"
+)
+
+// SyncReport collects the counters of a sync run
+type SyncReport struct {
+	RunID        string
+	StartedAt    time.Time
+	Fetched      int
+	Summarized   int
+	Extractive   int
+	Failed       int
+	Inconsistent int
+	Inserted     int
+	Updated      int
+	Skipped      int
+	Translated   int
+	Quarantined  int
+	Entities     int
+	Clustered    int
+	Clusters     int
+	Duplicates   int
+	Revised      int
+
+	cacheHits        int64
+	cacheMisses      int64
+	promptTokens     int64
+	completionTokens int64
+	costUSD          float64
+}
+
+func newSyncReport() *SyncReport {
+	hits, misses := ai.CacheStats()
+	prompt, completion, cost := ai.UsageStats()
+	return &SyncReport{
+		RunID:            helpers.GetRandomID(),
+		StartedAt:        time.Now(),
+		cacheHits:        hits,
+		cacheMisses:      misses,
+		promptTokens:     prompt,
+		completionTokens: completion,
+		costUSD:          cost,
+	}
+}
+
+// CacheHitRate returns the share of summaries served from the cache during the run
+func (r *SyncReport) CacheHitRate() (hits, misses int64, rate float64) {
+	h, m := ai.CacheStats()
+	hits, misses = h-r.cacheHits, m-r.cacheMisses
+	if hits+misses > 0 {
+		rate = float64(hits) / float64(hits+misses)
+	}
+
+	return hits, misses, rate
+}
+
+// Usage returns the tokens used and the money spent on AI during the run
+func (r *SyncReport) Usage() (prompt, completion int64, costUSD float64) {
+	p, c, cost := ai.UsageStats()
+	return p - r.promptTokens, c - r.completionTokens, cost - r.costUSD
+}
+
+// Finish logs the report and records the run in the database
+func (r *SyncReport) Finish() {
+	hits, misses, rate := r.CacheHitRate()
+	prompt, completion, cost := r.Usage()
+	slog.Info("sync report",
+		"run_id", r.RunID,
+		"duration", time.Since(r.StartedAt).Round(time.Second).String(),
+		"fetched", r.Fetched,
+		"summarized", r.Summarized,
+		"extractive", r.Extractive,
+		"failed", r.Failed,
+		"inconsistent", r.Inconsistent,
+		"inserted", r.Inserted,
+		"updated", r.Updated,
+		"skipped", r.Skipped,
+		"translated", r.Translated,
+		"quarantined", r.Quarantined,
+		"entities", r.Entities,
+		"clustered", r.Clustered,
+		"clusters_opened", r.Clusters,
+		"duplicates", r.Duplicates,
+		"revised", r.Revised,
+		"cache_hits", hits,
+		"cache_misses", misses,
+		"cache_hit_rate", rate,
+		"prompt_tokens", prompt,
+		"completion_tokens", completion,
+		"cost_usd", cost,
+	)
+
+	err := helpers.GetDbPool().SyncRuns.AddSyncRun(dto.SyncRun{
+		Id:               r.RunID,
+		StartedAt:        r.StartedAt,
+		FinishedAt:       time.Now(),
+		Fetched:          r.Fetched,
+		Summarized:       r.Summarized,
+		Extractive:       r.Extractive,
+		Failed:           r.Failed,
+		Inconsistent:     r.Inconsistent,
+		Inserted:         r.Inserted,
+		Quarantined:      r.Quarantined,
+		CacheHits:        hits,
+		CacheMisses:      misses,
+		PromptTokens:     prompt,
+		CompletionTokens: completion,
+		CostUSD:          cost,
+	})
+	if err != nil {
+		slog.Error("failed to record sync run", "error", err)
+	}
+}
@@ -0,0 +1,81 @@
+package crawler
+
+import (
+	"log/slog"
+	"time"
+
+	"ncrawler/internal/dto"
+	"ncrawler/internal/retraction"
+	"ncrawler/pkg/helpers"
+)
+
+// RetractionChange is an article found retracted by its publisher, or
+// restored after being retracted
+type RetractionChange struct {
+	News      dto.News
+	Retracted bool
+	Reason    string
+}
+
+// RetractionReport is what a retraction check found
+type RetractionReport struct {
+	Checked int
+	// Inconclusive counts the links that could not be checked, like on a
+	// timeout or a 503
+	Inconclusive int
+	Changes      []RetractionChange
+}
+
+// RunRetractionCheck checks at most limit articles younger than
+// RETRACTION_WINDOW and not checked within RETRACTION_INTERVAL for
+// retraction, and flags or restores them
+func RunRetractionCheck(limit int) (RetractionReport, error) {
+	dbPool := helpers.GetDbPool()
+
+	now := time.Now()
+	candidates, err := dbPool.News.GetRetractionCandidates(now.Add(-RETRACTION_WINDOW), now.Add(-RETRACTION_INTERVAL), limit)
+	if err != nil {
+		return RetractionReport{}, err
+	}
+
+	var report RetractionReport
+	checked := make([]string, 0, len(candidates))
+	for i, n := range candidates {
+		if i > 0 {
+			time.Sleep(RETRACTION_DELAY)
+		}
+
+		result, err := retraction.Check(n.SourceLink)
+		checked = append(checked, n.SourceLink)
+		report.Checked++
+		if err != nil {
+			slog.Warn("retraction check inconclusive", "link", n.SourceLink, "error", err)
+			report.Inconclusive++
+			continue
+		}
+
+		changed, err := dbPool.News.UpdateRetraction(n.SourceLink, result.Retracted, result.Reason)
+		if err != nil {
+			slog.Error("failed to update retraction", "link", n.SourceLink, "error", err)
+			continue
+		}
+		if !changed {
+			continue
+		}
+
+		if result.Retracted {
+			slog.Info("news retracted", "headline", n.Headline, "reason", result.Reason, "final_url", result.FinalURL)
+		} else {
+			slog.Info("news restored", "headline", n.Headline)
+		}
+		report.Changes = append(report.Changes, RetractionChange{News: n, Retracted: result.Retracted, Reason: result.Reason})
+	}
+
+	if len(checked) > 0 {
+		if err := dbPool.News.MarkRetractionChecked(checked); err != nil {
+			return report, err
+		}
+	}
+
+	return report, nil
+}
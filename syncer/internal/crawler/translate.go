@@ -0,0 +1,95 @@
+package crawler
+
+import (
+	"errors"
+	"log/slog"
+
+	"ncrawler/internal/ai"
+	"ncrawler/internal/dto"
+	"ncrawler/internal/lang"
+	"ncrawler/pkg/helpers"
+)
+
+// translationTargets returns the languages the news is translated into
+func translationTargets(language string) []string {
+	switch {
+	case language == lang.ENGLISH && TRANSLATE_TO_BANGLA:
+		return []string{lang.BANGLA}
+	case language != lang.ENGLISH && language != lang.UNDEFINED && TRANSLATE_TO_ENGLISH:
+		return []string{lang.ENGLISH}
+	}
+
+	return nil
+}
+
+// translate translates the news into its target languages with the AI,
+// failures are logged and skipped as the original text is always kept
+func (c *crawler) translate(n dto.News, runID string) []dto.Translation {
+	targets := translationTargets(n.Language)
+	if len(targets) == 0 || !ai.IsConfigured() || c.llmPaused {
+		return nil
+	}
+
+	opts := ai.DefaultOptions()
+	opts.RunID = runID
+
+	var translations []dto.Translation
+	for _, target := range targets {
+		slog.Info("translating", "headline", n.Headline, "from", n.Language, "to", target)
+		t, err := ai.Translate(n, target, opts)
+		if errors.Is(err, ai.ErrBudgetExceeded) {
+			slog.Warn("pausing AI translation", "cause", err)
+			c.llmPaused = true
+			break
+		}
+		if err != nil {
+			slog.Error("failed to translate", "headline", n.Headline, "error", err)
+			continue
+		}
+		translations = append(translations, *t)
+	}
+
+	return translations
+}
+
+// RunTranslate translates stored news that lack a translation into the
+// target language, detecting the language of rows stored without one
+func RunTranslate(target string, limit int) error {
+	dbPool := helpers.GetDbPool()
+
+	newsStories, err := dbPool.News.GetUntranslatedNews(target, limit)
+	if err != nil {
+		return err
+	}
+
+	var translated int
+	for _, n := range newsStories {
+		if n.Language == "" {
+			n.Language = lang.Detect(n.Headline + "\n" + n.Story)
+			if err := dbPool.News.UpdateLanguage(n.SourceLink, n.Language); err != nil {
+				return err
+			}
+		}
+		if n.Language == target || n.Language == lang.UNDEFINED {
+			continue
+		}
+
+		t, err := ai.Translate(n, target, ai.DefaultOptions())
+		if errors.Is(err, ai.ErrBudgetExceeded) {
+			return err
+		}
+		if err != nil {
+			slog.Error("failed to translate", "headline", n.Headline, "error", err)
+			continue
+		}
+
+		if err := dbPool.Translations.AddTranslations([]dto.Translation{*t}); err != nil {
+			return err
+		}
+		translated++
+	}
+
+	slog.Info("news translated", "language", target, "translated", translated, "checked", len(newsStories))
+
+	return nil
+}
@@ -0,0 +1,114 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+
+	"ncrawler/internal/dto"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// closedBatchStatuses are the statuses of batches whose articles may be
+// submitted again when they are still without a summary
+var closedBatchStatuses = []string{"applied", "failed", "expired", "cancelled"}
+
+type aiBatchStore struct {
+	dbPool *pgxpool.Pool
+}
+
+// AddBatch records a prepared batch with the ids of the news it summarizes
+func (s *aiBatchStore) AddBatch(b dto.AIBatch, newsIDs []string) error {
+	return WrapInTx(context.Background(), s.dbPool, func(tx pgx.Tx) error {
+		query := `
+			INSERT INTO ai_batches (
+				id,
+				file_path,
+				model,
+				prompt_version,
+				status,
+				request_count
+			) VALUES ($1, $2, $3, $4, $5, $6)`
+
+		_, err := tx.Exec(context.Background(), query, b.Id, b.FilePath, b.Model, b.PromptVersion, b.Status, b.RequestCount)
+		if err != nil {
+			return fmt.Errorf("error inserting batch: %w", err)
+		}
+
+		_, err = tx.Exec(context.Background(), `
+			INSERT INTO ai_batch_items (batch_id, news_id)
+			SELECT $1, unnest($2::bigint[])`, b.Id, newsIDs)
+		if err != nil {
+			return fmt.Errorf("error inserting batch items: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// UpdateBatch saves the provider ids and status of the batch
+func (s *aiBatchStore) UpdateBatch(b dto.AIBatch) error {
+	query := `
+		UPDATE ai_batches
+		SET
+			provider_batch_id = $2,
+			input_file_id = $3,
+			output_file_id = $4,
+			error_file_id = $5,
+			status = $6,
+			updated_at = now()
+		WHERE id = $1`
+
+	_, err := s.dbPool.Exec(context.Background(), query,
+		b.Id, b.ProviderBatchID, b.InputFileID, b.OutputFileID, b.ErrorFileID, b.Status)
+	if err != nil {
+		return fmt.Errorf("error updating batch %s: %w", b.Id, err)
+	}
+
+	return nil
+}
+
+// GetOpenBatches returns the batches that are not applied or given up yet
+func (s *aiBatchStore) GetOpenBatches() ([]dto.AIBatch, error) {
+	query := `
+		SELECT
+			id,
+			COALESCE(provider_batch_id, ''),
+			COALESCE(input_file_id, ''),
+			COALESCE(output_file_id, ''),
+			COALESCE(error_file_id, ''),
+			file_path,
+			model,
+			prompt_version,
+			status,
+			request_count,
+			created_at,
+			updated_at
+		FROM ai_batches
+		WHERE status <> ALL($1)
+		ORDER BY created_at`
+
+	rows, err := s.dbPool.Query(context.Background(), query, closedBatchStatuses)
+	if err != nil {
+		return nil, fmt.Errorf("error querying open batches: %w", err)
+	}
+	defer rows.Close()
+
+	var batches []dto.AIBatch
+	for rows.Next() {
+		var b dto.AIBatch
+		err := rows.Scan(&b.Id, &b.ProviderBatchID, &b.InputFileID, &b.OutputFileID, &b.ErrorFileID,
+			&b.FilePath, &b.Model, &b.PromptVersion, &b.Status, &b.RequestCount, &b.CreatedAt, &b.UpdatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning row: %w", err)
+		}
+		batches = append(batches, b)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return batches, nil
+}
@@ -0,0 +1,89 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"ncrawler/internal/dto"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type aiUsageStore struct {
+	dbPool *pgxpool.Pool
+}
+
+// AddUsage records the usage of one AI request, the usage of a batch request
+// already recorded for the batch is left alone and false is returned
+func (s *aiUsageStore) AddUsage(u dto.AIUsage) (bool, error) {
+	query := `
+		INSERT INTO ai_usage (
+			run_id,
+			source_name,
+			source_link,
+			model,
+			prompt_tokens,
+			completion_tokens,
+			cost_usd,
+			batch_id
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, ''))
+		ON CONFLICT (batch_id, source_link) WHERE batch_id IS NOT NULL DO NOTHING`
+
+	tag, err := s.dbPool.Exec(context.Background(), query,
+		u.RunID, u.SourceName, u.SourceLink, u.Model, u.PromptTokens, u.CompletionTokens, u.CostUSD, u.BatchID)
+	if err != nil {
+		return false, fmt.Errorf("error recording ai usage: %w", err)
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+// GetSpend returns the total cost of the AI requests made since the given time
+func (s *aiUsageStore) GetSpend(since time.Time) (float64, error) {
+	var spend float64
+	err := s.dbPool.QueryRow(context.Background(),
+		`SELECT COALESCE(SUM(cost_usd), 0) FROM ai_usage WHERE created_at >= $1`, since).Scan(&spend)
+	if err != nil {
+		return 0, fmt.Errorf("error querying ai spend: %w", err)
+	}
+
+	return spend, nil
+}
+
+// GetUsageSummary returns the usage since the given time grouped by UTC day and source
+func (s *aiUsageStore) GetUsageSummary(since time.Time) ([]dto.AIUsageSummary, error) {
+	query := `
+		SELECT
+			date_trunc('day', created_at AT TIME ZONE 'UTC'),
+			COALESCE(source_name, ''),
+			COUNT(*),
+			SUM(prompt_tokens),
+			SUM(completion_tokens),
+			SUM(cost_usd)
+		FROM ai_usage
+		WHERE created_at >= $1
+		GROUP BY 1, 2
+		ORDER BY 1 DESC, 2`
+
+	rows, err := s.dbPool.Query(context.Background(), query, since)
+	if err != nil {
+		return nil, fmt.Errorf("error querying ai usage: %w", err)
+	}
+	defer rows.Close()
+
+	var summaries []dto.AIUsageSummary
+	for rows.Next() {
+		var us dto.AIUsageSummary
+		if err := rows.Scan(&us.Day, &us.SourceName, &us.Requests, &us.PromptTokens, &us.CompletionTokens, &us.CostUSD); err != nil {
+			return nil, fmt.Errorf("error scanning row: %w", err)
+		}
+		summaries = append(summaries, us)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return summaries, nil
+}
@@ -0,0 +1,581 @@
+package pg
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"ncrawler/internal/dto"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// backupStore reads and restores the tables of a backup
+type backupStore struct {
+	dbPool *pgxpool.Pool
+}
+
+// CountRows counts the rows of the table
+func (s *backupStore) CountRows(table string) (int, error) {
+	var count int
+	err := s.dbPool.QueryRow(context.Background(), `SELECT count(*) FROM `+pgx.Identifier{table}.Sanitize()).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("error counting rows of %s: %w", table, err)
+	}
+
+	return count, nil
+}
+
+// GetNewsAfter retrieves the news stories after the source link in source
+// link order, quarantined and retracted ones too
+func (s *backupStore) GetNewsAfter(afterLink string, limit int) ([]dto.News, error) {
+	query := `
+		SELECT ` + newsSelectColumns + `
+		FROM news
+		WHERE source_link > $1
+		ORDER BY source_link
+		LIMIT $2`
+
+	rows, err := s.dbPool.Query(context.Background(), query, afterLink, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error querying news: %w", err)
+	}
+
+	return scanNews(rows)
+}
+
+// GetNewsEntitiesByLinks retrieves the entities mentioned in the news stories
+func (s *backupStore) GetNewsEntitiesByLinks(sourceLinks []string) ([]dto.NewsEntity, error) {
+	query := `
+		SELECT ne.source_link, e.name, e.type, COALESCE(e.aliases, '{}'), ne.mentions
+		FROM news_entities ne
+		JOIN entities e ON e.id = ne.entity_id
+		WHERE ne.source_link = ANY($1)
+		ORDER BY ne.source_link, e.name, e.type`
+
+	rows, err := s.dbPool.Query(context.Background(), query, sourceLinks)
+	if err != nil {
+		return nil, fmt.Errorf("error querying news entities: %w", err)
+	}
+	defer rows.Close()
+
+	var newsEntities []dto.NewsEntity
+	for rows.Next() {
+		var ne dto.NewsEntity
+		if err := rows.Scan(&ne.SourceLink, &ne.Name, &ne.Type, &ne.Aliases, &ne.Mentions); err != nil {
+			return nil, fmt.Errorf("error scanning row: %w", err)
+		}
+		newsEntities = append(newsEntities, ne)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return newsEntities, nil
+}
+
+// GetRevisionsByLinks retrieves the recorded versions of the news stories
+func (s *backupStore) GetRevisionsByLinks(sourceLinks []string) ([]dto.Revision, error) {
+	query := `
+		SELECT
+			source_link,
+			revision,
+			headline,
+			story,
+			COALESCE(content_hash, ''),
+			COALESCE(diff, ''),
+			COALESCE(modified_at, '0001-01-01 00:00:00+00'),
+			created_at
+		FROM article_revisions
+		WHERE source_link = ANY($1)
+		ORDER BY source_link, revision`
+
+	rows, err := s.dbPool.Query(context.Background(), query, sourceLinks)
+	if err != nil {
+		return nil, fmt.Errorf("error querying revisions: %w", err)
+	}
+	defer rows.Close()
+
+	var revisions []dto.Revision
+	for rows.Next() {
+		var r dto.Revision
+		if err := rows.Scan(&r.SourceLink, &r.Revision, &r.Headline, &r.Story, &r.ContentHash, &r.Diff, &r.ModifiedAt, &r.CreatedAt); err != nil {
+			return nil, fmt.Errorf("error scanning row: %w", err)
+		}
+		revisions = append(revisions, r)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return revisions, nil
+}
+
+// GetTranslationsByLinks retrieves the translations of the news stories
+func (s *backupStore) GetTranslationsByLinks(sourceLinks []string) ([]dto.Translation, error) {
+	query := `
+		SELECT source_link, language, headline, COALESCE(summary, ''), COALESCE(bullet_points, '{}'), COALESCE(model, '')
+		FROM news_translations
+		WHERE source_link = ANY($1)
+		ORDER BY source_link, language`
+
+	rows, err := s.dbPool.Query(context.Background(), query, sourceLinks)
+	if err != nil {
+		return nil, fmt.Errorf("error querying translations: %w", err)
+	}
+	defer rows.Close()
+
+	var translations []dto.Translation
+	for rows.Next() {
+		var t dto.Translation
+		if err := rows.Scan(&t.SourceLink, &t.Language, &t.Headline, &t.Summary, &t.BulletPoints, &t.Model); err != nil {
+			return nil, fmt.Errorf("error scanning row: %w", err)
+		}
+		translations = append(translations, t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return translations, nil
+}
+
+// GetArchivedNewsAfter retrieves the archived news stories after the source
+// link in source link order
+func (s *backupStore) GetArchivedNewsAfter(afterLink string, limit int) ([]dto.ArchivedNews, error) {
+	query := `
+		SELECT news, archived_at
+		FROM news_archive
+		WHERE source_link > $1
+		ORDER BY source_link
+		LIMIT $2`
+
+	rows, err := s.dbPool.Query(context.Background(), query, afterLink, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error querying archived news: %w", err)
+	}
+	defer rows.Close()
+
+	var archived []dto.ArchivedNews
+	for rows.Next() {
+		var (
+			a    dto.ArchivedNews
+			data []byte
+		)
+		if err := rows.Scan(&data, &a.ArchivedAt); err != nil {
+			return nil, fmt.Errorf("error scanning row: %w", err)
+		}
+		if err := json.Unmarshal(data, &a.News); err != nil {
+			return nil, fmt.Errorf("error decoding archived news: %w", err)
+		}
+		archived = append(archived, a)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return archived, nil
+}
+
+// GetSyncRuns retrieves the counters of every sync run, the first first
+func (s *backupStore) GetSyncRuns() ([]dto.SyncRun, error) {
+	query := `
+		SELECT
+			id,
+			started_at,
+			finished_at,
+			fetched,
+			summarized,
+			extractive,
+			failed,
+			inconsistent,
+			inserted,
+			quarantined,
+			cache_hits,
+			cache_misses,
+			prompt_tokens,
+			completion_tokens,
+			cost_usd
+		FROM sync_runs
+		ORDER BY started_at, id`
+
+	rows, err := s.dbPool.Query(context.Background(), query)
+	if err != nil {
+		return nil, fmt.Errorf("error querying sync runs: %w", err)
+	}
+	defer rows.Close()
+
+	var runs []dto.SyncRun
+	for rows.Next() {
+		var r dto.SyncRun
+		err := rows.Scan(&r.Id, &r.StartedAt, &r.FinishedAt, &r.Fetched, &r.Summarized, &r.Extractive, &r.Failed, &r.Inconsistent,
+			&r.Inserted, &r.Quarantined, &r.CacheHits, &r.CacheMisses, &r.PromptTokens, &r.CompletionTokens, &r.CostUSD)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning row: %w", err)
+		}
+		runs = append(runs, r)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return runs, nil
+}
+
+// GetClustersAfter retrieves the story clusters after the id in id order
+func (s *backupStore) GetClustersAfter(afterID int64, limit int) ([]dto.StoryCluster, error) {
+	query := `
+		SELECT ` + clusterSelectColumns + `
+		FROM story_clusters c
+		WHERE c.id > $1
+		ORDER BY c.id
+		LIMIT $2`
+
+	rows, err := s.dbPool.Query(context.Background(), query, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error querying clusters: %w", err)
+	}
+
+	return scanClusters(rows)
+}
+
+// GetClusterMembersByLinks retrieves the cluster memberships of the news stories
+func (s *backupStore) GetClusterMembersByLinks(sourceLinks []string) ([]dto.ClusterMember, error) {
+	query := `
+		SELECT m.cluster_id, m.source_link, n.source_name, m.similarity, m.added_at
+		FROM story_cluster_members m
+		JOIN news n ON n.source_link = m.source_link
+		WHERE m.source_link = ANY($1)
+		ORDER BY m.source_link`
+
+	rows, err := s.dbPool.Query(context.Background(), query, sourceLinks)
+	if err != nil {
+		return nil, fmt.Errorf("error querying cluster members: %w", err)
+	}
+	defer rows.Close()
+
+	var members []dto.ClusterMember
+	for rows.Next() {
+		var m dto.ClusterMember
+		if err := rows.Scan(&m.ClusterId, &m.SourceLink, &m.SourceName, &m.Similarity, &m.AddedAt); err != nil {
+			return nil, fmt.Errorf("error scanning row: %w", err)
+		}
+		members = append(members, m)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return members, nil
+}
+
+// GetTombstonesAfter retrieves the links of the deleted news stories after
+// the source link in source link order
+func (s *backupStore) GetTombstonesAfter(afterLink string, limit int) ([]dto.Tombstone, error) {
+	query := `
+		SELECT source_link, deleted_at
+		FROM news_tombstones
+		WHERE source_link > $1
+		ORDER BY source_link
+		LIMIT $2`
+
+	rows, err := s.dbPool.Query(context.Background(), query, afterLink, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error querying tombstones: %w", err)
+	}
+	defer rows.Close()
+
+	var tombstones []dto.Tombstone
+	for rows.Next() {
+		var t dto.Tombstone
+		if err := rows.Scan(&t.SourceLink, &t.DeletedAt); err != nil {
+			return nil, fmt.Errorf("error scanning row: %w", err)
+		}
+		tombstones = append(tombstones, t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return tombstones, nil
+}
+
+// GetAIUsageAfter retrieves the usage of the AI requests after the id in id
+// order
+func (s *backupStore) GetAIUsageAfter(afterID int64, limit int) ([]dto.AIUsage, error) {
+	query := `
+		SELECT
+			id,
+			COALESCE(run_id, ''),
+			COALESCE(batch_id, ''),
+			COALESCE(source_name, ''),
+			COALESCE(source_link, ''),
+			model,
+			prompt_tokens,
+			completion_tokens,
+			cost_usd,
+			created_at
+		FROM ai_usage
+		WHERE id > $1
+		ORDER BY id
+		LIMIT $2`
+
+	rows, err := s.dbPool.Query(context.Background(), query, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error querying ai usage: %w", err)
+	}
+	defer rows.Close()
+
+	var usage []dto.AIUsage
+	for rows.Next() {
+		var u dto.AIUsage
+		err := rows.Scan(&u.Id, &u.RunID, &u.BatchID, &u.SourceName, &u.SourceLink, &u.Model,
+			&u.PromptTokens, &u.CompletionTokens, &u.CostUSD, &u.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning row: %w", err)
+		}
+		usage = append(usage, u)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return usage, nil
+}
+
+// RestoreNews inserts the news stories with their retraction, the stories
+// already stored are left alone
+func (s *backupStore) RestoreNews(newsStories []dto.News) error {
+	if len(newsStories) == 0 {
+		return nil
+	}
+
+	return WrapInTx(context.Background(), s.dbPool, func(tx pgx.Tx) error {
+		if _, err := upsertNews(tx, newsStories, `ON CONFLICT (source_link) DO NOTHING`); err != nil {
+			return err
+		}
+
+		for _, news := range newsStories {
+			if !news.Retracted {
+				continue
+			}
+			_, err := tx.Exec(context.Background(), `
+				UPDATE news
+				SET retracted = true, retracted_at = $2, retraction_reason = NULLIF($3, '')
+				WHERE source_link = $1`,
+				news.SourceLink, nullableTime(news.RetractedAt), news.RetractionReason)
+			if err != nil {
+				return fmt.Errorf("error restoring retraction of %s: %w", news.SourceLink, err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// RestoreNewsEntities saves the entities mentioned in the news stories
+func (s *backupStore) RestoreNewsEntities(newsEntities []dto.NewsEntity) error {
+	store := entityStore{dbPool: s.dbPool}
+	return store.AddNewsEntities(newsEntities)
+}
+
+// RestoreRevisions inserts the recorded versions of the news stories
+func (s *backupStore) RestoreRevisions(revisions []dto.Revision) error {
+	query := `
+		INSERT INTO article_revisions (
+			source_link,
+			revision,
+			headline,
+			story,
+			content_hash,
+			diff,
+			modified_at,
+			created_at
+		) VALUES ($1, $2, $3, $4, NULLIF($5, ''), NULLIF($6, ''), $7, $8)
+		ON CONFLICT (source_link, revision) DO NOTHING`
+
+	return WrapInTx(context.Background(), s.dbPool, func(tx pgx.Tx) error {
+		for _, r := range revisions {
+			_, err := tx.Exec(context.Background(), query,
+				r.SourceLink, r.Revision, r.Headline, r.Story, r.ContentHash, r.Diff, nullableTime(r.ModifiedAt), r.CreatedAt.UTC())
+			if err != nil {
+				return fmt.Errorf("error restoring revision %d of %s: %w", r.Revision, r.SourceLink, err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// RestoreTranslations saves the translations of the news stories
+func (s *backupStore) RestoreTranslations(translations []dto.Translation) error {
+	store := translationStore{dbPool: s.dbPool}
+	return store.AddTranslations(translations)
+}
+
+// RestoreArchivedNews inserts the archived news stories
+func (s *backupStore) RestoreArchivedNews(archived []dto.ArchivedNews) error {
+	query := `
+		INSERT INTO news_archive (source_link, source_name, category, crawled_at, archived_at, news)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (source_link) DO NOTHING`
+
+	return WrapInTx(context.Background(), s.dbPool, func(tx pgx.Tx) error {
+		for _, a := range archived {
+			data, err := json.Marshal(a.News)
+			if err != nil {
+				return fmt.Errorf("error encoding news %s: %w", a.News.SourceLink, err)
+			}
+
+			_, err = tx.Exec(context.Background(), query,
+				a.News.SourceLink, a.News.SourceName, a.News.Category, nullableTime(a.News.CrawledAt), a.ArchivedAt.UTC(), data)
+			if err != nil {
+				return fmt.Errorf("error restoring archived news %s: %w", a.News.SourceLink, err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// RestoreSyncRuns records the counters of the sync runs
+func (s *backupStore) RestoreSyncRuns(runs []dto.SyncRun) error {
+	store := syncRunStore{dbPool: s.dbPool}
+	for _, r := range runs {
+		if err := store.AddSyncRun(r); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RestoreClusters inserts the story clusters with their ids, the clusters
+// created afterwards are numbered after them
+func (s *backupStore) RestoreClusters(clusters []dto.StoryCluster) error {
+	query := `
+		INSERT INTO story_clusters (id, headline, representative_link, first_seen, last_seen, size, centroid)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (id) DO NOTHING`
+
+	return WrapInTx(context.Background(), s.dbPool, func(tx pgx.Tx) error {
+		for _, c := range clusters {
+			centroid, err := json.Marshal(c.Centroid)
+			if err != nil {
+				return fmt.Errorf("error encoding centroid of cluster %d: %w", c.Id, err)
+			}
+
+			_, err = tx.Exec(context.Background(), query,
+				c.Id, c.Headline, c.RepresentativeLink, c.FirstSeen.UTC(), c.LastSeen.UTC(), c.Size, centroid)
+			if err != nil {
+				return fmt.Errorf("error restoring cluster %d: %w", c.Id, err)
+			}
+		}
+
+		return restartIdentity(tx, "story_clusters")
+	})
+}
+
+// RestoreClusterMembers inserts the cluster memberships of the news stories
+func (s *backupStore) RestoreClusterMembers(members []dto.ClusterMember) error {
+	query := `
+		INSERT INTO story_cluster_members (cluster_id, source_link, similarity, added_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (source_link) DO NOTHING`
+
+	return WrapInTx(context.Background(), s.dbPool, func(tx pgx.Tx) error {
+		for _, m := range members {
+			_, err := tx.Exec(context.Background(), query, m.ClusterId, m.SourceLink, m.Similarity, m.AddedAt.UTC())
+			if err != nil {
+				return fmt.Errorf("error restoring member %s of cluster %d: %w", m.SourceLink, m.ClusterId, err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// RestoreTombstones inserts the links of the deleted news stories
+func (s *backupStore) RestoreTombstones(tombstones []dto.Tombstone) error {
+	query := `
+		INSERT INTO news_tombstones (source_link, deleted_at)
+		VALUES ($1, COALESCE($2, now()))
+		ON CONFLICT (source_link) DO NOTHING`
+
+	return WrapInTx(context.Background(), s.dbPool, func(tx pgx.Tx) error {
+		for _, t := range tombstones {
+			if _, err := tx.Exec(context.Background(), query, t.SourceLink, nullableTime(t.DeletedAt)); err != nil {
+				return fmt.Errorf("error restoring tombstone of %s: %w", t.SourceLink, err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// RestoreAIUsage inserts the usage of the AI requests with their ids, so the
+// spend caps count what was already spent
+func (s *backupStore) RestoreAIUsage(usage []dto.AIUsage) error {
+	query := `
+		INSERT INTO ai_usage (
+			id,
+			run_id,
+			batch_id,
+			source_name,
+			source_link,
+			model,
+			prompt_tokens,
+			completion_tokens,
+			cost_usd,
+			created_at
+		) VALUES ($1, NULLIF($2, ''), NULLIF($3, ''), NULLIF($4, ''), NULLIF($5, ''), $6, $7, $8, $9, $10)
+		ON CONFLICT (id) DO NOTHING`
+
+	return WrapInTx(context.Background(), s.dbPool, func(tx pgx.Tx) error {
+		for _, u := range usage {
+			_, err := tx.Exec(context.Background(), query,
+				u.Id, u.RunID, u.BatchID, u.SourceName, u.SourceLink, u.Model, u.PromptTokens, u.CompletionTokens, u.CostUSD, u.CreatedAt.UTC())
+			if err != nil {
+				return fmt.Errorf("error restoring ai usage %d: %w", u.Id, err)
+			}
+		}
+
+		return restartIdentity(tx, "ai_usage")
+	})
+}
+
+// restartIdentity moves the id sequence of the table past the restored ids
+func restartIdentity(tx pgx.Tx, table string) error {
+	_, err := tx.Exec(context.Background(),
+		`SELECT setval(pg_get_serial_sequence($1, 'id'), (SELECT COALESCE(max(id), 0) + 1 FROM `+pgx.Identifier{table}.Sanitize()+`), false)`, table)
+	if err != nil {
+		return fmt.Errorf("error restarting the ids of %s: %w", table, err)
+	}
+
+	return nil
+}
+
+// ClearTables deletes every row of the tables, with the rows referencing them
+func (s *backupStore) ClearTables(tables []string) error {
+	names := make([]string, len(tables))
+	for i, table := range tables {
+		names[i] = pgx.Identifier{table}.Sanitize()
+	}
+
+	_, err := s.dbPool.Exec(context.Background(), `TRUNCATE TABLE `+strings.Join(names, ", ")+` CASCADE`)
+	if err != nil {
+		return fmt.Errorf("error clearing tables: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,237 @@
+package pg
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"ncrawler/internal/dto"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type clusterStore struct {
+	dbPool *pgxpool.Pool
+}
+
+const clusterSelectColumns = `
+	c.id,
+	c.headline,
+	c.representative_link,
+	c.first_seen,
+	c.last_seen,
+	c.size,
+	(SELECT COUNT(DISTINCT n.source_name)
+		FROM story_cluster_members m
+		JOIN news n ON n.source_link = m.source_link
+		WHERE m.cluster_id = c.id) AS sources,
+	c.centroid`
+
+func scanClusters(rows pgx.Rows) ([]dto.StoryCluster, error) {
+	defer rows.Close()
+
+	var clusters []dto.StoryCluster
+	for rows.Next() {
+		var (
+			c        dto.StoryCluster
+			centroid []byte
+		)
+		err := rows.Scan(&c.Id, &c.Headline, &c.RepresentativeLink, &c.FirstSeen, &c.LastSeen, &c.Size, &c.Sources, &centroid)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning row: %w", err)
+		}
+		if err := json.Unmarshal(centroid, &c.Centroid); err != nil {
+			return nil, fmt.Errorf("error decoding centroid of cluster %d: %w", c.Id, err)
+		}
+		clusters = append(clusters, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return clusters, nil
+}
+
+// GetOpenClusters retrieves the clusters that got an article since the given time
+func (s *clusterStore) GetOpenClusters(since time.Time) ([]dto.StoryCluster, error) {
+	query := `
+		SELECT ` + clusterSelectColumns + `
+		FROM story_clusters c
+		WHERE c.last_seen >= $1`
+
+	rows, err := s.dbPool.Query(context.Background(), query, since)
+	if err != nil {
+		return nil, fmt.Errorf("error querying open clusters: %w", err)
+	}
+
+	return scanClusters(rows)
+}
+
+// GetClusters retrieves the clusters seen since the given time covered by at
+// least minSources sources, the largest first
+func (s *clusterStore) GetClusters(since time.Time, minSources, limit int) ([]dto.StoryCluster, error) {
+	query := `
+		SELECT * FROM (
+			SELECT ` + clusterSelectColumns + `
+			FROM story_clusters c
+			WHERE c.last_seen >= $1
+		) clusters
+		WHERE sources >= $2
+		ORDER BY size DESC, last_seen DESC
+		LIMIT $3`
+
+	rows, err := s.dbPool.Query(context.Background(), query, since, minSources, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error querying clusters: %w", err)
+	}
+
+	return scanClusters(rows)
+}
+
+// AddClusterMember adds the news story to the cluster and saves the cluster,
+// creating it when it has no id yet. A news story already in a cluster is
+// left there and the cluster is not saved, it reports whether the story was
+// added.
+func (s *clusterStore) AddClusterMember(c *dto.StoryCluster, member dto.ClusterMember) (bool, error) {
+	centroid, err := json.Marshal(c.Centroid)
+	if err != nil {
+		return false, fmt.Errorf("error encoding centroid: %w", err)
+	}
+
+	memberQuery := `
+		INSERT INTO story_cluster_members (cluster_id, source_link, similarity)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (source_link) DO NOTHING`
+
+	var added bool
+	err = WrapInTx(context.Background(), s.dbPool, func(tx pgx.Tx) error {
+		if c.Id == 0 {
+			var id int64
+			err := tx.QueryRow(context.Background(), `
+				INSERT INTO story_clusters (headline, representative_link, first_seen, last_seen, size, centroid)
+				VALUES ($1, $2, $3, $4, $5, $6)
+				RETURNING id`,
+				c.Headline, c.RepresentativeLink, c.FirstSeen, c.LastSeen, c.Size, centroid).Scan(&id)
+			if err != nil {
+				return fmt.Errorf("error creating cluster: %w", err)
+			}
+
+			tag, err := tx.Exec(context.Background(), memberQuery, id, member.SourceLink, member.Similarity)
+			if err != nil {
+				return fmt.Errorf("error adding %s to cluster %d: %w", member.SourceLink, id, err)
+			}
+			if tag.RowsAffected() == 0 {
+				_, err := tx.Exec(context.Background(), `DELETE FROM story_clusters WHERE id = $1`, id)
+				if err != nil {
+					return fmt.Errorf("error deleting empty cluster %d: %w", id, err)
+				}
+				return nil
+			}
+
+			c.Id, added = id, true
+			return nil
+		}
+
+		tag, err := tx.Exec(context.Background(), memberQuery, c.Id, member.SourceLink, member.Similarity)
+		if err != nil {
+			return fmt.Errorf("error adding %s to cluster %d: %w", member.SourceLink, c.Id, err)
+		}
+		if tag.RowsAffected() == 0 {
+			return nil
+		}
+
+		_, err = tx.Exec(context.Background(), `
+			UPDATE story_clusters
+			SET last_seen = $2, size = $3, centroid = $4, updated_at = now()
+			WHERE id = $1`,
+			c.Id, c.LastSeen, c.Size, centroid)
+		if err != nil {
+			return fmt.Errorf("error updating cluster %d: %w", c.Id, err)
+		}
+
+		added = true
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return added, nil
+}
+
+// GetClusterMembers retrieves the news stories of the cluster
+func (s *clusterStore) GetClusterMembers(clusterID int64) ([]dto.ClusterMember, error) {
+	query := `
+		SELECT m.cluster_id, m.source_link, n.source_name, m.similarity, m.added_at
+		FROM story_cluster_members m
+		JOIN news n ON n.source_link = m.source_link
+		WHERE m.cluster_id = $1
+		ORDER BY m.added_at`
+
+	rows, err := s.dbPool.Query(context.Background(), query, clusterID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying members of cluster %d: %w", clusterID, err)
+	}
+	defer rows.Close()
+
+	var members []dto.ClusterMember
+	for rows.Next() {
+		var m dto.ClusterMember
+		if err := rows.Scan(&m.ClusterId, &m.SourceLink, &m.SourceName, &m.Similarity, &m.AddedAt); err != nil {
+			return nil, fmt.Errorf("error scanning row: %w", err)
+		}
+		members = append(members, m)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return members, nil
+}
+
+// GetUnclusteredNews retrieves the news stories crawled since the given time
+// that belong to no cluster, the oldest first
+func (s *clusterStore) GetUnclusteredNews(since time.Time) ([]dto.News, error) {
+	query := `
+		SELECT ` + newsSelectColumns + `
+		FROM news
+		WHERE created_at >= $1
+		AND NOT quarantined
+		AND NOT EXISTS (
+			SELECT 1
+			FROM story_cluster_members m
+			WHERE m.source_link = news.source_link
+		)
+		ORDER BY created_at`
+
+	rows, err := s.dbPool.Query(context.Background(), query, since)
+	if err != nil {
+		return nil, fmt.Errorf("error querying unclustered news: %w", err)
+	}
+
+	return scanNews(rows)
+}
+
+// GetClusterNews retrieves the news stories of the cluster
+func (s *clusterStore) GetClusterNews(clusterID int64) ([]dto.News, error) {
+	query := `
+		SELECT ` + newsSelectColumns + `
+		FROM news
+		WHERE source_link IN (
+			SELECT source_link
+			FROM story_cluster_members
+			WHERE cluster_id = $1
+		)
+		ORDER BY COALESCE(published_at, created_at)`
+
+	rows, err := s.dbPool.Query(context.Background(), query, clusterID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying news of cluster %d: %w", clusterID, err)
+	}
+
+	return scanNews(rows)
+}
@@ -0,0 +1,10 @@
+package pg
+
+const (
+	// HIDE_RETRACTED hides the news stories retracted by their publisher from
+	// the queries and exports
+	HIDE_RETRACTED = true
+
+	// MAX_QUERY_PARAMS is the number of parameters Postgres accepts in a query
+	MAX_QUERY_PARAMS = 65535
+)
@@ -0,0 +1,172 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"ncrawler/internal/dto"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type entityStore struct {
+	dbPool *pgxpool.Pool
+}
+
+// AddNewsEntities saves the entities mentioned in news stories. The aliases
+// of an entity seen before are merged and the mentions of the same news
+// story are replaced.
+func (s *entityStore) AddNewsEntities(newsEntities []dto.NewsEntity) error {
+	if len(newsEntities) == 0 {
+		return nil
+	}
+
+	entityQuery := `
+		INSERT INTO entities (name, type, aliases)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (name, type) DO UPDATE
+		SET aliases = ARRAY(
+			SELECT DISTINCT unnest(entities.aliases || EXCLUDED.aliases)
+		)
+		RETURNING id`
+
+	mentionQuery := `
+		INSERT INTO news_entities (source_link, entity_id, mentions)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (source_link, entity_id) DO UPDATE
+		SET mentions = EXCLUDED.mentions`
+
+	return WrapInTx(context.Background(), s.dbPool, func(tx pgx.Tx) error {
+		for _, ne := range newsEntities {
+			aliases := ne.Aliases
+			if aliases == nil {
+				aliases = []string{}
+			}
+
+			var id int64
+			err := tx.QueryRow(context.Background(), entityQuery, ne.Name, ne.Type, aliases).Scan(&id)
+			if err != nil {
+				return fmt.Errorf("error saving entity %s: %w", ne.Name, err)
+			}
+
+			_, err = tx.Exec(context.Background(), mentionQuery, ne.SourceLink, id, ne.Mentions)
+			if err != nil {
+				return fmt.Errorf("error saving entity %s of %s: %w", ne.Name, ne.SourceLink, err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// GetTopEntities returns the entities mentioned in the most news stories
+// crawled since the given time, optionally of one type
+func (s *entityStore) GetTopEntities(since time.Time, entityType string, limit int) ([]dto.EntityCount, error) {
+	query := `
+		SELECT
+			e.name,
+			e.type,
+			COALESCE(e.aliases, '{}'),
+			COUNT(*) AS articles,
+			MAX(n.created_at)
+		FROM entities e
+		JOIN news_entities ne ON ne.entity_id = e.id
+		JOIN news n ON n.source_link = ne.source_link
+		WHERE n.created_at >= $1
+		AND ($2 = '' OR e.type = $2)
+		AND NOT n.quarantined
+		` + hideRetracted("n") + `
+		GROUP BY e.id
+		ORDER BY articles DESC, e.name
+		LIMIT $3`
+
+	rows, err := s.dbPool.Query(context.Background(), query, since, entityType, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error querying top entities: %w", err)
+	}
+	defer rows.Close()
+
+	var counts []dto.EntityCount
+	for rows.Next() {
+		var ec dto.EntityCount
+		if err := rows.Scan(&ec.Name, &ec.Type, &ec.Aliases, &ec.Articles, &ec.LastSeen); err != nil {
+			return nil, fmt.Errorf("error scanning row: %w", err)
+		}
+		counts = append(counts, ec)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return counts, nil
+}
+
+// GetNewsByEntity retrieves the latest news stories mentioning the entity,
+// found by its canonical name or one of its aliases
+func (s *entityStore) GetNewsByEntity(name string, limit int) ([]dto.News, error) {
+	query := `
+		SELECT ` + newsSelectColumns + `
+		FROM news
+		WHERE NOT quarantined
+		` + hideRetracted("news") + `
+		AND source_link IN (
+			SELECT ne.source_link
+			FROM news_entities ne
+			JOIN entities e ON e.id = ne.entity_id
+			WHERE lower(e.name) = lower($1)
+			OR lower($1) = ANY(SELECT lower(a) FROM unnest(e.aliases) a)
+		)
+		ORDER BY COALESCE(published_at, created_at) DESC
+		LIMIT $2`
+
+	rows, err := s.dbPool.Query(context.Background(), query, name, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error querying news of entity %s: %w", name, err)
+	}
+
+	return scanNews(rows)
+}
+
+// GetNewsWithoutEntities retrieves the latest news stories whose entities
+// were never extracted
+func (s *entityStore) GetNewsWithoutEntities(limit int) ([]dto.News, error) {
+	query := `
+		SELECT ` + newsSelectColumns + `
+		FROM news
+		WHERE entities_extracted_at IS NULL
+		AND NOT EXISTS (
+			SELECT 1
+			FROM news_entities ne
+			WHERE ne.source_link = news.source_link
+		)
+		ORDER BY created_at DESC
+		LIMIT $1`
+
+	rows, err := s.dbPool.Query(context.Background(), query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error querying news without entities: %w", err)
+	}
+
+	return scanNews(rows)
+}
+
+// MarkEntitiesExtracted records that the entities of the news stories were
+// extracted, so the stories mentioning none are not extracted again
+func (s *entityStore) MarkEntitiesExtracted(sourceLinks []string) error {
+	if len(sourceLinks) == 0 {
+		return nil
+	}
+
+	_, err := s.dbPool.Exec(context.Background(), `
+		UPDATE news
+		SET entities_extracted_at = now()
+		WHERE source_link = ANY($1)`, sourceLinks)
+	if err != nil {
+		return fmt.Errorf("error marking entities extracted: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,115 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// GetLinksAfter retrieves the source links of the news stories after the
+// source link, in source link order
+func (s *newsStore) GetLinksAfter(afterLink string, limit int) ([]string, error) {
+	rows, err := s.dbPool.Query(context.Background(), `SELECT source_link FROM news WHERE source_link > $1 ORDER BY source_link LIMIT $2`, afterLink, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error querying links: %w", err)
+	}
+
+	defer rows.Close()
+
+	var links []string
+	for rows.Next() {
+		var link string
+		if err := rows.Scan(&link); err != nil {
+			return nil, fmt.Errorf("error scanning row: %w", err)
+		}
+		links = append(links, link)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return links, nil
+}
+
+// CanonicalizeLink moves the news story stored under link, with its
+// translations, entities, revisions and cluster membership, to the canonical
+// link. When a story is already stored under the canonical link, its empty
+// fields are filled from the other story, which is then deleted along with
+// the rows the canonical story already has. It tells whether the stories
+// were merged.
+func (s *newsStore) CanonicalizeLink(link, canonical string) (bool, error) {
+	ctx := context.Background()
+	merged := false
+
+	err := WrapInTx(ctx, s.dbPool, func(tx pgx.Tx) error {
+		if err := tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM news WHERE source_link = $1)`, canonical).Scan(&merged); err != nil {
+			return fmt.Errorf("error checking news %s: %w", canonical, err)
+		}
+
+		if !merged {
+			// one statement, so the foreign keys are checked once every row moved
+			_, err := tx.Exec(ctx, `
+				WITH
+					translations AS (UPDATE news_translations SET source_link = $2 WHERE source_link = $1),
+					entities AS (UPDATE news_entities SET source_link = $2 WHERE source_link = $1),
+					revisions AS (UPDATE article_revisions SET source_link = $2 WHERE source_link = $1),
+					members AS (UPDATE story_cluster_members SET source_link = $2 WHERE source_link = $1)
+				UPDATE news SET source_link = $2 WHERE source_link = $1`,
+				link, canonical)
+			if err != nil {
+				return fmt.Errorf("error moving news %s: %w", link, err)
+			}
+		} else {
+			queries := []string{
+				`UPDATE news n
+				SET
+					summary = CASE WHEN COALESCE(n.summary, '') = '' THEN o.summary ELSE n.summary END,
+					bullet_points = CASE WHEN COALESCE(n.summary, '') = '' THEN o.bullet_points ELSE n.bullet_points END,
+					summary_method = CASE WHEN COALESCE(n.summary, '') = '' THEN o.summary_method ELSE n.summary_method END,
+					consistency_score = CASE WHEN COALESCE(n.summary, '') = '' THEN o.consistency_score ELSE n.consistency_score END,
+					unsupported_claims = CASE WHEN COALESCE(n.summary, '') = '' THEN o.unsupported_claims ELSE n.unsupported_claims END,
+					image_link = COALESCE(NULLIF(n.image_link, ''), o.image_link),
+					meta_description = COALESCE(NULLIF(n.meta_description, ''), o.meta_description),
+					meta_keywords = COALESCE(NULLIF(n.meta_keywords, ''), o.meta_keywords),
+					language = COALESCE(NULLIF(n.language, ''), o.language),
+					published_at = COALESCE(n.published_at, o.published_at),
+					modified_at = COALESCE(n.modified_at, o.modified_at),
+					created_at = LEAST(n.created_at, o.created_at)
+				FROM news o
+				WHERE n.source_link = $2 AND o.source_link = $1`,
+				`UPDATE news_translations t SET source_link = $2 WHERE source_link = $1
+				AND NOT EXISTS (SELECT 1 FROM news_translations c WHERE c.source_link = $2 AND c.language = t.language)`,
+				`UPDATE news_entities e SET source_link = $2 WHERE source_link = $1
+				AND NOT EXISTS (SELECT 1 FROM news_entities c WHERE c.source_link = $2 AND c.entity_id = e.entity_id)`,
+				`UPDATE article_revisions r SET source_link = $2 WHERE source_link = $1
+				AND NOT EXISTS (SELECT 1 FROM article_revisions c WHERE c.source_link = $2 AND c.revision = r.revision)`,
+				`UPDATE story_cluster_members SET source_link = $2 WHERE source_link = $1
+				AND NOT EXISTS (SELECT 1 FROM story_cluster_members c WHERE c.source_link = $2)`,
+				`DELETE FROM news WHERE source_link = $1`,
+			}
+			for _, query := range queries {
+				if _, err := tx.Exec(ctx, query, link, canonical); err != nil {
+					return fmt.Errorf("error merging news %s into %s: %w", link, canonical, err)
+				}
+			}
+		}
+
+		for _, query := range []string{
+			`UPDATE news SET duplicate_of = $2 WHERE duplicate_of = $1`,
+			`UPDATE ai_usage SET source_link = $2 WHERE source_link = $1`,
+		} {
+			if _, err := tx.Exec(ctx, query, link, canonical); err != nil {
+				return fmt.Errorf("error moving references of news %s: %w", link, err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return merged, nil
+}
@@ -0,0 +1,78 @@
+package pg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// migrationStore keeps the applied schema version, it implements migrate.Driver
+type migrationStore struct {
+	dbPool *pgxpool.Pool
+}
+
+func (s *migrationStore) ensureTable(ctx context.Context) error {
+	_, err := s.dbPool.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version BIGINT NOT NULL PRIMARY KEY,
+			dirty BOOLEAN NOT NULL
+		)`)
+	if err != nil {
+		return fmt.Errorf("error creating schema_migrations: %w", err)
+	}
+
+	return nil
+}
+
+// Version returns the applied schema version, 0 when none
+func (s *migrationStore) Version() (uint, bool, error) {
+	ctx := context.Background()
+	if err := s.ensureTable(ctx); err != nil {
+		return 0, false, err
+	}
+
+	var (
+		version int64
+		dirty   bool
+	)
+	err := s.dbPool.QueryRow(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&version, &dirty)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("error querying schema version: %w", err)
+	}
+
+	return uint(version), dirty, nil
+}
+
+// Apply runs the migration script and records the version in one transaction
+func (s *migrationStore) Apply(script string, version uint) error {
+	ctx := context.Background()
+	if err := s.ensureTable(ctx); err != nil {
+		return err
+	}
+
+	return WrapInTx(ctx, s.dbPool, func(tx pgx.Tx) error {
+		// without arguments the script runs with the simple protocol, which
+		// allows several statements
+		if _, err := tx.Exec(ctx, script); err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(ctx, `DELETE FROM schema_migrations`); err != nil {
+			return fmt.Errorf("error clearing schema version: %w", err)
+		}
+		if version == 0 {
+			return nil
+		}
+		if _, err := tx.Exec(ctx, `INSERT INTO schema_migrations (version, dirty) VALUES ($1, false)`, int64(version)); err != nil {
+			return fmt.Errorf("error saving schema version: %w", err)
+		}
+
+		return nil
+	})
+}
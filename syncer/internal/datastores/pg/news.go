@@ -4,13 +4,18 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"ncrawler/internal/dto"
+	"ncrawler/internal/lang"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// newsInsertColumns is the number of columns set by AddNewsStories
+const newsInsertColumns = 28
+
 type newsStore struct {
 	dbPool *pgxpool.Pool
 }
@@ -35,10 +40,15 @@ func (s *newsStore) DetectNewLinks(foundedLinks []string) ([]string, error) {
 		valueArgs = append(valueArgs, link)
 	}
 
+	// the archived and deleted news count as known so they are not crawled again
 	query := fmt.Sprintf(`
 		SELECT source_link 
 		FROM news 
-		WHERE source_link = ANY($1)`) // Use ANY instead of IN for better performance
+		WHERE source_link = ANY($1)
+		UNION
+		SELECT source_link FROM news_archive WHERE source_link = ANY($1)
+		UNION
+		SELECT source_link FROM news_tombstones WHERE source_link = ANY($1)`) // Use ANY instead of IN for better performance
 
 	rows, err := s.dbPool.Query(context.Background(), query, foundedLinks)
 	if err != nil {
@@ -69,62 +79,750 @@ func (s *newsStore) DetectNewLinks(foundedLinks []string) ([]string, error) {
 	return newLinks, nil
 }
 
-func (s *newsStore) AddNewsStories(newsStories []dto.News) error {
+// AddNewsStories upserts the news stories by their source link. A story
+// already stored is merged according to the policy, dto.MERGE_KEEP,
+// dto.MERGE_OVERWRITE or dto.MERGE_FILL_EMPTY. Stories repeated in the batch
+// are stored once and large batches are split to stay under MAX_QUERY_PARAMS.
+func (s *newsStore) AddNewsStories(newsStories []dto.News, policy string) (dto.UpsertResult, error) {
+	var result dto.UpsertResult
 	if len(newsStories) == 0 {
-		return nil
+		return result, nil
 	}
 
-	return WrapInTx(context.Background(), s.dbPool, func(tx pgx.Tx) error {
-		// Create values string for bulk insert
-		valueStrings := make([]string, 0, len(newsStories))
-		valueArgs := make([]interface{}, 0, len(newsStories)*10)
+	conflictClause, ok := newsConflictClauses[policy]
+	if !ok {
+		return result, fmt.Errorf("unknown merge policy %s", policy)
+	}
 
-		for i, news := range newsStories {
-			base := i * 10
-			valueStrings = append(valueStrings,
-				fmt.Sprintf("($%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d)",
-					base+1, base+2, base+3, base+4, base+5, base+6, base+7, base+8, base+9, base+10))
+	// the same row can not be upserted twice by one statement, the last
+	// version of a repeated story wins
+	unique := make([]dto.News, 0, len(newsStories))
+	seen := make(map[string]int, len(newsStories))
+	for _, news := range newsStories {
+		if i, ok := seen[news.SourceLink]; ok {
+			unique[i] = news
+			result.Skipped++
+			continue
+		}
+		seen[news.SourceLink] = len(unique)
+		unique = append(unique, news)
+	}
 
-			// Convert BulletPoints to a proper PostgreSQL array if it's nil
-			bulletPoints := news.BulletPoints
-			if bulletPoints == nil {
-				bulletPoints = []string{} // Convert nil to empty array for PostgreSQL
+	chunkSize := MAX_QUERY_PARAMS / newsInsertColumns
+	err := WrapInTx(context.Background(), s.dbPool, func(tx pgx.Tx) error {
+		for start := 0; start < len(unique); start += chunkSize {
+			chunk := unique[start:min(start+chunkSize, len(unique))]
+			chunkResult, err := upsertNews(tx, chunk, conflictClause)
+			if err != nil {
+				return err
 			}
+			result.Add(chunkResult)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return dto.UpsertResult{}, err
+	}
+
+	return result, nil
+}
+
+// summaryIsEmpty is the condition of a stored news story without summary
+const summaryIsEmpty = `COALESCE(news.summary, '') = ''`
+
+// storyChanged is the condition of a stored news story whose text differs
+// from the incoming one, its summary no longer matches
+const storyChanged = `(news.headline, news.story) IS DISTINCT FROM (EXCLUDED.headline, EXCLUDED.story)`
+
+// newsConflictClauses merge a stored news story according to the merge policy.
+// Only the rows that change are returned, so the others count as skipped.
+var newsConflictClauses = map[string]string{
+	dto.MERGE_KEEP: `ON CONFLICT (source_link) DO NOTHING`,
+	dto.MERGE_OVERWRITE: `
+		ON CONFLICT (source_link) DO UPDATE
+		SET
+			category = EXCLUDED.category,
+			headline = EXCLUDED.headline,
+			story = EXCLUDED.story,
+			image_link = EXCLUDED.image_link,
+			meta_description = EXCLUDED.meta_description,
+			content_hash = EXCLUDED.content_hash,
+			simhash = EXCLUDED.simhash,
+			published_at = COALESCE(EXCLUDED.published_at, news.published_at),
+			modified_at = COALESCE(EXCLUDED.modified_at, news.modified_at)
+		WHERE (news.category, news.headline, news.story, news.image_link, news.meta_description, news.published_at, news.modified_at)
+			IS DISTINCT FROM
+			(EXCLUDED.category, EXCLUDED.headline, EXCLUDED.story, EXCLUDED.image_link, EXCLUDED.meta_description,
+			COALESCE(EXCLUDED.published_at, news.published_at), COALESCE(EXCLUDED.modified_at, news.modified_at))`,
+	dto.MERGE_FILL_EMPTY: `
+		ON CONFLICT (source_link) DO UPDATE
+		SET
+			summary = CASE WHEN ` + summaryIsEmpty + ` THEN EXCLUDED.summary ELSE news.summary END,
+			bullet_points = CASE WHEN ` + summaryIsEmpty + ` THEN EXCLUDED.bullet_points ELSE news.bullet_points END,
+			summary_method = CASE WHEN ` + summaryIsEmpty + ` THEN EXCLUDED.summary_method ELSE news.summary_method END,
+			consistency_score = CASE WHEN ` + summaryIsEmpty + ` THEN EXCLUDED.consistency_score ELSE news.consistency_score END,
+			unsupported_claims = CASE WHEN ` + summaryIsEmpty + ` THEN EXCLUDED.unsupported_claims ELSE news.unsupported_claims END,
+			quarantined = CASE WHEN ` + summaryIsEmpty + ` THEN news.quarantined OR EXCLUDED.quarantined ELSE news.quarantined END,
+			quarantine_reasons = CASE WHEN ` + summaryIsEmpty + ` THEN ARRAY(
+				SELECT DISTINCT unnest(COALESCE(news.quarantine_reasons, '{}') || EXCLUDED.quarantine_reasons)
+			) ELSE news.quarantine_reasons END,
+			model_verification_status = CASE WHEN ` + summaryIsEmpty + ` THEN EXCLUDED.model_verification_status ELSE news.model_verification_status END,
+			image_link = COALESCE(NULLIF(news.image_link, ''), EXCLUDED.image_link),
+			meta_description = COALESCE(NULLIF(news.meta_description, ''), EXCLUDED.meta_description),
+			meta_keywords = COALESCE(NULLIF(news.meta_keywords, ''), EXCLUDED.meta_keywords),
+			language = COALESCE(NULLIF(news.language, ''), EXCLUDED.language),
+			published_at = COALESCE(news.published_at, EXCLUDED.published_at),
+			modified_at = COALESCE(news.modified_at, EXCLUDED.modified_at)
+		WHERE (` + summaryIsEmpty + ` AND EXCLUDED.summary <> '')
+			OR (COALESCE(news.image_link, '') = '' AND EXCLUDED.image_link <> '')
+			OR (COALESCE(news.meta_description, '') = '' AND EXCLUDED.meta_description <> '')
+			OR (COALESCE(news.meta_keywords, '') = '' AND EXCLUDED.meta_keywords <> '')
+			OR (COALESCE(news.language, '') = '' AND EXCLUDED.language <> '')
+			OR (news.published_at IS NULL AND EXCLUDED.published_at IS NOT NULL)
+			OR (news.modified_at IS NULL AND EXCLUDED.modified_at IS NOT NULL)`,
+	dto.MERGE_REPLACE: `
+		ON CONFLICT (source_link) DO UPDATE
+		SET
+			category = EXCLUDED.category,
+			headline = EXCLUDED.headline,
+			story = EXCLUDED.story,
+			summary = CASE WHEN EXCLUDED.summary <> '' OR ` + storyChanged + ` THEN EXCLUDED.summary ELSE news.summary END,
+			bullet_points = CASE WHEN EXCLUDED.summary <> '' OR ` + storyChanged + ` THEN EXCLUDED.bullet_points ELSE news.bullet_points END,
+			summary_method = CASE WHEN ` + storyChanged + ` THEN EXCLUDED.summary_method ELSE news.summary_method END,
+			consistency_score = CASE WHEN ` + storyChanged + ` THEN EXCLUDED.consistency_score ELSE news.consistency_score END,
+			unsupported_claims = CASE WHEN ` + storyChanged + ` THEN EXCLUDED.unsupported_claims ELSE news.unsupported_claims END,
+			simhash = CASE WHEN ` + storyChanged + ` THEN EXCLUDED.simhash ELSE news.simhash END,
+			image_link = COALESCE(NULLIF(EXCLUDED.image_link, ''), news.image_link),
+			meta_description = COALESCE(NULLIF(EXCLUDED.meta_description, ''), news.meta_description),
+			meta_keywords = COALESCE(NULLIF(EXCLUDED.meta_keywords, ''), news.meta_keywords),
+			language = COALESCE(NULLIF(EXCLUDED.language, ''), news.language),
+			verification_status = COALESCE(NULLIF(EXCLUDED.verification_status, ''), news.verification_status),
+			content_hash = EXCLUDED.content_hash,
+			revision = EXCLUDED.revision,
+			published_at = COALESCE(EXCLUDED.published_at, news.published_at),
+			modified_at = COALESCE(EXCLUDED.modified_at, news.modified_at)
+		WHERE (news.category, news.headline, news.story, news.content_hash, news.revision) IS DISTINCT FROM
+			(EXCLUDED.category, EXCLUDED.headline, EXCLUDED.story, EXCLUDED.content_hash, EXCLUDED.revision)
+			OR (EXCLUDED.summary <> '' AND news.summary IS DISTINCT FROM EXCLUDED.summary)
+			OR (EXCLUDED.image_link <> '' AND news.image_link IS DISTINCT FROM EXCLUDED.image_link)
+			OR (EXCLUDED.meta_description <> '' AND news.meta_description IS DISTINCT FROM EXCLUDED.meta_description)
+			OR (EXCLUDED.meta_keywords <> '' AND news.meta_keywords IS DISTINCT FROM EXCLUDED.meta_keywords)
+			OR (EXCLUDED.language <> '' AND news.language IS DISTINCT FROM EXCLUDED.language)
+			OR (EXCLUDED.verification_status <> '' AND news.verification_status IS DISTINCT FROM EXCLUDED.verification_status)
+			OR (EXCLUDED.published_at IS NOT NULL AND news.published_at IS DISTINCT FROM EXCLUDED.published_at)
+			OR (EXCLUDED.modified_at IS NOT NULL AND news.modified_at IS DISTINCT FROM EXCLUDED.modified_at)`,
+}
+
+// upsertNews upserts the news stories with one multi-row statement
+func upsertNews(tx pgx.Tx, newsStories []dto.News, conflictClause string) (dto.UpsertResult, error) {
+	// Create values string for bulk insert
+	valueStrings := make([]string, 0, len(newsStories))
+	valueArgs := make([]interface{}, 0, len(newsStories)*newsInsertColumns)
 
-			valueArgs = append(valueArgs,
-				news.SourceName,
-				news.Category,
-				news.Headline,
-				news.Story,
-				news.ImageLink,
-				news.SourceLink,
-				news.MetaDescription,
-				news.Summary,
-				bulletPoints, // pgx will automatically handle the array conversion
-				news.MetaKeywords,
-			)
+	for i, news := range newsStories {
+		base := i * newsInsertColumns
+		placeholders := make([]string, newsInsertColumns)
+		for j := range placeholders {
+			placeholders[j] = fmt.Sprintf("$%d", base+j+1)
 		}
+		valueStrings = append(valueStrings, "("+strings.Join(placeholders, ", ")+")")
 
-		query := fmt.Sprintf(`
-			INSERT INTO news (
-				source_name, 
-				category, 
-				headline, 
-				story,
-				image_link, 
-				source_link, 
-				meta_description,
-				summary,
-				bullet_points,
-				meta_keywords
-			) VALUES %s`,
-			strings.Join(valueStrings, ","))
-
-		_, err := tx.Exec(context.Background(), query, valueArgs...)
-		if err != nil {
-			return fmt.Errorf("error executing bulk insert: %w", err)
+		// Convert BulletPoints to a proper PostgreSQL array if it's nil
+		bulletPoints := news.BulletPoints
+		if bulletPoints == nil {
+			bulletPoints = []string{} // Convert nil to empty array for PostgreSQL
+		}
+		unsupportedClaims := news.UnsupportedClaims
+		if unsupportedClaims == nil {
+			unsupportedClaims = []string{}
+		}
+		quarantineReasons := news.QuarantineReasons
+		if quarantineReasons == nil {
+			quarantineReasons = []string{}
+		}
+		disputedFacts := news.DisputedFacts
+		if disputedFacts == nil {
+			disputedFacts = []string{}
+		}
+		crawledAt := news.CrawledAt
+		if crawledAt.IsZero() {
+			crawledAt = time.Now()
+		}
+
+		valueArgs = append(valueArgs,
+			news.SourceName,
+			news.Category,
+			news.Headline,
+			news.Story,
+			news.ImageLink,
+			news.SourceLink,
+			news.MetaDescription,
+			news.Summary,
+			bulletPoints, // pgx will automatically handle the array conversion
+			news.MetaKeywords,
+			news.Language,
+			news.SummaryMethod,
+			news.ConsistencyScore,
+			unsupportedClaims,
+			news.Quarantined,
+			quarantineReasons,
+			news.VerificationStatus,
+			news.ModelVerificationStatus,
+			news.CorroboratingSources,
+			disputedFacts,
+			news.Fingerprint,
+			news.DuplicateOf,
+			news.DuplicateSimilarity,
+			news.ContentHash,
+			max(news.Revision, 1),
+			nullableTime(news.ModifiedAt),
+			nullableTime(news.PublishedAt),
+			crawledAt.UTC(),
+		)
+	}
+
+	// xmax is 0 for the inserted rows and set for the updated ones
+	query := fmt.Sprintf(`
+		INSERT INTO news (
+			source_name, 
+			category, 
+			headline, 
+			story,
+			image_link, 
+			source_link, 
+			meta_description,
+			summary,
+			bullet_points,
+			meta_keywords,
+			language,
+			summary_method,
+			consistency_score,
+			unsupported_claims,
+			quarantined,
+			quarantine_reasons,
+			verification_status,
+			model_verification_status,
+			corroborating_sources,
+			disputed_facts,
+			simhash,
+			duplicate_of,
+			duplicate_similarity,
+			content_hash,
+			revision,
+			modified_at,
+			published_at,
+			created_at
+		) VALUES %s
+		%s
+		RETURNING (xmax = 0)`,
+		strings.Join(valueStrings, ","), conflictClause)
+
+	rows, err := tx.Query(context.Background(), query, valueArgs...)
+	if err != nil {
+		return dto.UpsertResult{}, fmt.Errorf("error executing bulk upsert: %w", err)
+	}
+	defer rows.Close()
+
+	var result dto.UpsertResult
+	for rows.Next() {
+		var inserted bool
+		if err := rows.Scan(&inserted); err != nil {
+			return dto.UpsertResult{}, fmt.Errorf("error scanning row: %w", err)
+		}
+		if inserted {
+			result.Inserted++
+		} else {
+			result.Updated++
 		}
+	}
+	if err := rows.Err(); err != nil {
+		return dto.UpsertResult{}, fmt.Errorf("error executing bulk upsert: %w", err)
+	}
+	result.Skipped = len(newsStories) - result.Inserted - result.Updated
+
+	return result, nil
+}
 
+// nullableTime stores the zero time as NULL and the others in UTC
+func nullableTime(t time.Time) any {
+	if t.IsZero() {
 		return nil
-	})
+	}
+
+	return t.UTC()
+}
+
+// newsSelectColumns are the columns read into a dto.News by scanNews
+const newsSelectColumns = `
+	id::text,
+	source_name,
+	category,
+	headline,
+	story,
+	COALESCE(summary, ''),
+	COALESCE(bullet_points, '{}'),
+	COALESCE(image_link, ''),
+	source_link,
+	COALESCE(meta_description, ''),
+	COALESCE(meta_keywords, ''),
+	COALESCE(language, ''),
+	quarantined,
+	COALESCE(quarantine_reasons, '{}'),
+	COALESCE(verification_status, ''),
+	COALESCE(model_verification_status, ''),
+	COALESCE(corroborating_sources, 0),
+	COALESCE(disputed_facts, '{}'),
+	COALESCE(simhash, 0),
+	COALESCE(duplicate_of, ''),
+	COALESCE(duplicate_similarity, 0),
+	COALESCE(content_hash, ''),
+	COALESCE(revision, 1),
+	COALESCE(modified_at, '0001-01-01 00:00:00+00'),
+	COALESCE(published_at, '0001-01-01 00:00:00+00'),
+	created_at,
+	retracted,
+	COALESCE(retracted_at, '0001-01-01 00:00:00+00'),
+	COALESCE(retraction_reason, ''),
+	updated_at`
+
+// hideRetracted is the condition filtering out the retracted news stories
+// when HIDE_RETRACTED is set
+func hideRetracted(table string) string {
+	if !HIDE_RETRACTED {
+		return ""
+	}
+
+	return "AND NOT " + table + ".retracted"
+}
+
+// scanNews reads all the rows selected with newsSelectColumns
+func scanNews(rows pgx.Rows) ([]dto.News, error) {
+	defer rows.Close()
+
+	var newsStories []dto.News
+	for rows.Next() {
+		var news dto.News
+		err := rows.Scan(
+			&news.Id, &news.SourceName, &news.Category, &news.Headline,
+			&news.Story, &news.Summary, &news.BulletPoints, &news.ImageLink,
+			&news.SourceLink, &news.MetaDescription, &news.MetaKeywords,
+			&news.Language, &news.Quarantined, &news.QuarantineReasons,
+			&news.VerificationStatus, &news.ModelVerificationStatus, &news.CorroboratingSources, &news.DisputedFacts,
+			&news.Fingerprint, &news.DuplicateOf, &news.DuplicateSimilarity,
+			&news.ContentHash, &news.Revision, &news.ModifiedAt, &news.PublishedAt, &news.CrawledAt,
+			&news.Retracted, &news.RetractedAt, &news.RetractionReason, &news.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning row: %w", err)
+		}
+		newsStories = append(newsStories, news)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return newsStories, nil
+}
+
+// GetNews retrieves the latest news stories, optionally filtered by category
+func (s *newsStore) GetNews(category string, limit int) ([]dto.News, error) {
+	if limit == 0 {
+		limit = 20
+	}
+
+	query := `
+		SELECT ` + newsSelectColumns + `
+		FROM news
+		WHERE ($1 = '' OR category = $1)
+		AND NOT quarantined
+		` + hideRetracted("news") + `
+		ORDER BY COALESCE(published_at, created_at) DESC
+		LIMIT $2`
+
+	rows, err := s.dbPool.Query(context.Background(), query, category, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error querying news: %w", err)
+	}
+
+	return scanNews(rows)
+}
+
+// GetNewsByIDs retrieves the news stories with the given ids
+func (s *newsStore) GetNewsByIDs(ids []string) ([]dto.News, error) {
+	query := `
+		SELECT ` + newsSelectColumns + `
+		FROM news
+		WHERE id = ANY($1::bigint[])`
+
+	rows, err := s.dbPool.Query(context.Background(), query, ids)
+	if err != nil {
+		return nil, fmt.Errorf("error querying news by ids: %w", err)
+	}
+
+	return scanNews(rows)
+}
+
+// GetNewsByLinks retrieves the news stories with the given source links
+func (s *newsStore) GetNewsByLinks(sourceLinks []string) ([]dto.News, error) {
+	query := `
+		SELECT ` + newsSelectColumns + `
+		FROM news
+		WHERE source_link = ANY($1)`
+
+	rows, err := s.dbPool.Query(context.Background(), query, sourceLinks)
+	if err != nil {
+		return nil, fmt.Errorf("error querying news by links: %w", err)
+	}
+
+	return scanNews(rows)
+}
+
+// GetChangedNews retrieves the news stories changed after the given update
+// time and source link, in the order they changed. Quarantined and retracted
+// news stories are replicated too, with their flags.
+func (s *newsStore) GetChangedNews(after time.Time, afterLink string, limit int) ([]dto.News, error) {
+	query := `
+		SELECT ` + newsSelectColumns + `
+		FROM news
+		WHERE (updated_at, source_link) > ($1, $2)
+		ORDER BY updated_at, source_link
+		LIMIT $3`
+
+	rows, err := s.dbPool.Query(context.Background(), query, after.UTC(), afterLink, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error querying changed news: %w", err)
+	}
+
+	return scanNews(rows)
+}
+
+// pendingSummaryCondition selects the news stories without a summary that
+// are not part of an open AI batch nor stripped of their story, $1 is
+// closedBatchStatuses
+const pendingSummaryCondition = `
+		COALESCE(summary, '') = ''
+		AND story <> ''
+		AND NOT EXISTS (
+			SELECT 1
+			FROM ai_batch_items bi
+			JOIN ai_batches b ON b.id = bi.batch_id
+			WHERE bi.news_id = news.id
+			AND b.status <> ALL($1)
+		)`
+
+// GetPendingSummaries retrieves the oldest news stories without a summary
+// that are not part of an open AI batch
+func (s *newsStore) GetPendingSummaries(limit int) ([]dto.News, error) {
+	query := `
+		SELECT ` + newsSelectColumns + `
+		FROM news
+		WHERE ` + pendingSummaryCondition + `
+		ORDER BY created_at
+		LIMIT $2`
+
+	rows, err := s.dbPool.Query(context.Background(), query, closedBatchStatuses, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error querying pending summaries: %w", err)
+	}
+
+	return scanNews(rows)
+}
+
+// GetPendingSummariesByLinks retrieves the news stories with the given
+// source links that have no summary and are not part of an open AI batch
+func (s *newsStore) GetPendingSummariesByLinks(sourceLinks []string) ([]dto.News, error) {
+	query := `
+		SELECT ` + newsSelectColumns + `
+		FROM news
+		WHERE ` + pendingSummaryCondition + `
+		AND source_link = ANY($2)
+		ORDER BY created_at`
+
+	rows, err := s.dbPool.Query(context.Background(), query, closedBatchStatuses, sourceLinks)
+	if err != nil {
+		return nil, fmt.Errorf("error querying pending summaries: %w", err)
+	}
+
+	return scanNews(rows)
+}
+
+// UpdateSummary saves the AI generated fields of the news story
+func (s *newsStore) UpdateSummary(news dto.News) error {
+	bulletPoints := news.BulletPoints
+	if bulletPoints == nil {
+		bulletPoints = []string{}
+	}
+	unsupportedClaims := news.UnsupportedClaims
+	if unsupportedClaims == nil {
+		unsupportedClaims = []string{}
+	}
+	quarantineReasons := news.QuarantineReasons
+	if quarantineReasons == nil {
+		quarantineReasons = []string{}
+	}
+
+	query := `
+		UPDATE news
+		SET
+			summary = $2,
+			bullet_points = $3,
+			meta_keywords = $4,
+			summary_method = $5,
+			consistency_score = $6,
+			unsupported_claims = $7,
+			quarantined = $8,
+			quarantine_reasons = $9,
+			model_verification_status = $10
+		WHERE id = $1::bigint`
+
+	_, err := s.dbPool.Exec(context.Background(), query,
+		news.Id, news.Summary, bulletPoints, news.MetaKeywords, news.SummaryMethod, news.ConsistencyScore, unsupportedClaims,
+		news.Quarantined, quarantineReasons, news.ModelVerificationStatus)
+	if err != nil {
+		return fmt.Errorf("error updating summary of news %s: %w", news.Id, err)
+	}
+
+	return nil
+}
+
+// GetUntranslatedNews retrieves the latest news stories that are not written
+// in the target language and have no translation into it yet. The stories of
+// an undetermined language are skipped, they cannot be translated, and so are
+// the quarantined and unsummarized ones
+func (s *newsStore) GetUntranslatedNews(target string, limit int) ([]dto.News, error) {
+	query := `
+		SELECT ` + newsSelectColumns + `
+		FROM news
+		WHERE COALESCE(language, '') <> $1
+		AND COALESCE(language, '') <> $3
+		AND NOT quarantined
+		AND COALESCE(summary, '') <> ''
+		AND NOT EXISTS (
+			SELECT 1
+			FROM news_translations t
+			WHERE t.source_link = news.source_link
+			AND t.language = $1
+		)
+		ORDER BY created_at DESC
+		LIMIT $2`
+
+	rows, err := s.dbPool.Query(context.Background(), query, target, limit, lang.UNDEFINED)
+	if err != nil {
+		return nil, fmt.Errorf("error querying untranslated news: %w", err)
+	}
+
+	return scanNews(rows)
+}
+
+// UpdateLanguage saves the detected language of the news story
+func (s *newsStore) UpdateLanguage(sourceLink, language string) error {
+	_, err := s.dbPool.Exec(context.Background(),
+		`UPDATE news SET language = $2 WHERE source_link = $1`, sourceLink, language)
+	if err != nil {
+		return fmt.Errorf("error updating language of news %s: %w", sourceLink, err)
+	}
+
+	return nil
+}
+
+// GetQuarantined retrieves the latest news stories held for review
+func (s *newsStore) GetQuarantined(limit int) ([]dto.News, error) {
+	query := `
+		SELECT ` + newsSelectColumns + `
+		FROM news
+		WHERE quarantined
+		ORDER BY created_at DESC
+		LIMIT $1`
+
+	rows, err := s.dbPool.Query(context.Background(), query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error querying quarantined news: %w", err)
+	}
+
+	return scanNews(rows)
+}
+
+// ReleaseQuarantine publishes a reviewed news story, it reports whether the
+// story was quarantined
+func (s *newsStore) ReleaseQuarantine(sourceLink string) (bool, error) {
+	tag, err := s.dbPool.Exec(context.Background(),
+		`UPDATE news SET quarantined = false WHERE source_link = $1 AND quarantined`, sourceLink)
+	if err != nil {
+		return false, fmt.Errorf("error releasing news %s: %w", sourceLink, err)
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+// GetNewsSince retrieves the news stories crawled since the given time
+func (s *newsStore) GetNewsSince(since time.Time) ([]dto.News, error) {
+	query := `
+		SELECT ` + newsSelectColumns + `
+		FROM news
+		WHERE created_at >= $1
+		ORDER BY created_at`
+
+	rows, err := s.dbPool.Query(context.Background(), query, since)
+	if err != nil {
+		return nil, fmt.Errorf("error querying news since %s: %w", since, err)
+	}
+
+	return scanNews(rows)
+}
+
+// UpdateVerification saves the verification status computed for the news stories
+func (s *newsStore) UpdateVerification(sourceLinks []string, status string, sources int, disputedFacts []string) error {
+	if disputedFacts == nil {
+		disputedFacts = []string{}
+	}
+
+	query := `
+		UPDATE news
+		SET
+			verification_status = $2,
+			corroborating_sources = $3,
+			disputed_facts = $4
+		WHERE source_link = ANY($1)`
+
+	_, err := s.dbPool.Exec(context.Background(), query, sourceLinks, status, sources, disputedFacts)
+	if err != nil {
+		return fmt.Errorf("error updating verification status: %w", err)
+	}
+
+	return nil
+}
+
+// FindNearDuplicate retrieves the earliest original news story crawled since
+// the given time whose fingerprint is at most maxDistance bits away, nil
+// when there is none. The story at sourceLink, a re-crawl of the news itself,
+// is not a duplicate of it.
+func (s *newsStore) FindNearDuplicate(sourceLink string, fingerprint int64, since time.Time, maxDistance int) (*dto.News, error) {
+	query := `
+		SELECT ` + newsSelectColumns + `
+		FROM news
+		WHERE simhash IS NOT NULL
+		AND simhash <> 0
+		AND COALESCE(duplicate_of, '') = ''
+		AND created_at >= $2
+		AND bit_count((simhash # $1)::bit(64)) <= $3
+		AND source_link <> $4
+		ORDER BY bit_count((simhash # $1)::bit(64)), created_at
+		LIMIT 1`
+
+	rows, err := s.dbPool.Query(context.Background(), query, fingerprint, since, maxDistance, sourceLink)
+	if err != nil {
+		return nil, fmt.Errorf("error querying near duplicates: %w", err)
+	}
+
+	newsStories, err := scanNews(rows)
+	if err != nil || len(newsStories) == 0 {
+		return nil, err
+	}
+
+	return &newsStories[0], nil
+}
+
+// GetRecrawlCandidates retrieves the news stories crawled since the given
+// time and not checked for changes since checkedBefore, the least recently
+// checked first
+func (s *newsStore) GetRecrawlCandidates(since, checkedBefore time.Time, limit int) ([]dto.News, error) {
+	query := `
+		SELECT ` + newsSelectColumns + `
+		FROM news
+		WHERE created_at >= $1
+		AND COALESCE(last_checked_at, created_at) < $2
+		AND NOT quarantined
+		AND NOT retracted
+		ORDER BY COALESCE(last_checked_at, created_at)
+		LIMIT $3`
+
+	rows, err := s.dbPool.Query(context.Background(), query, since, checkedBefore, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error querying recrawl candidates: %w", err)
+	}
+
+	return scanNews(rows)
+}
+
+// MarkChecked records that the news stories were checked for changes
+func (s *newsStore) MarkChecked(sourceLinks []string) error {
+	_, err := s.dbPool.Exec(context.Background(),
+		`UPDATE news SET last_checked_at = now() WHERE source_link = ANY($1)`, sourceLinks)
+	if err != nil {
+		return fmt.Errorf("error marking news checked: %w", err)
+	}
+
+	return nil
+}
+
+// GetRetractionCandidates retrieves the news stories crawled since the given
+// time and not checked for retraction since checkedBefore, the least recently
+// checked first. Retracted stories are checked again to find the restored ones.
+func (s *newsStore) GetRetractionCandidates(since, checkedBefore time.Time, limit int) ([]dto.News, error) {
+	query := `
+		SELECT ` + newsSelectColumns + `
+		FROM news
+		WHERE created_at >= $1
+		AND COALESCE(retraction_checked_at, created_at) < $2
+		ORDER BY COALESCE(retraction_checked_at, created_at)
+		LIMIT $3`
+
+	rows, err := s.dbPool.Query(context.Background(), query, since, checkedBefore, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error querying retraction candidates: %w", err)
+	}
+
+	return scanNews(rows)
+}
+
+// UpdateRetraction flags the news story as retracted for the given reason, or
+// clears the flag, and reports whether the flag changed
+func (s *newsStore) UpdateRetraction(sourceLink string, retracted bool, reason string) (bool, error) {
+	query := `
+		UPDATE news
+		SET
+			retracted = $2::boolean,
+			retracted_at = CASE WHEN $2::boolean THEN now() END,
+			retraction_reason = CASE WHEN $2::boolean THEN $3::text END
+		WHERE source_link = $1
+		AND retracted <> $2`
+
+	tag, err := s.dbPool.Exec(context.Background(), query, sourceLink, retracted, reason)
+	if err != nil {
+		return false, fmt.Errorf("error updating retraction of %s: %w", sourceLink, err)
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+// MarkRetractionChecked records that the news stories were checked for retraction
+func (s *newsStore) MarkRetractionChecked(sourceLinks []string) error {
+	_, err := s.dbPool.Exec(context.Background(),
+		`UPDATE news SET retraction_checked_at = now() WHERE source_link = ANY($1)`, sourceLinks)
+	if err != nil {
+		return fmt.Errorf("error marking news checked for retraction: %w", err)
+	}
+
+	return nil
+}
+
+// GetRetracted retrieves the news stories retracted since the given time, the
+// latest first
+func (s *newsStore) GetRetracted(since time.Time, limit int) ([]dto.News, error) {
+	query := `
+		SELECT ` + newsSelectColumns + `
+		FROM news
+		WHERE retracted
+		AND retracted_at >= $1
+		ORDER BY retracted_at DESC
+		LIMIT $2`
+
+	rows, err := s.dbPool.Query(context.Background(), query, since, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error querying retracted news: %w", err)
+	}
+
+	return scanNews(rows)
 }
@@ -0,0 +1,167 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"ncrawler/db"
+	"ncrawler/internal/dto"
+	"ncrawler/internal/migrate"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// TEST_DB_URL_ENV names the variable holding the URL of a Postgres database
+// the tests may empty, the tests needing one are skipped without it
+const TEST_DB_URL_ENV = "NCRAWLER_TEST_PG_URL"
+
+// newTestPool connects to the test database, migrates it and empties the news
+func newTestPool(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+
+	url := os.Getenv(TEST_DB_URL_ENV)
+	if url == "" {
+		t.Skipf("%s is not set", TEST_DB_URL_ENV)
+	}
+
+	ctx := context.Background()
+	dbPool, err := pgxpool.New(ctx, url)
+	if err != nil {
+		t.Fatalf("error connecting to database: %v", err)
+	}
+	t.Cleanup(dbPool.Close)
+
+	migrator, err := migrate.New(&migrationStore{dbPool: dbPool}, db.Migrations, db.PG_MIGRATIONS)
+	if err != nil {
+		t.Fatalf("error loading migrations: %v", err)
+	}
+	if _, err := migrator.Up(); err != nil {
+		t.Fatalf("error migrating database: %v", err)
+	}
+
+	if _, err := dbPool.Exec(ctx, `TRUNCATE news, news_archive, news_tombstones RESTART IDENTITY CASCADE`); err != nil {
+		t.Fatalf("error emptying database: %v", err)
+	}
+
+	return dbPool
+}
+
+// testNews returns a summarized news story stored under the link
+func testNews(link string) dto.News {
+	return dto.News{
+		SourceName:      "bbc",
+		Category:        "world",
+		Headline:        "Floods hit the coast",
+		Story:           "Heavy rain flooded the coastal towns on Monday.",
+		Summary:         "Rain flooded the coast.",
+		BulletPoints:    []string{"Rain flooded the coast"},
+		ImageLink:       "https://example.com/flood.jpg",
+		SourceLink:      link,
+		MetaDescription: "Floods on the coast",
+		MetaKeywords:    "floods,weather",
+		Language:        "en",
+		ContentHash:     "hash-1",
+		Revision:        1,
+		PublishedAt:     time.Date(2026, 3, 2, 8, 0, 0, 0, time.UTC),
+		CrawledAt:       time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestAddNewsStories(t *testing.T) {
+	const link = "https://www.bbc.com/news/articles/floods"
+
+	changed := testNews(link)
+	changed.Headline = "Floods hit the coast, thousands moved"
+	changed.Story = "Heavy rain flooded the coastal towns, thousands were moved."
+	changed.ContentHash = "hash-2"
+
+	unsummarized := testNews(link)
+	unsummarized.Summary = ""
+	unsummarized.BulletPoints = nil
+
+	tests := []struct {
+		name     string
+		policy   string
+		stored   []dto.News
+		incoming []dto.News
+		want     dto.UpsertResult
+	}{
+		{"keep inserts new", dto.MERGE_KEEP, nil, []dto.News{testNews(link)}, dto.UpsertResult{Inserted: 1}},
+		{"keep skips stored", dto.MERGE_KEEP, []dto.News{testNews(link)}, []dto.News{changed}, dto.UpsertResult{Skipped: 1}},
+		{"keep stores repeated once", dto.MERGE_KEEP, nil, []dto.News{testNews(link), testNews(link)}, dto.UpsertResult{Inserted: 1, Skipped: 1}},
+		{"overwrite inserts new", dto.MERGE_OVERWRITE, nil, []dto.News{testNews(link)}, dto.UpsertResult{Inserted: 1}},
+		{"overwrite updates changed", dto.MERGE_OVERWRITE, []dto.News{testNews(link)}, []dto.News{changed}, dto.UpsertResult{Updated: 1}},
+		{"overwrite skips unchanged", dto.MERGE_OVERWRITE, []dto.News{testNews(link)}, []dto.News{testNews(link)}, dto.UpsertResult{Skipped: 1}},
+		{"fill empty inserts new", dto.MERGE_FILL_EMPTY, nil, []dto.News{testNews(link)}, dto.UpsertResult{Inserted: 1}},
+		{"fill empty sets missing summary", dto.MERGE_FILL_EMPTY, []dto.News{unsummarized}, []dto.News{testNews(link)}, dto.UpsertResult{Updated: 1}},
+		{"fill empty skips filled", dto.MERGE_FILL_EMPTY, []dto.News{testNews(link)}, []dto.News{changed}, dto.UpsertResult{Skipped: 1}},
+		{"fill empty skips nothing to fill", dto.MERGE_FILL_EMPTY, []dto.News{unsummarized}, []dto.News{unsummarized}, dto.UpsertResult{Skipped: 1}},
+		{"replace inserts new", dto.MERGE_REPLACE, nil, []dto.News{testNews(link)}, dto.UpsertResult{Inserted: 1}},
+		{"replace updates changed", dto.MERGE_REPLACE, []dto.News{testNews(link)}, []dto.News{changed}, dto.UpsertResult{Updated: 1}},
+		{"replace skips unchanged", dto.MERGE_REPLACE, []dto.News{testNews(link)}, []dto.News{testNews(link)}, dto.UpsertResult{Skipped: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := NewNewsStore(newTestPool(t))
+			if _, err := store.AddNewsStories(tt.stored, dto.MERGE_KEEP); err != nil {
+				t.Fatalf("error storing news: %v", err)
+			}
+
+			got, err := store.AddNewsStories(tt.incoming, tt.policy)
+			if err != nil {
+				t.Fatalf("AddNewsStories() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("AddNewsStories() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestAddNewsStoriesChunks upserts more stories than one statement can hold
+// under MAX_QUERY_PARAMS
+func TestAddNewsStoriesChunks(t *testing.T) {
+	store := NewNewsStore(newTestPool(t))
+
+	chunkSize := MAX_QUERY_PARAMS / newsInsertColumns
+	tests := []struct {
+		name  string
+		count int
+	}{
+		{"one full chunk", chunkSize},
+		{"one over a chunk", chunkSize + 1},
+		{"several chunks", 2*chunkSize + 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			newsStories := make([]dto.News, tt.count)
+			for i := range newsStories {
+				newsStories[i] = testNews(fmt.Sprintf("https://example.com/%s/%d", t.Name(), i))
+			}
+
+			got, err := store.AddNewsStories(newsStories, dto.MERGE_KEEP)
+			if err != nil {
+				t.Fatalf("AddNewsStories() error = %v", err)
+			}
+			if want := (dto.UpsertResult{Inserted: tt.count}); got != want {
+				t.Errorf("AddNewsStories() = %+v, want %+v", got, want)
+			}
+
+			for i := range newsStories {
+				newsStories[i].Headline += " updated"
+			}
+			got, err = store.AddNewsStories(newsStories, dto.MERGE_OVERWRITE)
+			if err != nil {
+				t.Fatalf("AddNewsStories() error = %v", err)
+			}
+			if want := (dto.UpsertResult{Updated: tt.count}); got != want {
+				t.Errorf("AddNewsStories() = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"ncrawler/internal/dto"
+)
+
+// likeEscaper escapes the wildcards of a LIKE pattern
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// QueryNews retrieves a page of the news stories matching the query, sorted
+// by time then source link so the next page starts after the cursor
+func (s *newsStore) QueryNews(q dto.NewsQuery) (dto.NewsPage, error) {
+	if err := q.Validate(); err != nil {
+		return dto.NewsPage{}, err
+	}
+
+	sortKey := "COALESCE(news.published_at, news.created_at)"
+	if q.Sort == dto.SORT_CRAWLED {
+		sortKey = "news.created_at"
+	}
+
+	var (
+		where = []string{"NOT news.quarantined"}
+		args  []any
+	)
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if len(q.Sources) > 0 {
+		where = append(where, "news.source_name = ANY("+arg(q.Sources)+")")
+	}
+	if len(q.Categories) > 0 {
+		where = append(where, "news.category = ANY("+arg(q.Categories)+")")
+	}
+	if !q.From.IsZero() {
+		where = append(where, sortKey+" >= "+arg(q.From.UTC()))
+	}
+	if !q.To.IsZero() {
+		where = append(where, sortKey+" < "+arg(q.To.UTC()))
+	}
+	if q.Language != "" {
+		where = append(where, "news.language = "+arg(q.Language))
+	}
+	if q.VerificationStatus != "" {
+		where = append(where, "news.verification_status = "+arg(q.VerificationStatus))
+	}
+	if q.Entity != "" {
+		entity := arg(q.Entity)
+		where = append(where, `news.source_link IN (
+			SELECT ne.source_link
+			FROM news_entities ne
+			JOIN entities e ON e.id = ne.entity_id
+			WHERE lower(e.name) = lower(`+entity+`)
+			OR lower(`+entity+`) = ANY(SELECT lower(a) FROM unnest(e.aliases) a))`)
+	}
+	if q.Text != "" {
+		headline := "news.headline ILIKE " + arg("%"+likeEscaper.Replace(q.Text)+"%")
+		if words := q.Words(); len(words) > 0 {
+			where = append(where, "(to_tsvector('simple', news.headline || ' ' || news.story) @@ to_tsquery('simple', "+
+				arg(strings.Join(words, " & "))+") OR "+headline+")")
+		} else {
+			where = append(where, headline)
+		}
+	}
+
+	order := "DESC"
+	if q.Ascending {
+		order = "ASC"
+	}
+	if q.Cursor != "" {
+		after, link, err := q.DecodeCursor()
+		if err != nil {
+			return dto.NewsPage{}, err
+		}
+		op := "<"
+		if q.Ascending {
+			op = ">"
+		}
+		where = append(where, fmt.Sprintf("(%s, news.source_link) %s (%s, %s)", sortKey, op, arg(after), arg(link)))
+	}
+
+	limit := q.PageLimit()
+	query := `
+		SELECT ` + newsSelectColumns + `
+		FROM news
+		WHERE ` + strings.Join(where, "\n\t\tAND ") + `
+		` + hideRetracted("news") + `
+		ORDER BY ` + sortKey + ` ` + order + `, news.source_link ` + order + `
+		LIMIT ` + arg(limit+1)
+
+	rows, err := s.dbPool.Query(context.Background(), query, args...)
+	if err != nil {
+		return dto.NewsPage{}, fmt.Errorf("error querying news: %w", err)
+	}
+
+	newsStories, err := scanNews(rows)
+	if err != nil {
+		return dto.NewsPage{}, err
+	}
+
+	page := dto.NewsPage{News: newsStories}
+	if len(newsStories) > limit {
+		page.News = newsStories[:limit]
+		page.NextCursor = q.NextCursor(page.News[limit-1])
+	}
+
+	return page, nil
+}
@@ -0,0 +1,154 @@
+package pg
+
+import (
+	"database/sql"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"ncrawler/db"
+	"ncrawler/internal/datastores/sqlite3"
+	"ncrawler/internal/definition"
+	"ncrawler/internal/dto"
+	"ncrawler/internal/migrate"
+)
+
+// queryTestNews are the stories the queries run on, b and c share their
+// publish time, d is sorted by its crawl time and f is quarantined
+func queryTestNews() []dto.News {
+	at := func(day, hour int) time.Time {
+		return time.Date(2026, 3, day, hour, 0, 0, 0, time.UTC)
+	}
+	story := func(link, source, category, language, headline, text string, published, crawled time.Time) dto.News {
+		return dto.News{
+			Id:          link,
+			SourceName:  source,
+			Category:    category,
+			Headline:    headline,
+			Story:       text,
+			SourceLink:  link,
+			Language:    language,
+			PublishedAt: published,
+			CrawledAt:   crawled,
+		}
+	}
+
+	quarantined := story("https://example.com/f", "cnn", "world", "en", "Storm reaches the islands", "The storm reached the islands.", at(5, 8), at(5, 9))
+	quarantined.Quarantined = true
+
+	return []dto.News{
+		story("https://example.com/a", "bbc", "world", "en", "Floods hit the coast", "Heavy rain hit the coastal towns.", at(1, 10), at(1, 11)),
+		story("https://example.com/b", "bbc", "sport", "en", "Cup final goes to penalties", "The final was decided on penalties.", at(2, 10), at(2, 10)),
+		story("https://example.com/c", "aljazeera", "world", "en", "Talks resume in Geneva", "Delegates met again in Geneva.", at(2, 10), at(2, 12)),
+		story("https://example.com/d", "aljazeera", "world", "ar", "Flood warning issued", "A warning was issued for the river.", time.Time{}, at(3, 9)),
+		story("https://example.com/e", "cnn", "business", "en", "Tech shares climb", "The markets rallied on Monday.", at(4, 8), at(4, 9)),
+		quarantined,
+		story("https://example.com/g", "bbc", "world", "en", "Bridge reopens after repairs", "The bridge reopened to traffic.", time.Date(2026, 2, 28, 8, 0, 0, 0, time.UTC), at(6, 9)),
+	}
+}
+
+// newTestSqlite opens a migrated sqlite3 datastore in a temporary file, it
+// is skipped when built without the sqlite_fts5 tag
+func newTestSqlite(t *testing.T) *sqlite3.Repositories {
+	t.Helper()
+
+	sqlDb, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "news.db"))
+	if err != nil {
+		t.Fatalf("error opening database: %v", err)
+	}
+	t.Cleanup(func() { sqlDb.Close() })
+
+	repos := sqlite3.NewRepositories(sqlDb)
+	migrator, err := migrate.New(&repos.Migrations, db.Migrations, db.SQLITE3_MIGRATIONS)
+	if err != nil {
+		t.Fatalf("error loading migrations: %v", err)
+	}
+	if _, err := migrator.Up(); err != nil {
+		if strings.Contains(err.Error(), "no such module: fts5") {
+			t.Skip("sqlite3 built without FTS5, run the tests with -tags sqlite_fts5")
+		}
+		t.Fatalf("error migrating database: %v", err)
+	}
+
+	return repos
+}
+
+// queryAll follows the cursor through every page of the query and returns the
+// stories in order
+func queryAll(t *testing.T, store definition.NewsStore, q dto.NewsQuery) []dto.News {
+	t.Helper()
+
+	var newsStories []dto.News
+	for range len(queryTestNews()) + 1 {
+		page, err := store.QueryNews(q)
+		if err != nil {
+			t.Fatalf("QueryNews() error = %v", err)
+		}
+		newsStories = append(newsStories, page.News...)
+		if page.NextCursor == "" {
+			return newsStories
+		}
+		q.Cursor = page.NextCursor
+	}
+
+	t.Fatal("QueryNews() did not reach the last page")
+	return nil
+}
+
+// TestQueryNewsParity runs the same queries on Postgres and sqlite3, both
+// must return the same stories in the same order on every page size
+func TestQueryNewsParity(t *testing.T) {
+	pgStore := NewNewsStore(newTestPool(t))
+	sqliteStore := &newTestSqlite(t).News
+	for _, store := range []definition.NewsStore{pgStore, sqliteStore} {
+		if _, err := store.AddNewsStories(queryTestNews(), dto.MERGE_KEEP); err != nil {
+			t.Fatalf("error storing news: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		query dto.NewsQuery
+	}{
+		{"newest first", dto.NewsQuery{}},
+		{"oldest first", dto.NewsQuery{Ascending: true}},
+		{"by crawl time", dto.NewsQuery{Sort: dto.SORT_CRAWLED}},
+		{"sources", dto.NewsQuery{Sources: []string{"bbc"}}},
+		{"categories", dto.NewsQuery{Categories: []string{"world", "business"}}},
+		{"time range", dto.NewsQuery{From: time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC), To: time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC)}},
+		{"language", dto.NewsQuery{Language: "ar"}},
+		{"headline part", dto.NewsQuery{Text: "flood"}},
+		{"story words", dto.NewsQuery{Text: "markets rallied"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, limit := range []int{1, 2, 0} {
+				q := tt.query
+				q.Limit = limit
+
+				pgNews := queryAll(t, pgStore, q)
+				sqliteNews := queryAll(t, sqliteStore, q)
+				if len(pgNews) == 0 {
+					t.Fatalf("QueryNews() with limit %d found no news", limit)
+				}
+				if !slices.EqualFunc(pgNews, sqliteNews, func(a, b dto.News) bool {
+					return a.SourceLink == b.SourceLink && q.SortTime(a).Equal(q.SortTime(b))
+				}) {
+					t.Errorf("QueryNews() with limit %d: pg = %v, sqlite3 = %v", limit, sourceLinks(pgNews), sourceLinks(sqliteNews))
+				}
+			}
+		})
+	}
+}
+
+func sourceLinks(newsStories []dto.News) []string {
+	links := make([]string, len(newsStories))
+	for i, news := range newsStories {
+		links[i] = news.SourceLink
+	}
+
+	return links
+}
@@ -6,11 +6,31 @@ import (
 
 type Repositories struct {
 	// repositories
-	News newsStore
+	News         newsStore
+	SummaryCache summaryCacheStore
+	AIUsage      aiUsageStore
+	SyncRuns     syncRunStore
+	AIBatches    aiBatchStore
+	Translations translationStore
+	Entities     entityStore
+	Clusters     clusterStore
+	Revisions    revisionStore
+	Backup       backupStore
+	Migrations   migrationStore
 }
 
 func NewRepositories(pool *pgxpool.Pool) *Repositories {
 	return &Repositories{
-		News: newsStore{dbPool: pool},
+		News:         newsStore{dbPool: pool},
+		SummaryCache: summaryCacheStore{dbPool: pool},
+		AIUsage:      aiUsageStore{dbPool: pool},
+		SyncRuns:     syncRunStore{dbPool: pool},
+		AIBatches:    aiBatchStore{dbPool: pool},
+		Translations: translationStore{dbPool: pool},
+		Entities:     entityStore{dbPool: pool},
+		Clusters:     clusterStore{dbPool: pool},
+		Revisions:    revisionStore{dbPool: pool},
+		Backup:       backupStore{dbPool: pool},
+		Migrations:   migrationStore{dbPool: pool},
 	}
 }
@@ -0,0 +1,225 @@
+package pg
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"ncrawler/internal/dto"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// GetSourceCategories retrieves the sources and categories of the stored
+// news with their number of stories
+func (s *newsStore) GetSourceCategories() ([]dto.SourceCategory, error) {
+	rows, err := s.dbPool.Query(context.Background(), `
+		SELECT source_name, category, count(*)
+		FROM news
+		GROUP BY source_name, category
+		ORDER BY source_name, category`)
+	if err != nil {
+		return nil, fmt.Errorf("error querying source categories: %w", err)
+	}
+	defer rows.Close()
+
+	var groups []dto.SourceCategory
+	for rows.Next() {
+		var g dto.SourceCategory
+		if err := rows.Scan(&g.Source, &g.Category, &g.Count); err != nil {
+			return nil, fmt.Errorf("error scanning row: %w", err)
+		}
+		groups = append(groups, g)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return groups, nil
+}
+
+// StripStories empties the story text of the news of the source and category
+// crawled from after to before, the zero after strips all the older news
+func (s *newsStore) StripStories(source, category string, after, before time.Time, dryRun bool) (int, error) {
+	condition := `
+		WHERE source_name = $1
+		AND category = $2
+		AND created_at < $3
+		AND ($4::timestamptz IS NULL OR created_at >= $4)
+		AND story <> ''`
+	args := []any{source, category, before.UTC(), nullableTime(after)}
+
+	if dryRun {
+		var count int
+		if err := s.dbPool.QueryRow(context.Background(), `SELECT count(*) FROM news `+condition, args...).Scan(&count); err != nil {
+			return 0, fmt.Errorf("error counting stories to strip: %w", err)
+		}
+		return count, nil
+	}
+
+	var stripped int
+	err := WrapInTx(context.Background(), s.dbPool, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(context.Background(), `UPDATE news SET story = '' `+condition, args...)
+		if err != nil {
+			return fmt.Errorf("error stripping stories: %w", err)
+		}
+		stripped = int(tag.RowsAffected())
+
+		return promoteDuplicates(tx)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return stripped, nil
+}
+
+// promoteDuplicates unlinks the near duplicates of the stories stripped of
+// their text or no longer stored, they hold the only copy of the text now
+func promoteDuplicates(tx pgx.Tx) error {
+	_, err := tx.Exec(context.Background(), `
+		UPDATE news n
+		SET duplicate_of = NULL, duplicate_similarity = NULL
+		WHERE COALESCE(n.duplicate_of, '') <> ''
+		AND NOT EXISTS (SELECT 1 FROM news o WHERE o.source_link = n.duplicate_of AND o.story <> '')`)
+	if err != nil {
+		return fmt.Errorf("error promoting near duplicates: %w", err)
+	}
+
+	return nil
+}
+
+// GetNewsCrawledBefore retrieves the news of the source and category crawled
+// before the time, quarantined and retracted ones too, in source link order
+func (s *newsStore) GetNewsCrawledBefore(source, category string, before time.Time, afterLink string, limit int) ([]dto.News, error) {
+	query := `
+		SELECT ` + newsSelectColumns + `
+		FROM news
+		WHERE source_name = $1
+		AND category = $2
+		AND created_at < $3
+		AND source_link > $4
+		ORDER BY source_link
+		LIMIT $5`
+
+	rows, err := s.dbPool.Query(context.Background(), query, source, category, before.UTC(), afterLink, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error querying news crawled before %s: %w", before.Format(time.DateOnly), err)
+	}
+
+	return scanNews(rows)
+}
+
+// ArchiveNews moves the news stories into news_archive as JSON and deletes
+// them with their revisions, entities, translations and cluster memberships
+func (s *newsStore) ArchiveNews(newsStories []dto.News) (int, error) {
+	if len(newsStories) == 0 {
+		return 0, nil
+	}
+
+	var archived int
+	err := WrapInTx(context.Background(), s.dbPool, func(tx pgx.Tx) error {
+		links := make([]string, 0, len(newsStories))
+		for _, news := range newsStories {
+			data, err := json.Marshal(news)
+			if err != nil {
+				return fmt.Errorf("error encoding news %s: %w", news.SourceLink, err)
+			}
+
+			_, err = tx.Exec(context.Background(), `
+				INSERT INTO news_archive (source_link, source_name, category, crawled_at, news)
+				VALUES ($1, $2, $3, $4, $5)
+				ON CONFLICT (source_link) DO UPDATE
+				SET source_name = excluded.source_name,
+					category = excluded.category,
+					crawled_at = excluded.crawled_at,
+					news = excluded.news,
+					archived_at = now()`,
+				news.SourceLink, news.SourceName, news.Category, nullableTime(news.CrawledAt), data)
+			if err != nil {
+				return fmt.Errorf("error archiving news %s: %w", news.SourceLink, err)
+			}
+			links = append(links, news.SourceLink)
+		}
+
+		tag, err := tx.Exec(context.Background(), `DELETE FROM news WHERE source_link = ANY($1)`, links)
+		if err != nil {
+			return fmt.Errorf("error deleting archived news: %w", err)
+		}
+		archived = int(tag.RowsAffected())
+
+		return promoteDuplicates(tx)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return archived, nil
+}
+
+// DeleteNews deletes the news stories with their revisions, entities,
+// translations and cluster memberships, their links are kept in
+// news_tombstones so they are not crawled again
+func (s *newsStore) DeleteNews(sourceLinks []string) (int, error) {
+	var deleted int
+	err := WrapInTx(context.Background(), s.dbPool, func(tx pgx.Tx) error {
+		_, err := tx.Exec(context.Background(), `
+			INSERT INTO news_tombstones (source_link)
+			SELECT unnest($1::text[])
+			ON CONFLICT (source_link) DO NOTHING`, sourceLinks)
+		if err != nil {
+			return fmt.Errorf("error saving tombstones of news: %w", err)
+		}
+
+		tag, err := tx.Exec(context.Background(), `DELETE FROM news WHERE source_link = ANY($1)`, sourceLinks)
+		if err != nil {
+			return fmt.Errorf("error deleting news: %w", err)
+		}
+		deleted = int(tag.RowsAffected())
+
+		return promoteDuplicates(tx)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return deleted, nil
+}
+
+// PruneRevisions purges all but the keep latest recorded revisions of the
+// news of the source and category
+func (s *newsStore) PruneRevisions(source, category string, keep int, dryRun bool) (int, error) {
+	ranked := `
+		WITH ranked AS (
+			SELECT r.source_link, r.revision,
+				row_number() OVER (PARTITION BY r.source_link ORDER BY r.revision DESC) AS rank
+			FROM article_revisions r
+			JOIN news n ON n.source_link = r.source_link
+			WHERE n.source_name = $1
+			AND n.category = $2
+		)`
+
+	if dryRun {
+		var count int
+		err := s.dbPool.QueryRow(context.Background(), ranked+`
+			SELECT count(*) FROM ranked WHERE rank > $3`, source, category, keep).Scan(&count)
+		if err != nil {
+			return 0, fmt.Errorf("error counting revisions to prune: %w", err)
+		}
+		return count, nil
+	}
+
+	tag, err := s.dbPool.Exec(context.Background(), ranked+`
+		DELETE FROM article_revisions r
+		USING ranked
+		WHERE r.source_link = ranked.source_link
+		AND r.revision = ranked.revision
+		AND ranked.rank > $3`, source, category, keep)
+	if err != nil {
+		return 0, fmt.Errorf("error pruning revisions: %w", err)
+	}
+
+	return int(tag.RowsAffected()), nil
+}
@@ -0,0 +1,124 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+
+	"ncrawler/internal/dto"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type revisionStore struct {
+	dbPool *pgxpool.Pool
+}
+
+// AddRevision records the revised version of the news story with its diff
+// and replaces the story with it. The stored version is recorded first when
+// it is the first revision of the story.
+func (s *revisionStore) AddRevision(old, revised dto.News, diff string) error {
+	return WrapInTx(context.Background(), s.dbPool, func(tx pgx.Tx) error {
+		if err := insertRevisions(tx, old, revised, diff); err != nil {
+			return err
+		}
+
+		_, err := tx.Exec(context.Background(), `
+			UPDATE news
+			SET
+				headline = $2,
+				story = $3,
+				meta_description = COALESCE(NULLIF($4, ''), meta_description),
+				image_link = COALESCE(NULLIF($5, ''), image_link),
+				content_hash = $6,
+				revision = $7,
+				modified_at = COALESCE($8, modified_at),
+				simhash = $9,
+				revised_at = now(),
+				last_checked_at = now()
+			WHERE source_link = $1`,
+			revised.SourceLink, revised.Headline, revised.Story, revised.MetaDescription, revised.ImageLink,
+			revised.ContentHash, revised.Revision, nullableTime(revised.ModifiedAt), revised.Fingerprint)
+		if err != nil {
+			return fmt.Errorf("error revising news %s: %w", revised.SourceLink, err)
+		}
+
+		return nil
+	})
+}
+
+// RecordRevision records the revised version of the news story with its diff
+// without touching the story, like when a replica already replaced it
+func (s *revisionStore) RecordRevision(old, revised dto.News, diff string) error {
+	return WrapInTx(context.Background(), s.dbPool, func(tx pgx.Tx) error {
+		return insertRevisions(tx, old, revised, diff)
+	})
+}
+
+// insertRevisions records the stored version of the news story, when it is
+// its first revision, and the revised one
+func insertRevisions(tx pgx.Tx, old, revised dto.News, diff string) error {
+	query := `
+		INSERT INTO article_revisions (
+			source_link,
+			revision,
+			headline,
+			story,
+			content_hash,
+			diff,
+			modified_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (source_link, revision) DO NOTHING`
+
+	_, err := tx.Exec(context.Background(), query,
+		old.SourceLink, max(old.Revision, 1), old.Headline, old.Story, old.ContentHash, "", nullableTime(old.ModifiedAt))
+	if err != nil {
+		return fmt.Errorf("error saving revision %d of %s: %w", old.Revision, old.SourceLink, err)
+	}
+
+	_, err = tx.Exec(context.Background(), query,
+		revised.SourceLink, revised.Revision, revised.Headline, revised.Story, revised.ContentHash, diff, nullableTime(revised.ModifiedAt))
+	if err != nil {
+		return fmt.Errorf("error saving revision %d of %s: %w", revised.Revision, revised.SourceLink, err)
+	}
+
+	return nil
+}
+
+// GetRevisions retrieves the recorded versions of the news story, the first first
+func (s *revisionStore) GetRevisions(sourceLink string) ([]dto.Revision, error) {
+	query := `
+		SELECT
+			source_link,
+			revision,
+			headline,
+			story,
+			content_hash,
+			COALESCE(diff, ''),
+			COALESCE(modified_at, '0001-01-01 00:00:00+00'),
+			created_at
+		FROM article_revisions
+		WHERE source_link = $1
+		ORDER BY revision`
+
+	rows, err := s.dbPool.Query(context.Background(), query, sourceLink)
+	if err != nil {
+		return nil, fmt.Errorf("error querying revisions of %s: %w", sourceLink, err)
+	}
+	defer rows.Close()
+
+	var revisions []dto.Revision
+	for rows.Next() {
+		var r dto.Revision
+		if err := rows.Scan(&r.SourceLink, &r.Revision, &r.Headline, &r.Story, &r.ContentHash, &r.Diff, &r.ModifiedAt, &r.CreatedAt); err != nil {
+			return nil, fmt.Errorf("error scanning row: %w", err)
+		}
+		revisions = append(revisions, r)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return revisions, nil
+}
@@ -0,0 +1,50 @@
+package pg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type summaryCacheStore struct {
+	dbPool *pgxpool.Pool
+}
+
+// GetSummary returns the cached response of the key, found is false on a miss
+func (s *summaryCacheStore) GetSummary(key string) (response []byte, found bool, err error) {
+	query := `
+		UPDATE ai_summary_cache
+		SET hits = hits + 1, last_hit_at = now()
+		WHERE cache_key = $1
+		RETURNING response::text`
+
+	var content string
+	err = s.dbPool.QueryRow(context.Background(), query, key).Scan(&content)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("error reading cached summary: %w", err)
+	}
+
+	return []byte(content), true, nil
+}
+
+// PutSummary stores the response under the key, replacing any previous one
+func (s *summaryCacheStore) PutSummary(key, model, promptVersion string, response []byte) error {
+	query := `
+		INSERT INTO ai_summary_cache (cache_key, model, prompt_version, response)
+		VALUES ($1, $2, $3, $4::jsonb)
+		ON CONFLICT (cache_key) DO UPDATE
+		SET response = EXCLUDED.response, created_at = now()`
+
+	_, err := s.dbPool.Exec(context.Background(), query, key, model, promptVersion, string(response))
+	if err != nil {
+		return fmt.Errorf("error caching summary: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,45 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+
+	"ncrawler/internal/dto"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type syncRunStore struct {
+	dbPool *pgxpool.Pool
+}
+
+// AddSyncRun records the counters of a finished sync run
+func (s *syncRunStore) AddSyncRun(r dto.SyncRun) error {
+	query := `
+		INSERT INTO sync_runs (
+			id,
+			started_at,
+			finished_at,
+			fetched,
+			summarized,
+			extractive,
+			failed,
+			inconsistent,
+			inserted,
+			quarantined,
+			cache_hits,
+			cache_misses,
+			prompt_tokens,
+			completion_tokens,
+			cost_usd
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)`
+
+	_, err := s.dbPool.Exec(context.Background(), query,
+		r.Id, r.StartedAt, r.FinishedAt, r.Fetched, r.Summarized, r.Extractive, r.Failed, r.Inconsistent, r.Inserted,
+		r.Quarantined, r.CacheHits, r.CacheMisses, r.PromptTokens, r.CompletionTokens, r.CostUSD)
+	if err != nil {
+		return fmt.Errorf("error recording sync run: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,56 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+
+	"ncrawler/internal/dto"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type translationStore struct {
+	dbPool *pgxpool.Pool
+}
+
+// AddTranslations saves the translations, replacing earlier translations of
+// the same news into the same language
+func (s *translationStore) AddTranslations(translations []dto.Translation) error {
+	if len(translations) == 0 {
+		return nil
+	}
+
+	query := `
+		INSERT INTO news_translations (
+			source_link,
+			language,
+			headline,
+			summary,
+			bullet_points,
+			model
+		) VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (source_link, language) DO UPDATE
+		SET
+			headline = EXCLUDED.headline,
+			summary = EXCLUDED.summary,
+			bullet_points = EXCLUDED.bullet_points,
+			model = EXCLUDED.model,
+			updated_at = now()`
+
+	return WrapInTx(context.Background(), s.dbPool, func(tx pgx.Tx) error {
+		for _, t := range translations {
+			bulletPoints := t.BulletPoints
+			if bulletPoints == nil {
+				bulletPoints = []string{}
+			}
+
+			_, err := tx.Exec(context.Background(), query, t.SourceLink, t.Language, t.Headline, t.Summary, bulletPoints, t.Model)
+			if err != nil {
+				return fmt.Errorf("error saving %s translation of %s: %w", t.Language, t.SourceLink, err)
+			}
+		}
+
+		return nil
+	})
+}
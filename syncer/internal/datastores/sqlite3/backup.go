@@ -0,0 +1,278 @@
+package sqlite3
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"ncrawler/internal/dto"
+)
+
+// backupStore reads and restores the tables of a backup, the local database
+// keeps no revisions, translations nor sync runs
+type backupStore struct {
+	db *sql.DB
+}
+
+// CountRows counts the rows of the table
+func (s *backupStore) CountRows(table string) (int, error) {
+	var count int
+	err := s.db.QueryRow(`SELECT count(*) FROM "` + strings.ReplaceAll(table, `"`, `""`) + `"`).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("error counting rows of %s: %w", table, err)
+	}
+
+	return count, nil
+}
+
+// GetNewsAfter retrieves the news stories after the source link in source
+// link order
+func (s *backupStore) GetNewsAfter(afterLink string, limit int) ([]dto.News, error) {
+	rows, err := s.db.Query(`SELECT `+newsSelectColumns+` FROM news WHERE source_link > ? ORDER BY source_link LIMIT ?`, afterLink, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error querying news: %w", err)
+	}
+
+	return scanNews(rows)
+}
+
+// GetNewsEntitiesByLinks retrieves the entities mentioned in the news stories
+func (s *backupStore) GetNewsEntitiesByLinks(sourceLinks []string) ([]dto.NewsEntity, error) {
+	if len(sourceLinks) == 0 {
+		return nil, nil
+	}
+
+	marks := make([]string, len(sourceLinks))
+	args := make([]any, len(sourceLinks))
+	for i, link := range sourceLinks {
+		marks[i] = "?"
+		args[i] = link
+	}
+
+	rows, err := s.db.Query(`
+		SELECT ne.source_link, e.name, e.type, e.aliases, ne.mentions
+		FROM news_entities ne
+		JOIN entities e ON e.id = ne.entity_id
+		WHERE ne.source_link IN (`+strings.Join(marks, ", ")+`)
+		ORDER BY ne.source_link, e.name, e.type`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error querying news entities: %w", err)
+	}
+	defer rows.Close()
+
+	var newsEntities []dto.NewsEntity
+	for rows.Next() {
+		var (
+			ne      dto.NewsEntity
+			aliases sql.NullString
+		)
+		if err := rows.Scan(&ne.SourceLink, &ne.Name, &ne.Type, &aliases, &ne.Mentions); err != nil {
+			return nil, fmt.Errorf("error scanning row: %w", err)
+		}
+		ne.Aliases = parseList(aliases)
+		newsEntities = append(newsEntities, ne)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return newsEntities, nil
+}
+
+// GetRevisionsByLinks returns no revisions, the local database keeps none
+func (s *backupStore) GetRevisionsByLinks(sourceLinks []string) ([]dto.Revision, error) {
+	return nil, nil
+}
+
+// GetTranslationsByLinks returns no translations, the local database keeps none
+func (s *backupStore) GetTranslationsByLinks(sourceLinks []string) ([]dto.Translation, error) {
+	return nil, nil
+}
+
+// GetArchivedNewsAfter retrieves the archived news stories after the source
+// link in source link order
+func (s *backupStore) GetArchivedNewsAfter(afterLink string, limit int) ([]dto.ArchivedNews, error) {
+	rows, err := s.db.Query(`SELECT news, archived_at FROM news_archive WHERE source_link > ? ORDER BY source_link LIMIT ?`, afterLink, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error querying archived news: %w", err)
+	}
+	defer rows.Close()
+
+	var archived []dto.ArchivedNews
+	for rows.Next() {
+		var (
+			a          dto.ArchivedNews
+			data       string
+			archivedAt sql.NullString
+		)
+		if err := rows.Scan(&data, &archivedAt); err != nil {
+			return nil, fmt.Errorf("error scanning row: %w", err)
+		}
+		if err := json.Unmarshal([]byte(data), &a.News); err != nil {
+			return nil, fmt.Errorf("error decoding archived news: %w", err)
+		}
+		a.ArchivedAt = parseTime(archivedAt)
+		archived = append(archived, a)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return archived, nil
+}
+
+// GetSyncRuns returns no sync runs, the local database keeps none
+func (s *backupStore) GetSyncRuns() ([]dto.SyncRun, error) {
+	return nil, nil
+}
+
+// GetClustersAfter returns no clusters, the local database keeps none
+func (s *backupStore) GetClustersAfter(afterID int64, limit int) ([]dto.StoryCluster, error) {
+	return nil, nil
+}
+
+// GetClusterMembersByLinks returns no cluster members, the local database keeps none
+func (s *backupStore) GetClusterMembersByLinks(sourceLinks []string) ([]dto.ClusterMember, error) {
+	return nil, nil
+}
+
+// GetTombstonesAfter retrieves the links of the deleted news stories after
+// the source link in source link order
+func (s *backupStore) GetTombstonesAfter(afterLink string, limit int) ([]dto.Tombstone, error) {
+	rows, err := s.db.Query(`SELECT source_link, deleted_at FROM news_tombstones WHERE source_link > ? ORDER BY source_link LIMIT ?`, afterLink, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error querying tombstones: %w", err)
+	}
+	defer rows.Close()
+
+	var tombstones []dto.Tombstone
+	for rows.Next() {
+		var (
+			t         dto.Tombstone
+			deletedAt sql.NullString
+		)
+		if err := rows.Scan(&t.SourceLink, &deletedAt); err != nil {
+			return nil, fmt.Errorf("error scanning row: %w", err)
+		}
+		t.DeletedAt = parseTime(deletedAt)
+		tombstones = append(tombstones, t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return tombstones, nil
+}
+
+// GetAIUsageAfter returns no AI usage, the local database keeps none
+func (s *backupStore) GetAIUsageAfter(afterID int64, limit int) ([]dto.AIUsage, error) {
+	return nil, nil
+}
+
+// RestoreNews inserts the news stories with their quarantine and retraction,
+// the stories already stored are left alone. The news are stored by their
+// source link like the crawler does.
+func (s *backupStore) RestoreNews(newsStories []dto.News) error {
+	restored := make([]dto.News, len(newsStories))
+	for i, news := range newsStories {
+		news.Id = news.SourceLink
+		restored[i] = news
+	}
+
+	store := newsStore{db: s.db}
+	_, err := store.AddNewsStories(restored, dto.MERGE_KEEP)
+
+	return err
+}
+
+// RestoreNewsEntities saves the entities mentioned in the news stories
+func (s *backupStore) RestoreNewsEntities(newsEntities []dto.NewsEntity) error {
+	store := entityStore{db: s.db}
+	return store.AddNewsEntities(newsEntities)
+}
+
+// RestoreRevisions fails, the local database keeps no revisions
+func (s *backupStore) RestoreRevisions(revisions []dto.Revision) error {
+	return fmt.Errorf("the sqlite3 datastore keeps no revisions")
+}
+
+// RestoreTranslations fails, the local database keeps no translations
+func (s *backupStore) RestoreTranslations(translations []dto.Translation) error {
+	return fmt.Errorf("the sqlite3 datastore keeps no translations")
+}
+
+// RestoreArchivedNews inserts the archived news stories
+func (s *backupStore) RestoreArchivedNews(archived []dto.ArchivedNews) error {
+	return WrapInTx(s.db, func(tx *sql.Tx) error {
+		for _, a := range archived {
+			data, err := json.Marshal(a.News)
+			if err != nil {
+				return fmt.Errorf("error encoding news %s: %w", a.News.SourceLink, err)
+			}
+
+			_, err = tx.Exec(`
+				INSERT INTO news_archive (source_link, source_name, category, crawled_at, archived_at, news) VALUES (?, ?, ?, ?, ?, ?)
+				ON CONFLICT (source_link) DO NOTHING`,
+				a.News.SourceLink, a.News.SourceName, a.News.Category, formatTime(a.News.CrawledAt), formatTime(a.ArchivedAt), string(data))
+			if err != nil {
+				return fmt.Errorf("error restoring archived news %s: %w", a.News.SourceLink, err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// RestoreSyncRuns fails, the local database keeps no sync runs
+func (s *backupStore) RestoreSyncRuns(runs []dto.SyncRun) error {
+	return fmt.Errorf("the sqlite3 datastore keeps no sync runs")
+}
+
+// RestoreClusters fails, the local database keeps no clusters
+func (s *backupStore) RestoreClusters(clusters []dto.StoryCluster) error {
+	return fmt.Errorf("the sqlite3 datastore keeps no story clusters")
+}
+
+// RestoreClusterMembers fails, the local database keeps no clusters
+func (s *backupStore) RestoreClusterMembers(members []dto.ClusterMember) error {
+	return fmt.Errorf("the sqlite3 datastore keeps no story clusters")
+}
+
+// RestoreTombstones inserts the links of the deleted news stories
+func (s *backupStore) RestoreTombstones(tombstones []dto.Tombstone) error {
+	return WrapInTx(s.db, func(tx *sql.Tx) error {
+		for _, t := range tombstones {
+			_, err := tx.Exec(`
+				INSERT INTO news_tombstones (source_link, deleted_at) VALUES (?, COALESCE(?, strftime('%Y-%m-%dT%H:%M:%SZ', 'now')))
+				ON CONFLICT (source_link) DO NOTHING`,
+				t.SourceLink, formatTime(t.DeletedAt))
+			if err != nil {
+				return fmt.Errorf("error restoring tombstone of %s: %w", t.SourceLink, err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// RestoreAIUsage fails, the local database keeps no AI usage
+func (s *backupStore) RestoreAIUsage(usage []dto.AIUsage) error {
+	return fmt.Errorf("the sqlite3 datastore keeps no ai usage")
+}
+
+// ClearTables deletes every row of the tables
+func (s *backupStore) ClearTables(tables []string) error {
+	return WrapInTx(s.db, func(tx *sql.Tx) error {
+		for _, table := range tables {
+			if _, err := tx.Exec(`DELETE FROM "` + strings.ReplaceAll(table, `"`, `""`) + `"`); err != nil {
+				return fmt.Errorf("error clearing %s: %w", table, err)
+			}
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,63 @@
+package sqlite3
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"ncrawler/internal/dto"
+)
+
+// checkpointStore keeps where the replication in each direction stopped, in
+// the local database
+type checkpointStore struct {
+	db *sql.DB
+}
+
+// GetCheckpoint returns the checkpoint of the replication, the zero
+// checkpoint when it never ran
+func (s *checkpointStore) GetCheckpoint(name string) (dto.Checkpoint, error) {
+	var updatedAt, replicatedAt string
+	cp := dto.Checkpoint{Name: name}
+	err := s.db.QueryRow(`SELECT updated_at, source_link, replicated_at FROM replication_checkpoints WHERE name = ?`, name).
+		Scan(&updatedAt, &cp.SourceLink, &replicatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return cp, nil
+	}
+	if err != nil {
+		return cp, fmt.Errorf("error querying checkpoint %s: %w", name, err)
+	}
+
+	// the checkpoint of Postgres keeps its microseconds
+	if cp.UpdatedAt, err = time.Parse(time.RFC3339Nano, updatedAt); err != nil {
+		return cp, fmt.Errorf("error parsing checkpoint %s: %w", name, err)
+	}
+	cp.ReplicatedAt = parseTime(sql.NullString{String: replicatedAt, Valid: true})
+
+	return cp, nil
+}
+
+// SaveCheckpoint saves the checkpoint of the replication
+func (s *checkpointStore) SaveCheckpoint(cp dto.Checkpoint) error {
+	_, err := s.db.Exec(`
+		INSERT INTO replication_checkpoints (name, updated_at, source_link, replicated_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT (name) DO UPDATE
+		SET updated_at = excluded.updated_at, source_link = excluded.source_link, replicated_at = excluded.replicated_at`,
+		cp.Name, cp.UpdatedAt.UTC().Format(time.RFC3339Nano), cp.SourceLink, formatTime(cp.ReplicatedAt))
+	if err != nil {
+		return fmt.Errorf("error saving checkpoint %s: %w", cp.Name, err)
+	}
+
+	return nil
+}
+
+// DeleteCheckpoint forgets the checkpoint so the replication starts over
+func (s *checkpointStore) DeleteCheckpoint(name string) error {
+	if _, err := s.db.Exec(`DELETE FROM replication_checkpoints WHERE name = ?`, name); err != nil {
+		return fmt.Errorf("error deleting checkpoint %s: %w", name, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,7 @@
+package sqlite3
+
+const (
+	// HIDE_RETRACTED hides the news stories retracted by their publisher from
+	// the queries and exports, like the Postgres datastore does
+	HIDE_RETRACTED = true
+)
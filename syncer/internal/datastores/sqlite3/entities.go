@@ -0,0 +1,55 @@
+package sqlite3
+
+import (
+	"database/sql"
+	"fmt"
+
+	"ncrawler/internal/dto"
+)
+
+type entityStore struct {
+	db *sql.DB
+}
+
+// AddNewsEntities saves the entities mentioned in news stories. The aliases
+// of an entity seen before are merged and the mentions of the same news
+// story are replaced.
+func (s *entityStore) AddNewsEntities(newsEntities []dto.NewsEntity) error {
+	if len(newsEntities) == 0 {
+		return nil
+	}
+
+	entityQuery := `
+		INSERT INTO entities (name, type, aliases)
+		VALUES (?1, ?2, ?3)
+		ON CONFLICT (name, type) DO UPDATE
+		SET aliases = (
+			SELECT json_group_array(DISTINCT value)
+			FROM (SELECT value FROM json_each(COALESCE(entities.aliases, '[]'))
+				UNION SELECT value FROM json_each(COALESCE(?3, '[]')))
+		)
+		RETURNING id`
+
+	mentionQuery := `
+		INSERT INTO news_entities (source_link, entity_id, mentions)
+		VALUES (?, ?, ?)
+		ON CONFLICT (source_link, entity_id) DO UPDATE
+		SET mentions = excluded.mentions`
+
+	return WrapInTx(s.db, func(tx *sql.Tx) error {
+		for _, ne := range newsEntities {
+			var id int64
+			err := tx.QueryRow(entityQuery, ne.Name, ne.Type, formatList(ne.Aliases)).Scan(&id)
+			if err != nil {
+				return fmt.Errorf("error saving entity %s: %w", ne.Name, err)
+			}
+
+			_, err = tx.Exec(mentionQuery, ne.SourceLink, id, ne.Mentions)
+			if err != nil {
+				return fmt.Errorf("error saving entity %s of %s: %w", ne.Name, ne.SourceLink, err)
+			}
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,89 @@
+package sqlite3
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// GetLinksAfter retrieves the source links of the news stories after the
+// source link, in source link order
+func (s *newsStore) GetLinksAfter(afterLink string, limit int) ([]string, error) {
+	rows, err := s.db.Query(`SELECT source_link FROM news WHERE source_link > ? ORDER BY source_link LIMIT ?`, afterLink, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error querying links: %w", err)
+	}
+	defer rows.Close()
+
+	var links []string
+	for rows.Next() {
+		var link string
+		if err := rows.Scan(&link); err != nil {
+			return nil, fmt.Errorf("error scanning row: %w", err)
+		}
+		links = append(links, link)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return links, nil
+}
+
+// CanonicalizeLink moves the news story stored under link, with its entities,
+// to the canonical link. When a story is already stored under the canonical
+// link, its empty fields are filled from the other story, which is then
+// deleted. It tells whether the stories were merged.
+func (s *newsStore) CanonicalizeLink(link, canonical string) (bool, error) {
+	merged := false
+
+	err := WrapInTx(s.db, func(tx *sql.Tx) error {
+		if err := tx.QueryRow(`SELECT EXISTS (SELECT 1 FROM news WHERE source_link = ?)`, canonical).Scan(&merged); err != nil {
+			return fmt.Errorf("error checking news %s: %w", canonical, err)
+		}
+
+		if !merged {
+			if _, err := tx.Exec(`UPDATE news SET id = ?2, source_link = ?2 WHERE source_link = ?1`, link, canonical); err != nil {
+				return fmt.Errorf("error moving news %s: %w", link, err)
+			}
+			if _, err := tx.Exec(`UPDATE news_entities SET source_link = ?2 WHERE source_link = ?1`, link, canonical); err != nil {
+				return fmt.Errorf("error moving entities of news %s: %w", link, err)
+			}
+			return nil
+		}
+
+		_, err := tx.Exec(`
+			UPDATE news
+			SET
+				summary = CASE WHEN COALESCE(news.summary, '') = '' THEN o.summary ELSE news.summary END,
+				bullet_points = CASE WHEN COALESCE(news.summary, '') = '' THEN o.bullet_points ELSE news.bullet_points END,
+				image_link = COALESCE(NULLIF(news.image_link, ''), o.image_link),
+				meta_description = COALESCE(NULLIF(news.meta_description, ''), o.meta_description),
+				meta_keywords = COALESCE(NULLIF(news.meta_keywords, ''), o.meta_keywords),
+				language = COALESCE(NULLIF(news.language, ''), o.language),
+				published_at = COALESCE(news.published_at, o.published_at),
+				modified_at = COALESCE(news.modified_at, o.modified_at),
+				crawled_at = COALESCE(MIN(news.crawled_at, o.crawled_at), news.crawled_at, o.crawled_at)
+			FROM news o
+			WHERE news.source_link = ?2 AND o.source_link = ?1`,
+			link, canonical)
+		if err != nil {
+			return fmt.Errorf("error merging news %s into %s: %w", link, canonical, err)
+		}
+		_, err = tx.Exec(`
+			UPDATE news_entities SET source_link = ?2 WHERE source_link = ?1
+			AND NOT EXISTS (SELECT 1 FROM news_entities c WHERE c.source_link = ?2 AND c.entity_id = news_entities.entity_id)`,
+			link, canonical)
+		if err != nil {
+			return fmt.Errorf("error merging entities of news %s: %w", link, err)
+		}
+		_, err = deleteNews(tx, []string{link})
+
+		return err
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return merged, nil
+}
@@ -0,0 +1,69 @@
+package sqlite3
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// migrationStore keeps the applied schema version, it implements migrate.Driver
+type migrationStore struct {
+	db *sql.DB
+}
+
+func (s *migrationStore) ensureTable() error {
+	_, err := s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS schema_migrations (version uint64, dirty bool);
+		CREATE UNIQUE INDEX IF NOT EXISTS version_unique ON schema_migrations (version);`)
+	if err != nil {
+		return fmt.Errorf("error creating schema_migrations: %w", err)
+	}
+
+	return nil
+}
+
+// Version returns the applied schema version, 0 when none
+func (s *migrationStore) Version() (uint, bool, error) {
+	if err := s.ensureTable(); err != nil {
+		return 0, false, err
+	}
+
+	var (
+		version int64
+		dirty   bool
+	)
+	err := s.db.QueryRow(`SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&version, &dirty)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("error querying schema version: %w", err)
+	}
+
+	return uint(version), dirty, nil
+}
+
+// Apply runs the migration script and records the version in one transaction
+func (s *migrationStore) Apply(script string, version uint) error {
+	if err := s.ensureTable(); err != nil {
+		return err
+	}
+
+	return WrapInTx(s.db, func(tx *sql.Tx) error {
+		if _, err := tx.Exec(script); err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(`DELETE FROM schema_migrations`); err != nil {
+			return fmt.Errorf("error clearing schema version: %w", err)
+		}
+		if version == 0 {
+			return nil
+		}
+		if _, err := tx.Exec(`INSERT INTO schema_migrations (version, dirty) VALUES (?, false)`, int64(version)); err != nil {
+			return fmt.Errorf("error saving schema version: %w", err)
+		}
+
+		return nil
+	})
+}
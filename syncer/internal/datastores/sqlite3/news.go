@@ -2,8 +2,11 @@ package sqlite3
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"log/slog"
+	"strings"
+	"time"
 
 	"ncrawler/internal/dto"
 )
@@ -13,26 +16,156 @@ type newsStore struct {
 }
 
 const (
-	insertNewsQuery = `INSERT INTO news (id, source_name, category, headline, story, published_at, image_link, source_link, meta_description, meta_keywords) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	insertNewsQuery = `INSERT INTO news (id, source_name, category, headline, story, summary, bullet_points, language, verification_status, published_at, modified_at, crawled_at, image_link, source_link, meta_description, meta_keywords, content_hash, revision, quarantined, quarantine_reasons, retracted, retracted_at, retraction_reason) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	// timeLayout stores the times as UTC text that sorts chronologically
+	timeLayout = "2006-01-02T15:04:05Z"
+	// updatedLayout is the layout of updated_at, written by the triggers
+	// with milliseconds
+	updatedLayout = "2006-01-02T15:04:05.000Z"
 )
 
-// GetNews retrieves all news stories from the database
+// formatTime formats the time in UTC, the zero time is stored as NULL
+func formatTime(t time.Time) sql.NullString {
+	if t.IsZero() {
+		return sql.NullString{}
+	}
+
+	return sql.NullString{String: t.UTC().Format(timeLayout), Valid: true}
+}
+
+// formatList stores a list as a JSON array
+func formatList(list []string) sql.NullString {
+	if len(list) == 0 {
+		return sql.NullString{}
+	}
+	b, err := json.Marshal(list)
+	if err != nil {
+		return sql.NullString{}
+	}
+
+	return sql.NullString{String: string(b), Valid: true}
+}
+
+// parseList reads a list stored as a JSON array
+func parseList(s sql.NullString) []string {
+	var list []string
+	if s.Valid {
+		_ = json.Unmarshal([]byte(s.String), &list)
+	}
+
+	return list
+}
+
+// parseTime reads a stored time, rows stored before the times were typed may
+// hold RFC 3339 times with an offset
+func parseTime(s sql.NullString) time.Time {
+	if !s.Valid {
+		return time.Time{}
+	}
+	for _, layout := range []string{timeLayout, time.RFC3339, time.DateTime} {
+		if t, err := time.Parse(layout, s.String); err == nil {
+			return t.UTC()
+		}
+	}
+
+	return time.Time{}
+}
+
+// newsSelectColumns are the columns read into a dto.News by scanNews
+const newsSelectColumns = `id, source_name, category, headline, story, summary, bullet_points, language, verification_status, published_at, modified_at, crawled_at, image_link, source_link, meta_description, meta_keywords, content_hash, revision, updated_at, quarantined, quarantine_reasons, retracted, retracted_at, retraction_reason`
+
+// scanNews reads all the rows selected with newsSelectColumns
+func scanNews(rows *sql.Rows) ([]dto.News, error) {
+	defer rows.Close()
+
+	var (
+		id                 sql.NullString
+		sourceName         sql.NullString
+		category           sql.NullString
+		headline           sql.NullString
+		story              sql.NullString
+		summary            sql.NullString
+		bulletPoints       sql.NullString
+		language           sql.NullString
+		verificationStatus sql.NullString
+		publishedAt        sql.NullString
+		modifiedAt         sql.NullString
+		crawledAt          sql.NullString
+		imageLink          sql.NullString
+		sourceLink         sql.NullString
+		metaDescription    sql.NullString
+		metaKeywords       sql.NullString
+		contentHash        sql.NullString
+		revision           sql.NullInt64
+		updatedAt          sql.NullString
+		quarantined        sql.NullBool
+		quarantineReasons  sql.NullString
+		retracted          sql.NullBool
+		retractedAt        sql.NullString
+		retractionReason   sql.NullString
+	)
+
+	newsStories := []dto.News{}
+	for rows.Next() {
+		if err := rows.Scan(&id, &sourceName, &category, &headline, &story, &summary, &bulletPoints, &language, &verificationStatus, &publishedAt, &modifiedAt, &crawledAt, &imageLink, &sourceLink, &metaDescription, &metaKeywords, &contentHash, &revision, &updatedAt,
+			&quarantined, &quarantineReasons, &retracted, &retractedAt, &retractionReason); err != nil {
+			slog.Error("Error scanning options", "cause", err)
+			return nil, err
+		}
+
+		newsStories = append(newsStories, dto.News{
+			Id:                 id.String,
+			SourceName:         sourceName.String,
+			Category:           category.String,
+			Headline:           headline.String,
+			Story:              story.String,
+			Summary:            summary.String,
+			BulletPoints:       parseList(bulletPoints),
+			Language:           language.String,
+			VerificationStatus: verificationStatus.String,
+			PublishedAt:        parseTime(publishedAt),
+			ModifiedAt:         parseTime(modifiedAt),
+			CrawledAt:          parseTime(crawledAt),
+			ImageLink:          imageLink.String,
+			SourceLink:         sourceLink.String,
+			MetaDescription:    metaDescription.String,
+			MetaKeywords:       metaKeywords.String,
+			ContentHash:        contentHash.String,
+			Revision:           int(revision.Int64),
+			UpdatedAt:          parseTime(updatedAt),
+			Quarantined:        quarantined.Bool,
+			QuarantineReasons:  parseList(quarantineReasons),
+			Retracted:          retracted.Bool,
+			RetractedAt:        parseTime(retractedAt),
+			RetractionReason:   retractionReason.String,
+		})
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return newsStories, nil
+}
+
+// hideRetracted is the condition hiding the retracted news stories when
+// HIDE_RETRACTED is set
+func hideRetracted() string {
+	if !HIDE_RETRACTED {
+		return ""
+	}
+
+	return "AND NOT retracted"
+}
+
+// GetNews retrieves the latest news stories, the quarantined and retracted
+// ones are hidden
 func (s *newsStore) GetNews(ctgry string, limit int) ([]dto.News, error) {
 	var (
 		dbQuery string
 		rows    *sql.Rows
 		err     error
-
-		id              sql.NullString
-		sourceName      sql.NullString
-		category        sql.NullString
-		headline        sql.NullString
-		story           sql.NullString
-		publishedAt     sql.NullString
-		imageLink       sql.NullString
-		sourceLink      sql.NullString
-		metaDescription sql.NullString
-		metaKeywords    sql.NullString
 	)
 
 	if limit == 0 {
@@ -40,40 +173,95 @@ func (s *newsStore) GetNews(ctgry string, limit int) ([]dto.News, error) {
 	}
 
 	if ctgry == "" {
-		dbQuery = `SELECT id, source_name, category, headline, story, published_at, image_link, source_link, meta_description, meta_keywords FROM news ORDER BY published_at DESC LIMIT ?`
+		dbQuery = `SELECT ` + newsSelectColumns + ` FROM news WHERE NOT quarantined ` + hideRetracted() + ` ORDER BY COALESCE(published_at, crawled_at) DESC LIMIT ?`
 		rows, err = s.db.Query(dbQuery, limit)
 	} else {
-		dbQuery = `SELECT id, source_name, category, headline, story, published_at, image_link, source_link, meta_description, meta_keywords FROM news WHERE category = ? ORDER BY published_at DESC LIMIT ?`
+		dbQuery = `SELECT ` + newsSelectColumns + ` FROM news WHERE category = ? AND NOT quarantined ` + hideRetracted() + ` ORDER BY COALESCE(published_at, crawled_at) DESC LIMIT ?`
 		rows, err = s.db.Query(dbQuery, ctgry, limit)
 	}
 	if err != nil {
 		slog.Error("Error querying options", "cause", err)
 		return nil, err
 	}
-	defer rows.Close()
 
-	newsStories := []dto.News{}
-	for rows.Next() {
-		if err := rows.Scan(&id, &sourceName, &category, &headline, &story, &publishedAt, &imageLink, &sourceLink, &metaDescription, &metaKeywords); err != nil {
-			slog.Error("Error scanning options", "cause", err)
-			return nil, err
+	return scanNews(rows)
+}
+
+// GetNewsByLinks retrieves the news stories with the given source links
+func (s *newsStore) GetNewsByLinks(sourceLinks []string) ([]dto.News, error) {
+	if len(sourceLinks) == 0 {
+		return nil, nil
+	}
+
+	marks := make([]string, len(sourceLinks))
+	args := make([]any, len(sourceLinks))
+	for i, link := range sourceLinks {
+		marks[i] = "?"
+		args[i] = link
+	}
+
+	rows, err := s.db.Query(`SELECT `+newsSelectColumns+` FROM news WHERE source_link IN (`+strings.Join(marks, ", ")+`)`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error querying news by links: %w", err)
+	}
+
+	return scanNews(rows)
+}
+
+// GetChangedNews retrieves the news stories changed after the given update
+// time and source link, in the order they changed, quarantined and retracted
+// ones too
+func (s *newsStore) GetChangedNews(after time.Time, afterLink string, limit int) ([]dto.News, error) {
+	var updated string
+	if !after.IsZero() {
+		updated = after.UTC().Format(updatedLayout)
+	}
+
+	dbQuery := `SELECT ` + newsSelectColumns + ` FROM news WHERE (updated_at, source_link) > (?, ?) ORDER BY updated_at, source_link LIMIT ?`
+	rows, err := s.db.Query(dbQuery, updated, afterLink, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error querying changed news: %w", err)
+	}
+
+	return scanNews(rows)
+}
+
+// ReplicateFlags sets the quarantine and retraction of the stored news
+// stories to the ones of the given stories, which Postgres decides. It
+// returns the number of news stories changed.
+func (s *newsStore) ReplicateFlags(newsStories []dto.News) (int, error) {
+	query := `
+		UPDATE news
+		SET
+			quarantined = ?2,
+			quarantine_reasons = ?3,
+			retracted = ?4,
+			retracted_at = ?5,
+			retraction_reason = ?6
+		WHERE source_link = ?1
+		AND (quarantined IS NOT ?2 OR quarantine_reasons IS NOT ?3 OR retracted IS NOT ?4
+			OR retracted_at IS NOT ?5 OR retraction_reason IS NOT ?6)`
+
+	changed := 0
+	err := WrapInTx(s.db, func(tx *sql.Tx) error {
+		for _, news := range newsStories {
+			res, err := tx.Exec(query, news.SourceLink, news.Quarantined, formatList(news.QuarantineReasons),
+				news.Retracted, formatTime(news.RetractedAt), news.RetractionReason)
+			if err != nil {
+				return fmt.Errorf("error replicating flags of %s: %w", news.SourceLink, err)
+			}
+			if n, _ := res.RowsAffected(); n > 0 {
+				changed++
+			}
 		}
 
-		newsStories = append(newsStories, dto.News{
-			Id:              id.String,
-			SourceName:      sourceName.String,
-			Category:        category.String,
-			Headline:        headline.String,
-			Story:           story.String,
-			Date:            publishedAt.String,
-			ImageLink:       imageLink.String,
-			SourceLink:      sourceLink.String,
-			MetaDescription: metaDescription.String,
-			MetaKeywords:    metaKeywords.String,
-		})
+		return nil
+	})
+	if err != nil {
+		return 0, err
 	}
 
-	return newsStories, nil
+	return changed, nil
 }
 
 // AddNewsStories adds news stories to the database
@@ -81,9 +269,12 @@ func (s *newsStore) DetectNewLinks(foundedLinks []string) ([]string, error) {
 	var newLinks []string
 	err := WrapInTx(s.db, func(tx *sql.Tx) error {
 		for _, link := range foundedLinks {
-			// Check if the news story already exists based on the ID (link)
+			// Check if the news story already exists based on the ID (link),
+			// or was archived or deleted
 			var exists bool
-			err := tx.QueryRow("SELECT EXISTS(SELECT 1 FROM news WHERE id = ?)", link).Scan(&exists)
+			err := tx.QueryRow(`SELECT EXISTS(SELECT 1 FROM news WHERE id = ?1)
+				OR EXISTS(SELECT 1 FROM news_archive WHERE source_link = ?1)
+				OR EXISTS(SELECT 1 FROM news_tombstones WHERE source_link = ?1)`, link).Scan(&exists)
 			if err != nil {
 				return fmt.Errorf("error checking existence of news ID %s: %w", link, err)
 			}
@@ -99,31 +290,139 @@ func (s *newsStore) DetectNewLinks(foundedLinks []string) ([]string, error) {
 	return newLinks, err
 }
 
-// AddNewsStories adds news stories to the database
-func (s *newsStore) AddNewsStories(newsStories []dto.News) (int, error) {
-	var newNewsCount int
+// newsMergeQueries merge a stored news story according to the merge policy,
+// they only change the rows that differ so the others count as skipped
+var newsMergeQueries = map[string]string{
+	dto.MERGE_OVERWRITE: `
+		UPDATE news
+		SET
+			category = ?2,
+			headline = ?3,
+			story = ?4,
+			image_link = ?5,
+			meta_description = ?6,
+			published_at = COALESCE(?7, published_at),
+			modified_at = COALESCE(?8, modified_at),
+			content_hash = ?9
+		WHERE id = ?1
+		AND (category IS NOT ?2 OR headline IS NOT ?3 OR story IS NOT ?4 OR image_link IS NOT ?5
+			OR meta_description IS NOT ?6
+			OR (?7 IS NOT NULL AND published_at IS NOT ?7)
+			OR (?8 IS NOT NULL AND modified_at IS NOT ?8))`,
+	dto.MERGE_FILL_EMPTY: `
+		UPDATE news
+		SET
+			summary = CASE WHEN COALESCE(summary, '') = '' THEN ?2 ELSE summary END,
+			bullet_points = CASE WHEN COALESCE(summary, '') = '' THEN ?3 ELSE bullet_points END,
+			quarantined = CASE WHEN COALESCE(summary, '') = '' THEN MAX(quarantined, ?10) ELSE quarantined END,
+			quarantine_reasons = CASE WHEN COALESCE(summary, '') = '' AND ?10 THEN (
+				SELECT json_group_array(value) FROM (
+					SELECT value FROM json_each(COALESCE(quarantine_reasons, '[]'))
+					UNION SELECT value FROM json_each(COALESCE(?11, '[]'))
+				)
+			) ELSE quarantine_reasons END,
+			image_link = COALESCE(NULLIF(image_link, ''), ?4),
+			meta_description = COALESCE(NULLIF(meta_description, ''), ?5),
+			meta_keywords = COALESCE(NULLIF(meta_keywords, ''), ?6),
+			language = COALESCE(NULLIF(language, ''), ?7),
+			published_at = COALESCE(published_at, ?8),
+			modified_at = COALESCE(modified_at, ?9)
+		WHERE id = ?1
+		AND ((COALESCE(summary, '') = '' AND COALESCE(?2, '') <> '')
+			OR (COALESCE(image_link, '') = '' AND COALESCE(?4, '') <> '')
+			OR (COALESCE(meta_description, '') = '' AND COALESCE(?5, '') <> '')
+			OR (COALESCE(meta_keywords, '') = '' AND COALESCE(?6, '') <> '')
+			OR (COALESCE(language, '') = '' AND COALESCE(?7, '') <> '')
+			OR (published_at IS NULL AND ?8 IS NOT NULL)
+			OR (modified_at IS NULL AND ?9 IS NOT NULL))`,
+	dto.MERGE_REPLACE: `
+		UPDATE news
+		SET
+			category = ?2,
+			headline = ?3,
+			story = ?4,
+			summary = CASE WHEN COALESCE(?5, '') <> '' OR headline IS NOT ?3 OR story IS NOT ?4 THEN ?5 ELSE summary END,
+			bullet_points = CASE WHEN COALESCE(?5, '') <> '' OR headline IS NOT ?3 OR story IS NOT ?4 THEN ?6 ELSE bullet_points END,
+			image_link = COALESCE(NULLIF(?7, ''), image_link),
+			meta_description = COALESCE(NULLIF(?8, ''), meta_description),
+			meta_keywords = COALESCE(NULLIF(?9, ''), meta_keywords),
+			language = COALESCE(NULLIF(?10, ''), language),
+			verification_status = COALESCE(NULLIF(?11, ''), verification_status),
+			content_hash = ?12,
+			revision = ?13,
+			published_at = COALESCE(?14, published_at),
+			modified_at = COALESCE(?15, modified_at)
+		WHERE id = ?1
+		AND (category IS NOT ?2 OR headline IS NOT ?3 OR story IS NOT ?4 OR content_hash IS NOT ?12 OR revision IS NOT ?13
+			OR (COALESCE(?5, '') <> '' AND summary IS NOT ?5)
+			OR (COALESCE(?7, '') <> '' AND image_link IS NOT ?7)
+			OR (COALESCE(?8, '') <> '' AND meta_description IS NOT ?8)
+			OR (COALESCE(?9, '') <> '' AND meta_keywords IS NOT ?9)
+			OR (COALESCE(?10, '') <> '' AND language IS NOT ?10)
+			OR (COALESCE(?11, '') <> '' AND verification_status IS NOT ?11)
+			OR (?14 IS NOT NULL AND published_at IS NOT ?14)
+			OR (?15 IS NOT NULL AND modified_at IS NOT ?15))`,
+}
+
+// AddNewsStories upserts the news stories by their id. A story already
+// stored is merged according to the policy, dto.MERGE_KEEP,
+// dto.MERGE_OVERWRITE or dto.MERGE_FILL_EMPTY.
+func (s *newsStore) AddNewsStories(newsStories []dto.News, policy string) (dto.UpsertResult, error) {
+	var result dto.UpsertResult
+	mergeQuery, ok := newsMergeQueries[policy]
+	if !ok && policy != dto.MERGE_KEEP {
+		return result, fmt.Errorf("unknown merge policy %s", policy)
+	}
+
 	err := WrapInTx(s.db, func(tx *sql.Tx) error {
 		for _, news := range newsStories {
-			// Check if the news story already exists based on the ID (link)
-			var exists bool
-			err := tx.QueryRow("SELECT EXISTS(SELECT 1 FROM news WHERE id = ?)", news.Id).Scan(&exists)
+			res, err := tx.Exec(insertNewsQuery+` ON CONFLICT(id) DO NOTHING`,
+				news.Id, news.SourceName, news.Category, news.Headline, news.Story, news.Summary, formatList(news.BulletPoints), news.Language, news.VerificationStatus,
+				formatTime(news.PublishedAt), formatTime(news.ModifiedAt), formatTime(news.CrawledAt), news.ImageLink, news.SourceLink, news.MetaDescription, news.MetaKeywords,
+				news.ContentHash, max(news.Revision, 1), news.Quarantined, formatList(news.QuarantineReasons), news.Retracted, formatTime(news.RetractedAt), news.RetractionReason)
 			if err != nil {
-				return fmt.Errorf("error checking existence of news ID %s: %w", news.Id, err)
+				return fmt.Errorf("error inserting news ID %s: %w", news.Id, err)
+			}
+			if n, _ := res.RowsAffected(); n > 0 {
+				result.Inserted++
+				continue
+			}
+			if policy == dto.MERGE_KEEP {
+				result.Skipped++
+				continue
 			}
 
-			// If the news story doesn't exist, insert it
-			if !exists {
-				_, err := tx.Exec(insertNewsQuery, news.Id, news.SourceName, news.Category, news.Headline, news.Story, news.Date, news.ImageLink, news.SourceLink, news.MetaDescription, news.MetaKeywords)
-				if err != nil {
-					return fmt.Errorf("error inserting news ID %s: %w", news.Id, err)
-				}
-				newNewsCount++
+			var args []any
+			switch policy {
+			case dto.MERGE_OVERWRITE:
+				args = []any{news.Id, news.Category, news.Headline, news.Story, news.ImageLink, news.MetaDescription,
+					formatTime(news.PublishedAt), formatTime(news.ModifiedAt), news.ContentHash}
+			case dto.MERGE_FILL_EMPTY:
+				args = []any{news.Id, news.Summary, formatList(news.BulletPoints), news.ImageLink, news.MetaDescription,
+					news.MetaKeywords, news.Language, formatTime(news.PublishedAt), formatTime(news.ModifiedAt),
+					news.Quarantined, formatList(news.QuarantineReasons)}
+			case dto.MERGE_REPLACE:
+				args = []any{news.Id, news.Category, news.Headline, news.Story, news.Summary, formatList(news.BulletPoints),
+					news.ImageLink, news.MetaDescription, news.MetaKeywords, news.Language, news.VerificationStatus,
+					news.ContentHash, max(news.Revision, 1), formatTime(news.PublishedAt), formatTime(news.ModifiedAt)}
+			}
+			res, err = tx.Exec(mergeQuery, args...)
+			if err != nil {
+				return fmt.Errorf("error merging news ID %s: %w", news.Id, err)
+			}
+			if n, _ := res.RowsAffected(); n > 0 {
+				result.Updated++
+			} else {
+				result.Skipped++
 			}
 		}
 		return nil
 	})
+	if err != nil {
+		return dto.UpsertResult{}, err
+	}
 
-	return newNewsCount, err
+	return result, nil
 }
 
 // GetCategoryList retrieves all unique categories from the database
@@ -0,0 +1,119 @@
+package sqlite3
+
+import (
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"ncrawler/db"
+	"ncrawler/internal/dto"
+	"ncrawler/internal/migrate"
+)
+
+// newTestDB opens a migrated database in a temporary file. The migrations
+// need FTS5, the tests are skipped when built without the sqlite_fts5 tag.
+func newTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	sqlDb, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "news.db"))
+	if err != nil {
+		t.Fatalf("error opening database: %v", err)
+	}
+	t.Cleanup(func() { sqlDb.Close() })
+
+	migrator, err := migrate.New(&migrationStore{db: sqlDb}, db.Migrations, db.SQLITE3_MIGRATIONS)
+	if err != nil {
+		t.Fatalf("error loading migrations: %v", err)
+	}
+	if _, err := migrator.Up(); err != nil {
+		if strings.Contains(err.Error(), "no such module: fts5") {
+			t.Skip("sqlite3 built without FTS5, run the tests with -tags sqlite_fts5")
+		}
+		t.Fatalf("error migrating database: %v", err)
+	}
+
+	return sqlDb
+}
+
+// testNews returns a summarized news story stored under the link
+func testNews(link string) dto.News {
+	return dto.News{
+		Id:              link,
+		SourceName:      "bbc",
+		Category:        "world",
+		Headline:        "Floods hit the coast",
+		Story:           "Heavy rain flooded the coastal towns on Monday.",
+		Summary:         "Rain flooded the coast.",
+		BulletPoints:    []string{"Rain flooded the coast"},
+		ImageLink:       "https://example.com/flood.jpg",
+		SourceLink:      link,
+		MetaDescription: "Floods on the coast",
+		MetaKeywords:    "floods,weather",
+		Language:        "en",
+		ContentHash:     "hash-1",
+		Revision:        1,
+		PublishedAt:     time.Date(2026, 3, 2, 8, 0, 0, 0, time.UTC),
+		CrawledAt:       time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestAddNewsStories(t *testing.T) {
+	const link = "https://www.bbc.com/news/articles/floods"
+
+	changed := testNews(link)
+	changed.Headline = "Floods hit the coast, thousands moved"
+	changed.Story = "Heavy rain flooded the coastal towns, thousands were moved."
+	changed.ContentHash = "hash-2"
+
+	unsummarized := testNews(link)
+	unsummarized.Summary = ""
+	unsummarized.BulletPoints = nil
+
+	tests := []struct {
+		name     string
+		policy   string
+		stored   []dto.News
+		incoming []dto.News
+		want     dto.UpsertResult
+	}{
+		{"keep inserts new", dto.MERGE_KEEP, nil, []dto.News{testNews(link)}, dto.UpsertResult{Inserted: 1}},
+		{"keep skips stored", dto.MERGE_KEEP, []dto.News{testNews(link)}, []dto.News{changed}, dto.UpsertResult{Skipped: 1}},
+		{"keep stores repeated once", dto.MERGE_KEEP, nil, []dto.News{testNews(link), testNews(link)}, dto.UpsertResult{Inserted: 1, Skipped: 1}},
+		{"overwrite inserts new", dto.MERGE_OVERWRITE, nil, []dto.News{testNews(link)}, dto.UpsertResult{Inserted: 1}},
+		{"overwrite updates changed", dto.MERGE_OVERWRITE, []dto.News{testNews(link)}, []dto.News{changed}, dto.UpsertResult{Updated: 1}},
+		{"overwrite skips unchanged", dto.MERGE_OVERWRITE, []dto.News{testNews(link)}, []dto.News{testNews(link)}, dto.UpsertResult{Skipped: 1}},
+		{"fill empty inserts new", dto.MERGE_FILL_EMPTY, nil, []dto.News{testNews(link)}, dto.UpsertResult{Inserted: 1}},
+		{"fill empty sets missing summary", dto.MERGE_FILL_EMPTY, []dto.News{unsummarized}, []dto.News{testNews(link)}, dto.UpsertResult{Updated: 1}},
+		{"fill empty skips filled", dto.MERGE_FILL_EMPTY, []dto.News{testNews(link)}, []dto.News{changed}, dto.UpsertResult{Skipped: 1}},
+		{"fill empty skips nothing to fill", dto.MERGE_FILL_EMPTY, []dto.News{unsummarized}, []dto.News{unsummarized}, dto.UpsertResult{Skipped: 1}},
+		{"replace inserts new", dto.MERGE_REPLACE, nil, []dto.News{testNews(link)}, dto.UpsertResult{Inserted: 1}},
+		{"replace updates changed", dto.MERGE_REPLACE, []dto.News{testNews(link)}, []dto.News{changed}, dto.UpsertResult{Updated: 1}},
+		{"replace skips unchanged", dto.MERGE_REPLACE, []dto.News{testNews(link)}, []dto.News{testNews(link)}, dto.UpsertResult{Skipped: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newsStore{db: newTestDB(t)}
+			if _, err := store.AddNewsStories(tt.stored, dto.MERGE_KEEP); err != nil {
+				t.Fatalf("error storing news: %v", err)
+			}
+
+			got, err := store.AddNewsStories(tt.incoming, tt.policy)
+			if err != nil {
+				t.Fatalf("AddNewsStories() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("AddNewsStories() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddNewsStoriesUnknownPolicy(t *testing.T) {
+	store := newsStore{db: newTestDB(t)}
+	if _, err := store.AddNewsStories([]dto.News{testNews("https://example.com/a")}, "merge"); err == nil {
+		t.Error("AddNewsStories() error = nil, want an unknown merge policy error")
+	}
+}
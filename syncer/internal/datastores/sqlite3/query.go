@@ -0,0 +1,120 @@
+package sqlite3
+
+import (
+	"fmt"
+	"strings"
+
+	"ncrawler/internal/dto"
+)
+
+// likeEscaper escapes the wildcards of a LIKE pattern
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// QueryNews retrieves a page of the news stories matching the query, sorted
+// by time then source link so the next page starts after the cursor. The
+// quarantined and retracted stories are hidden like on Postgres. The text is
+// matched with news_fts, which needs the sqlite_fts5 build tag.
+func (s *newsStore) QueryNews(q dto.NewsQuery) (dto.NewsPage, error) {
+	if err := q.Validate(); err != nil {
+		return dto.NewsPage{}, err
+	}
+
+	sortKey := "COALESCE(published_at, crawled_at, '')"
+	if q.Sort == dto.SORT_CRAWLED {
+		sortKey = "COALESCE(crawled_at, '')"
+	}
+
+	var (
+		where = []string{"NOT quarantined"}
+		args  []any
+	)
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("?%d", len(args))
+	}
+	in := func(column string, values []string) string {
+		marks := make([]string, len(values))
+		for i, v := range values {
+			marks[i] = arg(v)
+		}
+		return column + " IN (" + strings.Join(marks, ", ") + ")"
+	}
+
+	if HIDE_RETRACTED {
+		where = append(where, "NOT retracted")
+	}
+	if len(q.Sources) > 0 {
+		where = append(where, in("source_name", q.Sources))
+	}
+	if len(q.Categories) > 0 {
+		where = append(where, in("category", q.Categories))
+	}
+	if !q.From.IsZero() {
+		where = append(where, sortKey+" >= "+arg(formatTime(q.From).String))
+	}
+	if !q.To.IsZero() {
+		where = append(where, sortKey+" < "+arg(formatTime(q.To).String))
+	}
+	if q.Language != "" {
+		where = append(where, "language = "+arg(q.Language))
+	}
+	if q.VerificationStatus != "" {
+		where = append(where, "verification_status = "+arg(q.VerificationStatus))
+	}
+	if q.Entity != "" {
+		entity := arg(q.Entity)
+		where = append(where, `source_link IN (
+			SELECT ne.source_link
+			FROM news_entities ne
+			JOIN entities e ON e.id = ne.entity_id
+			WHERE lower(e.name) = lower(`+entity+`)
+			OR EXISTS (SELECT 1 FROM json_each(e.aliases) a WHERE lower(a.value) = lower(`+entity+`)))`)
+	}
+	if q.Text != "" {
+		headline := "headline LIKE " + arg("%"+likeEscaper.Replace(q.Text)+"%") + ` ESCAPE '\'`
+		if words := q.Words(); len(words) > 0 {
+			where = append(where, "(rowid IN (SELECT rowid FROM news_fts WHERE news_fts MATCH "+
+				arg(`"`+strings.Join(words, `" "`)+`"`)+") OR "+headline+")")
+		} else {
+			where = append(where, headline)
+		}
+	}
+
+	order := "DESC"
+	if q.Ascending {
+		order = "ASC"
+	}
+	if q.Cursor != "" {
+		after, link, err := q.DecodeCursor()
+		if err != nil {
+			return dto.NewsPage{}, err
+		}
+		op := "<"
+		if q.Ascending {
+			op = ">"
+		}
+		where = append(where, fmt.Sprintf("(%s, source_link) %s (%s, %s)", sortKey, op, arg(formatTime(after).String), arg(link)))
+	}
+
+	limit := q.PageLimit()
+	dbQuery := `SELECT ` + newsSelectColumns + ` FROM news WHERE ` + strings.Join(where, " AND ") +
+		` ORDER BY ` + sortKey + ` ` + order + `, source_link ` + order + ` LIMIT ` + arg(limit+1)
+
+	rows, err := s.db.Query(dbQuery, args...)
+	if err != nil {
+		return dto.NewsPage{}, fmt.Errorf("error querying news: %w", err)
+	}
+
+	newsStories, err := scanNews(rows)
+	if err != nil {
+		return dto.NewsPage{}, err
+	}
+
+	page := dto.NewsPage{News: newsStories}
+	if len(newsStories) > limit {
+		page.News = newsStories[:limit]
+		page.NextCursor = q.NextCursor(page.News[limit-1])
+	}
+
+	return page, nil
+}
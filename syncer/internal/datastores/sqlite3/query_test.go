@@ -0,0 +1,115 @@
+package sqlite3
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"ncrawler/internal/definition"
+	"ncrawler/internal/dto"
+)
+
+// queryTestNews are the stories the queries run on, b and c share their
+// publish time, d is sorted by its crawl time and f is quarantined
+func queryTestNews() []dto.News {
+	at := func(day, hour int) time.Time {
+		return time.Date(2026, 3, day, hour, 0, 0, 0, time.UTC)
+	}
+	story := func(link, source, category, language, headline, text string, published, crawled time.Time) dto.News {
+		return dto.News{
+			Id:          link,
+			SourceName:  source,
+			Category:    category,
+			Headline:    headline,
+			Story:       text,
+			SourceLink:  link,
+			Language:    language,
+			PublishedAt: published,
+			CrawledAt:   crawled,
+		}
+	}
+
+	quarantined := story("https://example.com/f", "cnn", "world", "en", "Storm reaches the islands", "The storm reached the islands.", at(5, 8), at(5, 9))
+	quarantined.Quarantined = true
+
+	return []dto.News{
+		story("https://example.com/a", "bbc", "world", "en", "Floods hit the coast", "Heavy rain hit the coastal towns.", at(1, 10), at(1, 11)),
+		story("https://example.com/b", "bbc", "sport", "en", "Cup final goes to penalties", "The final was decided on penalties.", at(2, 10), at(2, 10)),
+		story("https://example.com/c", "aljazeera", "world", "en", "Talks resume in Geneva", "Delegates met again in Geneva.", at(2, 10), at(2, 12)),
+		story("https://example.com/d", "aljazeera", "world", "ar", "Flood warning issued", "A warning was issued for the river.", time.Time{}, at(3, 9)),
+		story("https://example.com/e", "cnn", "business", "en", "Tech shares climb", "The markets rallied on Monday.", at(4, 8), at(4, 9)),
+		quarantined,
+		story("https://example.com/g", "bbc", "world", "en", "Bridge reopens after repairs", "The bridge reopened to traffic.", time.Date(2026, 2, 28, 8, 0, 0, 0, time.UTC), at(6, 9)),
+	}
+}
+
+// queryAll follows the cursor through every page of the query and returns the
+// source links in order
+func queryAll(t *testing.T, store definition.NewsStore, q dto.NewsQuery) []string {
+	t.Helper()
+
+	var links []string
+	for range len(queryTestNews()) + 1 {
+		page, err := store.QueryNews(q)
+		if err != nil {
+			t.Fatalf("QueryNews() error = %v", err)
+		}
+		if len(page.News) > q.PageLimit() {
+			t.Fatalf("QueryNews() returned %d stories, more than the limit %d", len(page.News), q.PageLimit())
+		}
+		for _, news := range page.News {
+			links = append(links, news.SourceLink)
+		}
+		if page.NextCursor == "" {
+			return links
+		}
+		q.Cursor = page.NextCursor
+	}
+
+	t.Fatal("QueryNews() did not reach the last page")
+	return nil
+}
+
+func TestQueryNews(t *testing.T) {
+	store := &newsStore{db: newTestDB(t)}
+	if _, err := store.AddNewsStories(queryTestNews(), dto.MERGE_KEEP); err != nil {
+		t.Fatalf("error storing news: %v", err)
+	}
+
+	link := func(names ...string) []string {
+		links := make([]string, len(names))
+		for i, name := range names {
+			links[i] = "https://example.com/" + name
+		}
+		return links
+	}
+
+	tests := []struct {
+		name  string
+		query dto.NewsQuery
+		want  []string
+	}{
+		{"newest first", dto.NewsQuery{}, link("e", "d", "c", "b", "a", "g")},
+		{"oldest first", dto.NewsQuery{Ascending: true}, link("g", "a", "b", "c", "d", "e")},
+		{"by crawl time", dto.NewsQuery{Sort: dto.SORT_CRAWLED}, link("g", "e", "d", "c", "b", "a")},
+		{"sources", dto.NewsQuery{Sources: []string{"bbc"}}, link("b", "a", "g")},
+		{"categories", dto.NewsQuery{Categories: []string{"world", "business"}}, link("e", "d", "c", "a", "g")},
+		{"time range", dto.NewsQuery{From: time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC), To: time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC)}, link("d", "c", "b")},
+		{"language", dto.NewsQuery{Language: "ar"}, link("d")},
+		{"headline part", dto.NewsQuery{Text: "flood"}, link("d", "a")},
+		{"story words", dto.NewsQuery{Text: "markets rallied"}, link("e")},
+		{"no match", dto.NewsQuery{Sources: []string{"reuters"}}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, limit := range []int{1, 2, 0} {
+				q := tt.query
+				q.Limit = limit
+				if got := queryAll(t, store, q); !slices.Equal(got, tt.want) {
+					t.Errorf("QueryNews() with limit %d = %v, want %v", limit, got, tt.want)
+				}
+			}
+		})
+	}
+}
@@ -4,11 +4,19 @@ import "database/sql"
 
 type Repositories struct {
 	// repositories
-	News newsStore
+	News        newsStore
+	Entities    entityStore
+	Checkpoints checkpointStore
+	Backup      backupStore
+	Migrations  migrationStore
 }
 
 func NewRepositories(sqlDb *sql.DB) *Repositories {
 	return &Repositories{
-		News: newsStore{db: sqlDb},
+		News:        newsStore{db: sqlDb},
+		Entities:    entityStore{db: sqlDb},
+		Checkpoints: checkpointStore{db: sqlDb},
+		Backup:      backupStore{db: sqlDb},
+		Migrations:  migrationStore{db: sqlDb},
 	}
 }
@@ -0,0 +1,167 @@
+package sqlite3
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"ncrawler/internal/dto"
+)
+
+// crawledColumn is the crawl time of the news, rows stored before it was
+// recorded fall back to their publish time
+const crawledColumn = `COALESCE(crawled_at, published_at)`
+
+// GetSourceCategories retrieves the sources and categories of the stored
+// news with their number of stories
+func (s *newsStore) GetSourceCategories() ([]dto.SourceCategory, error) {
+	rows, err := s.db.Query(`SELECT COALESCE(source_name, ''), COALESCE(category, ''), count(*) FROM news GROUP BY 1, 2 ORDER BY 1, 2`)
+	if err != nil {
+		return nil, fmt.Errorf("error querying source categories: %w", err)
+	}
+	defer rows.Close()
+
+	var groups []dto.SourceCategory
+	for rows.Next() {
+		var g dto.SourceCategory
+		if err := rows.Scan(&g.Source, &g.Category, &g.Count); err != nil {
+			return nil, fmt.Errorf("error scanning row: %w", err)
+		}
+		groups = append(groups, g)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return groups, nil
+}
+
+// StripStories empties the story text of the news of the source and category
+// crawled from after to before, the zero after strips all the older news
+func (s *newsStore) StripStories(source, category string, after, before time.Time, dryRun bool) (int, error) {
+	condition := ` WHERE COALESCE(source_name, '') = ?1 AND COALESCE(category, '') = ?2 AND ` + crawledColumn + ` < ?3
+		AND (?4 IS NULL OR ` + crawledColumn + ` >= ?4) AND COALESCE(story, '') <> ''`
+	args := []any{source, category, formatTime(before), formatTime(after)}
+
+	if dryRun {
+		var count int
+		if err := s.db.QueryRow(`SELECT count(*) FROM news`+condition, args...).Scan(&count); err != nil {
+			return 0, fmt.Errorf("error counting stories to strip: %w", err)
+		}
+		return count, nil
+	}
+
+	res, err := s.db.Exec(`UPDATE news SET story = ''`+condition, args...)
+	if err != nil {
+		return 0, fmt.Errorf("error stripping stories: %w", err)
+	}
+	n, _ := res.RowsAffected()
+
+	return int(n), nil
+}
+
+// GetNewsCrawledBefore retrieves the news of the source and category crawled
+// before the time, in source link order
+func (s *newsStore) GetNewsCrawledBefore(source, category string, before time.Time, afterLink string, limit int) ([]dto.News, error) {
+	dbQuery := `SELECT ` + newsSelectColumns + ` FROM news WHERE COALESCE(source_name, '') = ? AND COALESCE(category, '') = ?
+		AND ` + crawledColumn + ` < ? AND source_link > ? ORDER BY source_link LIMIT ?`
+
+	rows, err := s.db.Query(dbQuery, source, category, formatTime(before), afterLink, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error querying news crawled before %s: %w", before.Format(time.DateOnly), err)
+	}
+
+	return scanNews(rows)
+}
+
+// ArchiveNews moves the news stories into news_archive as JSON and deletes
+// them with their entities
+func (s *newsStore) ArchiveNews(newsStories []dto.News) (int, error) {
+	var archived int
+	err := WrapInTx(s.db, func(tx *sql.Tx) error {
+		links := make([]string, 0, len(newsStories))
+		for _, news := range newsStories {
+			data, err := json.Marshal(news)
+			if err != nil {
+				return fmt.Errorf("error encoding news %s: %w", news.SourceLink, err)
+			}
+
+			_, err = tx.Exec(`
+				INSERT INTO news_archive (source_link, source_name, category, crawled_at, news) VALUES (?, ?, ?, ?, ?)
+				ON CONFLICT (source_link) DO UPDATE
+				SET source_name = excluded.source_name, category = excluded.category, crawled_at = excluded.crawled_at,
+					news = excluded.news, archived_at = excluded.archived_at`,
+				news.SourceLink, news.SourceName, news.Category, formatTime(news.CrawledAt), string(data))
+			if err != nil {
+				return fmt.Errorf("error archiving news %s: %w", news.SourceLink, err)
+			}
+			links = append(links, news.SourceLink)
+		}
+
+		var err error
+		archived, err = deleteNews(tx, links)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return archived, nil
+}
+
+// DeleteNews deletes the news stories with their entities, their links are
+// kept in news_tombstones so they are not crawled again
+func (s *newsStore) DeleteNews(sourceLinks []string) (int, error) {
+	var deleted int
+	err := WrapInTx(s.db, func(tx *sql.Tx) error {
+		for _, link := range sourceLinks {
+			if _, err := tx.Exec(`INSERT INTO news_tombstones (source_link) VALUES (?) ON CONFLICT (source_link) DO NOTHING`, link); err != nil {
+				return fmt.Errorf("error saving tombstone of news %s: %w", link, err)
+			}
+		}
+
+		var err error
+		deleted, err = deleteNews(tx, sourceLinks)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return deleted, nil
+}
+
+// deleteNews deletes the news stories, news_entities has no foreign key to
+// the news so their rows are deleted first
+func deleteNews(tx *sql.Tx, sourceLinks []string) (int, error) {
+	if len(sourceLinks) == 0 {
+		return 0, nil
+	}
+
+	marks := make([]string, len(sourceLinks))
+	args := make([]any, len(sourceLinks))
+	for i, link := range sourceLinks {
+		marks[i] = "?"
+		args[i] = link
+	}
+	in := `(` + strings.Join(marks, ", ") + `)`
+
+	if _, err := tx.Exec(`DELETE FROM news_entities WHERE source_link IN `+in, args...); err != nil {
+		return 0, fmt.Errorf("error deleting entities of news: %w", err)
+	}
+	res, err := tx.Exec(`DELETE FROM news WHERE source_link IN `+in, args...)
+	if err != nil {
+		return 0, fmt.Errorf("error deleting news: %w", err)
+	}
+	n, _ := res.RowsAffected()
+
+	return int(n), nil
+}
+
+// PruneRevisions does nothing, the local database keeps no revisions
+func (s *newsStore) PruneRevisions(source, category string, keep int, dryRun bool) (int, error) {
+	return 0, nil
+}
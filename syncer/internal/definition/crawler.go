@@ -11,7 +11,9 @@ type Downloader interface {
 type Source interface {
 	// GetNewLatestLinks() ([]string, error)
 	GetLatest() (dto.NewsList, error)
-	// GetName() string
+	// GetNews fetches the articles of the given links again
+	GetNews(links []string) (dto.NewsList, error)
+	GetName() string
 
 	// Downloader implements the downloader for the store
 	Downloader
@@ -0,0 +1,78 @@
+package definition
+
+import (
+	"time"
+
+	"ncrawler/internal/dto"
+)
+
+// NewsStore stores the news stories, it is implemented by every datastore
+type NewsStore interface {
+	AddNewsStories(newsStories []dto.News, policy string) (dto.UpsertResult, error)
+	QueryNews(q dto.NewsQuery) (dto.NewsPage, error)
+	GetNewsByLinks(sourceLinks []string) ([]dto.News, error)
+	// GetChangedNews returns the stories changed after the update time and
+	// source link, in the order they changed
+	GetChangedNews(after time.Time, afterLink string, limit int) ([]dto.News, error)
+}
+
+// RetentionStore prunes the news stories past their retention, the news
+// crawled before a time of a source and category. With dryRun nothing is
+// changed and the count is what would be.
+type RetentionStore interface {
+	GetSourceCategories() ([]dto.SourceCategory, error)
+	// StripStories drops the story text of the news crawled from after, the
+	// zero time for all, to before
+	StripStories(source, category string, after, before time.Time, dryRun bool) (int, error)
+	// GetNewsCrawledBefore returns the news crawled before, in source link
+	// order after the source link
+	GetNewsCrawledBefore(source, category string, before time.Time, afterLink string, limit int) ([]dto.News, error)
+	// ArchiveNews moves the news stories into the news_archive table
+	ArchiveNews(newsStories []dto.News) (int, error)
+	DeleteNews(sourceLinks []string) (int, error)
+	// PruneRevisions purges all but the keep latest revisions of each story
+	PruneRevisions(source, category string, keep int, dryRun bool) (int, error)
+}
+
+// BackupStore reads the tables of a backup and restores them. The news are
+// read in source link order with the rows referencing them, a datastore
+// without a table reads it empty.
+type BackupStore interface {
+	CountRows(table string) (int, error)
+	GetNewsAfter(afterLink string, limit int) ([]dto.News, error)
+	GetNewsEntitiesByLinks(sourceLinks []string) ([]dto.NewsEntity, error)
+	GetRevisionsByLinks(sourceLinks []string) ([]dto.Revision, error)
+	GetTranslationsByLinks(sourceLinks []string) ([]dto.Translation, error)
+	GetArchivedNewsAfter(afterLink string, limit int) ([]dto.ArchivedNews, error)
+	GetSyncRuns() ([]dto.SyncRun, error)
+	GetClustersAfter(afterID int64, limit int) ([]dto.StoryCluster, error)
+	GetClusterMembersByLinks(sourceLinks []string) ([]dto.ClusterMember, error)
+	GetTombstonesAfter(afterLink string, limit int) ([]dto.Tombstone, error)
+	GetAIUsageAfter(afterID int64, limit int) ([]dto.AIUsage, error)
+
+	RestoreNews(newsStories []dto.News) error
+	RestoreNewsEntities(newsEntities []dto.NewsEntity) error
+	RestoreRevisions(revisions []dto.Revision) error
+	RestoreTranslations(translations []dto.Translation) error
+	RestoreArchivedNews(archived []dto.ArchivedNews) error
+	RestoreSyncRuns(runs []dto.SyncRun) error
+	RestoreClusters(clusters []dto.StoryCluster) error
+	RestoreClusterMembers(members []dto.ClusterMember) error
+	RestoreTombstones(tombstones []dto.Tombstone) error
+	RestoreAIUsage(usage []dto.AIUsage) error
+	// ClearTables deletes every row of the tables, like after a failed restore
+	ClearTables(tables []string) error
+}
+
+// LinkStore rewrites the source links of the news stories
+type LinkStore interface {
+	GetLinksAfter(afterLink string, limit int) ([]string, error)
+	// CanonicalizeLink moves the story of the link to the canonical link, a
+	// story already stored there is merged with it and it returns true
+	CanonicalizeLink(link, canonical string) (bool, error)
+}
+
+// EntityStore stores the entities mentioned in the news stories
+type EntityStore interface {
+	AddNewsEntities(newsEntities []dto.NewsEntity) error
+}
@@ -0,0 +1,63 @@
+package dto
+
+import "time"
+
+// AIUsage is the token usage and cost of one AI request
+type AIUsage struct {
+	Id    int64  `json:"id"`
+	RunID string `json:"run_id"`
+	// BatchID is the batch of a Batch API request, its usage is recorded once
+	BatchID          string    `json:"batch_id"`
+	SourceName       string    `json:"source_name"`
+	SourceLink       string    `json:"source_link"`
+	Model            string    `json:"model"`
+	PromptTokens     int       `json:"prompt_tokens"`
+	CompletionTokens int       `json:"completion_tokens"`
+	CostUSD          float64   `json:"cost_usd"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// AIUsageSummary is the usage aggregated over a source and day
+type AIUsageSummary struct {
+	Day              time.Time
+	SourceName       string
+	Requests         int
+	PromptTokens     int
+	CompletionTokens int
+	CostUSD          float64
+}
+
+// SyncRun holds the counters of one sync run
+type SyncRun struct {
+	Id               string    `json:"id"`
+	StartedAt        time.Time `json:"started_at"`
+	FinishedAt       time.Time `json:"finished_at"`
+	Fetched          int       `json:"fetched"`
+	Summarized       int       `json:"summarized"`
+	Extractive       int       `json:"extractive"`
+	Failed           int       `json:"failed"`
+	Inconsistent     int       `json:"inconsistent"`
+	Inserted         int       `json:"inserted"`
+	Quarantined      int       `json:"quarantined"`
+	CacheHits        int64     `json:"cache_hits"`
+	CacheMisses      int64     `json:"cache_misses"`
+	PromptTokens     int64     `json:"prompt_tokens"`
+	CompletionTokens int64     `json:"completion_tokens"`
+	CostUSD          float64   `json:"cost_usd"`
+}
+
+// AIBatch tracks a summarization batch submitted to the Batch API
+type AIBatch struct {
+	Id              string
+	ProviderBatchID string
+	InputFileID     string
+	OutputFileID    string
+	ErrorFileID     string
+	FilePath        string
+	Model           string
+	PromptVersion   string
+	Status          string
+	RequestCount    int
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
@@ -0,0 +1,27 @@
+package dto
+
+import "time"
+
+// StoryCluster groups the news of different sources about the same story
+type StoryCluster struct {
+	Id int64 `json:"id"`
+	// Headline and RepresentativeLink are of the news that opened the cluster
+	Headline           string    `json:"headline"`
+	RepresentativeLink string    `json:"representative_link"`
+	FirstSeen          time.Time `json:"first_seen"`
+	LastSeen           time.Time `json:"last_seen"`
+	Size               int       `json:"size"`
+	Sources            int       `json:"sources"`
+	// Centroid is the mean term frequency vector of the members
+	Centroid map[string]float64 `json:"centroid"`
+}
+
+// ClusterMember is a news story of a cluster with its similarity to the
+// cluster when it joined
+type ClusterMember struct {
+	ClusterId  int64     `json:"cluster_id"`
+	SourceLink string    `json:"source_link"`
+	SourceName string    `json:"source_name"`
+	Similarity float64   `json:"similarity"`
+	AddedAt    time.Time `json:"added_at"`
+}
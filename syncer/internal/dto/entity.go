@@ -0,0 +1,32 @@
+package dto
+
+import "time"
+
+const (
+	ENTITY_PERSON       = "person"
+	ENTITY_ORGANIZATION = "organization"
+	ENTITY_LOCATION     = "location"
+	ENTITY_EVENT        = "event"
+)
+
+// Entity is a person, organization, location or event under its canonical
+// name, Aliases are the other names it is mentioned by
+type Entity struct {
+	Name    string   `json:"name"`
+	Type    string   `json:"type"`
+	Aliases []string `json:"aliases,omitempty"`
+}
+
+// NewsEntity is an entity mentioned in a news story
+type NewsEntity struct {
+	SourceLink string `json:"source_link"`
+	Entity
+	Mentions int `json:"mentions"`
+}
+
+// EntityCount is the number of news stories mentioning an entity
+type EntityCount struct {
+	Entity
+	Articles int       `json:"articles"`
+	LastSeen time.Time `json:"last_seen"`
+}
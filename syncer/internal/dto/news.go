@@ -3,25 +3,84 @@ package dto
 import (
 	"regexp"
 	"strings"
+	"time"
+)
+
+const (
+	SUMMARY_METHOD_LLM        = "llm"
+	SUMMARY_METHOD_EXTRACTIVE = "extractive"
 )
 
 type News struct {
-	Id              string   `json:"id" csv:"id"`
-	SourceName      string   `json:"source_name" csv:"source_name"`
-	Category        string   `json:"category" csv:"category"`
-	Headline        string   `json:"headline" csv:"headline"`
-	Story           string   `json:"story" csv:"story"`
-	Summary         string   `json:"summary" csv:"summary"`
-	BulletPoints    []string `json:"bullet_points" csv:"bullet_points"`
-	Date            string   `json:"date" csv:"date"`
-	ImageLink       string   `json:"image_link" csv:"image_link"`
-	SourceLink      string   `json:"source_link" csv:"source_link"`
-	MetaDescription string   `json:"meta_description" csv:"meta_description"`
-	MetaKeywords    string   `json:"meta_keywords" csv:"meta_keywords"`
+	Id              string   `json:"id" csv:"id" parquet:"id"`
+	SourceName      string   `json:"source_name" csv:"source_name" parquet:"source_name"`
+	Category        string   `json:"category" csv:"category" parquet:"category"`
+	Headline        string   `json:"headline" csv:"headline" parquet:"headline"`
+	Story           string   `json:"story" csv:"story" parquet:"story"`
+	Summary         string   `json:"summary" csv:"summary" parquet:"summary"`
+	BulletPoints    []string `json:"bullet_points" csv:"bullet_points" parquet:"bullet_points,list"`
+	ImageLink       string   `json:"image_link" csv:"image_link" parquet:"image_link"`
+	SourceLink      string   `json:"source_link" csv:"source_link" parquet:"source_link"`
+	MetaDescription string   `json:"meta_description" csv:"meta_description" parquet:"meta_description"`
+	MetaKeywords    string   `json:"meta_keywords" csv:"meta_keywords" parquet:"meta_keywords"`
+
+	// Language is the ISO 639-1 code of the language of the story
+	Language string `json:"language" csv:"language" parquet:"language"`
+
+	// SummaryMethod tells whether the summary was written by the AI or extracted from the story
+	SummaryMethod string `json:"summary_method" csv:"summary_method" parquet:"summary_method"`
+	// ConsistencyScore is the share of the facts in the summary found in the story
+	ConsistencyScore  float64  `json:"consistency_score" csv:"consistency_score" parquet:"consistency_score"`
+	UnsupportedClaims []string `json:"unsupported_claims" csv:"unsupported_claims" parquet:"unsupported_claims,list"`
+
+	// VerificationStatus is computed from the sources reporting the same story,
+	// ModelVerificationStatus is the guess of the AI from the story alone
+	VerificationStatus      string   `json:"verification_status" csv:"verification_status" parquet:"verification_status"`
+	ModelVerificationStatus string   `json:"model_verification_status" csv:"model_verification_status" parquet:"model_verification_status"`
+	CorroboratingSources    int      `json:"corroborating_sources" csv:"corroborating_sources" parquet:"corroborating_sources"`
+	DisputedFacts           []string `json:"disputed_facts" csv:"disputed_facts" parquet:"disputed_facts,list"`
+
+	// Fingerprint is the SimHash of the story, DuplicateOf links a near
+	// duplicate to the source link of the earlier story it copies
+	Fingerprint         int64   `json:"fingerprint" csv:"fingerprint" parquet:"fingerprint"`
+	DuplicateOf         string  `json:"duplicate_of" csv:"duplicate_of" parquet:"duplicate_of"`
+	DuplicateSimilarity float64 `json:"duplicate_similarity" csv:"duplicate_similarity" parquet:"duplicate_similarity"`
+
+	// PublishedAt and ModifiedAt are when the publisher first published and
+	// last updated the story, CrawledAt is when it was crawled, all in UTC
+	PublishedAt time.Time `json:"published_at" csv:"published_at" parquet:"published_at,timestamp(millisecond)"`
+	ModifiedAt  time.Time `json:"modified_at" csv:"modified_at" parquet:"modified_at,timestamp(millisecond)"`
+	CrawledAt   time.Time `json:"crawled_at" csv:"crawled_at" parquet:"crawled_at,timestamp(millisecond)"`
+	// UpdatedAt is when the stored story last changed, replication tracks it
+	UpdatedAt time.Time `json:"updated_at" csv:"updated_at" parquet:"updated_at,timestamp(millisecond)"`
+
+	// ContentHash identifies the text of the story and Revision counts its versions
+	ContentHash string `json:"content_hash" csv:"content_hash" parquet:"content_hash"`
+	Revision    int    `json:"revision" csv:"revision" parquet:"revision"`
+
+	// Quarantined news look hostile to the summarizer and are hidden until reviewed
+	Quarantined       bool     `json:"quarantined" csv:"quarantined" parquet:"quarantined"`
+	QuarantineReasons []string `json:"quarantine_reasons" csv:"quarantine_reasons" parquet:"quarantine_reasons,list"`
+
+	// Retracted news were deleted or unpublished by their publisher
+	Retracted        bool      `json:"retracted" csv:"retracted" parquet:"retracted"`
+	RetractedAt      time.Time `json:"retracted_at" csv:"retracted_at" parquet:"retracted_at,timestamp(millisecond)"`
+	RetractionReason string    `json:"retraction_reason" csv:"retraction_reason" parquet:"retraction_reason"`
 }
 
 type NewsList []News
 
+// Translation is the headline, summary and bullet points of a news story in
+// another language than the story
+type Translation struct {
+	SourceLink   string   `json:"source_link" csv:"source_link"`
+	Language     string   `json:"language" csv:"language"`
+	Headline     string   `json:"headline" csv:"headline"`
+	Summary      string   `json:"summary" csv:"summary"`
+	BulletPoints []string `json:"bullet_points" csv:"bullet_points"`
+	Model        string   `json:"model" csv:"model"`
+}
+
 // sanitizeText cleans a single string by:
 // - Trimming leading and trailing spaces
 // - Replacing multiple spaces with a single space
@@ -52,6 +111,10 @@ func (nl NewsList) Sanitize() {
 		// Also sanitize URLs (just trim spaces as we don't want to modify the URL structure)
 		nl[i].ImageLink = strings.TrimSpace(nl[i].ImageLink)
 		nl[i].SourceLink = strings.TrimSpace(nl[i].SourceLink)
-		nl[i].Date = strings.TrimSpace(nl[i].Date)
+
+		// Store the times in UTC
+		nl[i].PublishedAt = nl[i].PublishedAt.UTC()
+		nl[i].ModifiedAt = nl[i].ModifiedAt.UTC()
+		nl[i].CrawledAt = nl[i].CrawledAt.UTC()
 	}
 }
@@ -0,0 +1,121 @@
+package dto
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	// SORT_PUBLISHED sorts by publish time, falling back to the crawl time
+	SORT_PUBLISHED = "published"
+	// SORT_CRAWLED sorts by crawl time
+	SORT_CRAWLED = "crawled"
+
+	// DEFAULT_QUERY_LIMIT is the page size of a query without limit
+	DEFAULT_QUERY_LIMIT = 20
+	// MAX_QUERY_LIMIT is the largest page size
+	MAX_QUERY_LIMIT = 1000
+)
+
+// NewsQuery selects, sorts and pages news stories. Empty fields do not
+// filter. Stories are sorted by time then by source link, so the same query
+// returns the same stories in the same order from every datastore.
+type NewsQuery struct {
+	Sources    []string
+	Categories []string
+	// From and To bound the sort time, From included and To excluded
+	From time.Time
+	To   time.Time
+	// Language is the ISO 639-1 code of the story language
+	Language           string
+	VerificationStatus string
+	// Entity is the name or an alias of an entity mentioned in the story
+	Entity string
+	// Text matches all the words of the headline and story, or a part of
+	// the headline
+	Text string
+
+	// Sort is SORT_PUBLISHED, the default, or SORT_CRAWLED
+	Sort      string
+	Ascending bool
+	Limit     int
+	// Cursor is the NextCursor of the previous page
+	Cursor string
+}
+
+// NewsPage is a page of the stories of a query, NextCursor is empty on the
+// last page
+type NewsPage struct {
+	News       []News `json:"news"`
+	NextCursor string `json:"next_cursor"`
+}
+
+type cursor struct {
+	Time       time.Time `json:"t"`
+	SourceLink string    `json:"l"`
+}
+
+// PageLimit returns the page size of the query
+func (q NewsQuery) PageLimit() int {
+	switch {
+	case q.Limit <= 0:
+		return DEFAULT_QUERY_LIMIT
+	case q.Limit > MAX_QUERY_LIMIT:
+		return MAX_QUERY_LIMIT
+	}
+
+	return q.Limit
+}
+
+// SortTime returns the time the news story is sorted by
+func (q NewsQuery) SortTime(n News) time.Time {
+	if q.Sort == SORT_CRAWLED || n.PublishedAt.IsZero() {
+		return n.CrawledAt
+	}
+
+	return n.PublishedAt
+}
+
+// NextCursor returns the cursor of the page following the news story
+func (q NewsQuery) NextCursor(n News) string {
+	b, _ := json.Marshal(cursor{Time: q.SortTime(n).UTC(), SourceLink: n.SourceLink})
+
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// DecodeCursor returns the sort time and source link of the last story of
+// the previous page
+func (q NewsQuery) DecodeCursor() (time.Time, string, error) {
+	b, err := base64.RawURLEncoding.DecodeString(q.Cursor)
+	if err != nil {
+		return time.Time{}, "", fmt.Errorf("error decoding cursor: %w", err)
+	}
+
+	var c cursor
+	if err := json.Unmarshal(b, &c); err != nil {
+		return time.Time{}, "", fmt.Errorf("error decoding cursor: %w", err)
+	}
+
+	return c.Time, c.SourceLink, nil
+}
+
+// Words returns the lower case words of the text, split on every character
+// other than a letter or a digit
+func (q NewsQuery) Words() []string {
+	return strings.FieldsFunc(strings.ToLower(q.Text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.Is(unicode.Mn, r) && !unicode.Is(unicode.Mc, r)
+	})
+}
+
+// Validate checks the sort of the query
+func (q NewsQuery) Validate() error {
+	if q.Sort != "" && q.Sort != SORT_PUBLISHED && q.Sort != SORT_CRAWLED {
+		return fmt.Errorf("unknown sort %s", q.Sort)
+	}
+
+	return nil
+}
@@ -0,0 +1,12 @@
+package dto
+
+import "time"
+
+// Checkpoint is the last change replicated in one direction, replication
+// resumes after it
+type Checkpoint struct {
+	Name         string    `json:"name"`
+	UpdatedAt    time.Time `json:"updated_at"`
+	SourceLink   string    `json:"source_link"`
+	ReplicatedAt time.Time `json:"replicated_at"`
+}
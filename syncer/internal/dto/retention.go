@@ -0,0 +1,44 @@
+package dto
+
+import "time"
+
+// RetentionRule is how long the news of a source and category are kept, an
+// empty source or category matches all of them and a 0 keeps forever
+type RetentionRule struct {
+	Source   string `json:"source"`
+	Category string `json:"category"`
+	// StoryDays is the age after which the story text is dropped, the
+	// headline, summary and metadata are kept
+	StoryDays int `json:"story_days"`
+	// ArchiveDays is the age after which the news are moved out of the news
+	// table into the archive
+	ArchiveDays int `json:"archive_days"`
+	// MaxRevisions is the number of recorded revisions kept per story, the
+	// oldest are purged
+	MaxRevisions int `json:"max_revisions"`
+}
+
+// Keeps tells whether the rule keeps everything
+func (r RetentionRule) Keeps() bool {
+	return r.StoryDays <= 0 && r.ArchiveDays <= 0 && r.MaxRevisions <= 0
+}
+
+// SourceCategory is a source and category of the stored news
+type SourceCategory struct {
+	Source   string `json:"source"`
+	Category string `json:"category"`
+	Count    int    `json:"count"`
+}
+
+// ArchivedNews is a news story moved to the archive
+type ArchivedNews struct {
+	News       News      `json:"news"`
+	ArchivedAt time.Time `json:"archived_at"`
+}
+
+// Tombstone is the link of a news story deleted by the retention rules, it
+// is not crawled again
+type Tombstone struct {
+	SourceLink string    `json:"source_link"`
+	DeletedAt  time.Time `json:"deleted_at"`
+}
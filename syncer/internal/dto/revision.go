@@ -0,0 +1,16 @@
+package dto
+
+import "time"
+
+// Revision is a version of a news story, Diff describes its changes from the
+// previous version
+type Revision struct {
+	SourceLink  string    `json:"source_link"`
+	Revision    int       `json:"revision"`
+	Headline    string    `json:"headline"`
+	Story       string    `json:"story"`
+	ContentHash string    `json:"content_hash"`
+	Diff        string    `json:"diff"`
+	ModifiedAt  time.Time `json:"modified_at"`
+	CreatedAt   time.Time `json:"created_at"`
+}
@@ -0,0 +1,32 @@
+package dto
+
+const (
+	// MERGE_KEEP keeps the stored news story untouched
+	MERGE_KEEP = "keep"
+	// MERGE_OVERWRITE replaces the fields extracted from the article, like the
+	// headline and story, and keeps the summary and the other enrichments
+	MERGE_OVERWRITE = "overwrite"
+	// MERGE_FILL_EMPTY only sets the fields of the stored news story that are
+	// empty, like a missing summary, which comes with its quarantine verdict
+	MERGE_FILL_EMPTY = "fill_empty"
+	// MERGE_REPLACE replaces the stored news story and its summary with the
+	// incoming version, like a replica. Empty incoming fields keep the stored
+	// ones, except the summary of a story whose text changed, and the
+	// enrichments of Postgres only, like clusters, are kept
+	MERGE_REPLACE = "replace"
+)
+
+// UpsertResult counts what happened to the news stories of an upsert
+type UpsertResult struct {
+	Inserted int `json:"inserted"`
+	Updated  int `json:"updated"`
+	// Skipped counts the stories already stored and left unchanged
+	Skipped int `json:"skipped"`
+}
+
+// Add adds the counts of another upsert
+func (r *UpsertResult) Add(other UpsertResult) {
+	r.Inserted += other.Inserted
+	r.Updated += other.Updated
+	r.Skipped += other.Skipped
+}
@@ -0,0 +1,10 @@
+package entities
+
+const (
+	// GAZETTEER_FILE optionally extends the built-in gazetteer with a JSON
+	// array of entities
+	GAZETTEER_FILE = "gazetteer.json"
+
+	// MAX_NAME_WORDS is the longest capitalized sequence taken as a name
+	MAX_NAME_WORDS = 6
+)
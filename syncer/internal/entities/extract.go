@@ -0,0 +1,311 @@
+// Package entities finds the people, organizations, locations and events
+// mentioned in news stories with a gazetteer and capitalization rules
+package entities
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"ncrawler/internal/dto"
+	"ncrawler/pkg/helpers"
+)
+
+var tokenRegex = regexp.MustCompile(`[\p{L}\p{N}][\p{L}\p{N}.'’&-]*`)
+
+// connectors may join the capitalized words of a name like "Bank of England"
+var connectors = map[string]bool{"of": true, "the": true, "for": true, "and": true, "de": true, "al": true, "bin": true, "von": true}
+
+// titles announce a person, they are not part of the name
+var titles = map[string]bool{
+	"Mr": true, "Mrs": true, "Ms": true, "Dr": true, "Sir": true, "Dame": true,
+	"President": true, "Minister": true, "Prime": true, "Chancellor": true, "Senator": true,
+	"Governor": true, "King": true, "Queen": true, "Prince": true, "Princess": true,
+	"Pope": true, "General": true, "Gen": true, "Judge": true, "Justice": true, "Adviser": true,
+	"Advisor": true, "Chief": true, "Secretary": true, "Ambassador": true, "Professor": true,
+	"Rep": true, "Sen": true, "Lt": true, "Col": true, "Captain": true, "Mayor": true,
+}
+
+var orgWords = map[string]bool{
+	"Ministry": true, "Department": true, "University": true, "College": true, "Party": true,
+	"Bank": true, "Council": true, "Association": true, "Corporation": true, "Company": true,
+	"Inc": true, "Ltd": true, "Group": true, "Agency": true, "Commission": true, "Court": true,
+	"Army": true, "Navy": true, "Police": true, "League": true, "Committee": true, "Union": true,
+	"Organisation": true, "Organization": true, "Institute": true, "Foundation": true, "Board": true,
+	"Authority": true, "Parliament": true, "Assembly": true, "Federation": true, "Forces": true,
+	"Office": true, "Times": true, "News": true, "Post": true, "Network": true,
+}
+
+var eventWords = map[string]bool{
+	"Summit": true, "Election": true, "Elections": true, "Olympics": true, "Cup": true,
+	"War": true, "Conference": true, "Festival": true, "Games": true, "Championship": true,
+	"Uprising": true, "Revolution": true, "Massacre": true, "Attack": true, "Earthquake": true,
+	"Cyclone": true, "Hurricane": true, "Storm": true, "Protests": true, "Crisis": true,
+}
+
+var placeWords = map[string]bool{
+	"City": true, "District": true, "Province": true, "State": true, "County": true,
+	"River": true, "Island": true, "Islands": true, "Valley": true, "Mountains": true,
+	"Sea": true, "Ocean": true, "Bay": true, "Division": true, "Upazila": true, "Street": true,
+	"Road": true, "Airport": true, "Port": true, "Camp": true, "Region": true,
+}
+
+var placePrepositions = map[string]bool{"in": true, "at": true, "near": true, "from": true, "to": true, "across": true}
+
+var speechVerbs = map[string]bool{"said": true, "says": true, "told": true, "added": true, "wrote": true, "asked": true, "warned": true}
+
+// skipWords are capitalized at the start of a sentence without being names
+var skipWords = map[string]bool{
+	"The": true, "A": true, "An": true, "He": true, "She": true, "It": true, "They": true,
+	"We": true, "I": true, "You": true, "But": true, "And": true, "Or": true, "In": true,
+	"On": true, "At": true, "As": true, "If": true, "When": true, "While": true, "After": true,
+	"Before": true, "This": true, "That": true, "These": true, "Those": true, "There": true,
+	"His": true, "Her": true, "Their": true, "Its": true, "Our": true, "Some": true, "Many": true,
+	"More": true, "Most": true, "For": true, "From": true, "With": true, "By": true, "So": true,
+	"Yet": true, "Also": true, "However": true, "Meanwhile": true, "According": true, "Still": true,
+	"Monday": true, "Tuesday": true, "Wednesday": true, "Thursday": true, "Friday": true,
+	"Saturday": true, "Sunday": true, "January": true, "February": true, "March": true,
+	"April": true, "May": true, "June": true, "July": true, "August": true, "September": true,
+	"October": true, "November": true, "December": true,
+}
+
+type mention struct {
+	words []string
+	// before and after are the words around the mention
+	before, after string
+	// titled is set when the mention follows a title like "President"
+	titled bool
+	// title are the title words taken off the start of the mention
+	title []string
+}
+
+// Extract returns the entities mentioned in the text, most mentioned first
+func Extract(text string) []dto.NewsEntity {
+	var (
+		found   = map[string]*dto.NewsEntity{}
+		pending []mention
+	)
+
+	add := func(e dto.Entity) {
+		key := e.Type + "\x00" + strings.ToLower(e.Name)
+		if ne, ok := found[key]; ok {
+			ne.Mentions++
+			return
+		}
+		found[key] = &dto.NewsEntity{Entity: e, Mentions: 1}
+	}
+
+	for _, sentence := range helpers.SplitSentences(text) {
+		for _, m := range findMentions(sentence) {
+			name := strings.Join(m.words, " ")
+			if e, ok := lookup(name); ok {
+				add(e)
+				continue
+			}
+			if t := classify(m); t != "" {
+				add(dto.Entity{Name: name, Type: t})
+				continue
+			}
+			if known := lookupWithin(m.words); len(known) > 0 {
+				for _, e := range known {
+					add(e)
+				}
+				continue
+			}
+			pending = append(pending, m)
+		}
+	}
+
+	// a bare surname refers to the person named in full elsewhere in the text
+	surnames := map[string]*dto.NewsEntity{}
+	for _, ne := range found {
+		if ne.Type == dto.ENTITY_PERSON {
+			if words := strings.Fields(ne.Name); len(words) > 1 {
+				surnames[words[len(words)-1]] = ne
+			}
+		}
+	}
+	for _, m := range pending {
+		if len(m.words) != 1 {
+			continue
+		}
+		if ne, ok := surnames[m.words[0]]; ok {
+			ne.Mentions++
+			if !containsFold(ne.Aliases, m.words[0]) {
+				ne.Aliases = append(ne.Aliases, m.words[0])
+			}
+		}
+	}
+
+	entities := make([]dto.NewsEntity, 0, len(found))
+	for _, ne := range found {
+		entities = append(entities, *ne)
+	}
+	sort.Slice(entities, func(i, j int) bool {
+		if entities[i].Mentions != entities[j].Mentions {
+			return entities[i].Mentions > entities[j].Mentions
+		}
+		return entities[i].Name < entities[j].Name
+	})
+
+	return entities
+}
+
+// Titled returns the names of the people announced by a title in the text,
+// by the lowercase title with the words qualifying it, like "foreign
+// minister" for "Foreign Minister Touhid Hossain". Known names are returned
+// by their canonical name.
+func Titled(text string) map[string][]string {
+	found := map[string][]string{}
+	for _, sentence := range helpers.SplitSentences(text) {
+		for _, m := range findMentions(sentence) {
+			words := append(append([]string{}, m.title...), m.words...)
+			k := -1
+			for i, w := range words {
+				if titles[w] {
+					k = i
+				}
+			}
+
+			var title, name []string
+			switch {
+			case k >= 0 && k < len(words)-1:
+				title, name = words[:k+1], words[k+1:]
+			case k < 0 && titles[m.before]:
+				title, name = []string{m.before}, words
+			default:
+				continue
+			}
+			if len(name) > 3 {
+				continue
+			}
+
+			person := strings.Join(name, " ")
+			if e, ok := lookup(person); ok && e.Type == dto.ENTITY_PERSON {
+				person = e.Name
+			}
+			key := strings.ToLower(strings.Join(title, " "))
+			found[key] = append(found[key], person)
+		}
+	}
+
+	return found
+}
+
+// findMentions returns the capitalized word sequences of the sentence
+func findMentions(sentence string) []mention {
+	tokens := tokenRegex.FindAllString(sentence, -1)
+	for i, t := range tokens {
+		tokens[i] = strings.TrimSuffix(strings.TrimSuffix(strings.TrimRight(t, ".-"), "'s"), "’s")
+		if isAcronym(t) {
+			tokens[i] = t
+		}
+	}
+
+	var mentions []mention
+	for i := 0; i < len(tokens); {
+		if !isCapitalized(tokens[i]) || (i == 0 && skipWords[tokens[i]]) {
+			i++
+			continue
+		}
+
+		j := i
+		for j < len(tokens) && j-i < MAX_NAME_WORDS {
+			if isCapitalized(tokens[j]) {
+				j++
+				continue
+			}
+			// a connector must be followed by another capitalized word
+			if connectors[tokens[j]] && j+1 < len(tokens) && isCapitalized(tokens[j+1]) && j > i {
+				j++
+				continue
+			}
+			break
+		}
+
+		m := mention{words: tokens[i:j]}
+		for len(m.words) > 1 && titles[m.words[0]] {
+			m.title = append(m.title, m.words[0])
+			m.words, m.titled = m.words[1:], true
+		}
+		if i > 0 {
+			m.before = tokens[i-1]
+			m.titled = m.titled || titles[m.before]
+		}
+		if j < len(tokens) {
+			m.after = tokens[j]
+		}
+		if !(len(m.words) == 1 && titles[m.words[0]]) {
+			mentions = append(mentions, m)
+		}
+		i = j
+	}
+
+	return mentions
+}
+
+// lookupWithin returns the gazetteer entities named inside the words, like
+// "UN" and "Antonio Guterres" in "UN Secretary-General Antonio Guterres",
+// preferring the longest names
+func lookupWithin(words []string) []dto.Entity {
+	var known []dto.Entity
+	for i := 0; i < len(words); {
+		j := len(words)
+		for ; j > i; j-- {
+			if e, ok := lookup(strings.Join(words[i:j], " ")); ok {
+				known = append(known, e)
+				break
+			}
+		}
+		if j > i {
+			i = j
+		} else {
+			i++
+		}
+	}
+
+	return known
+}
+
+// classify returns the entity type of an unknown mention, or "" when the
+// rules can not tell
+func classify(m mention) string {
+	last := m.words[len(m.words)-1]
+	switch {
+	case m.titled && len(m.words) <= 3:
+		return dto.ENTITY_PERSON
+	case orgWords[last] || orgWords[m.words[0]]:
+		return dto.ENTITY_ORGANIZATION
+	case eventWords[last]:
+		return dto.ENTITY_EVENT
+	case placeWords[last]:
+		return dto.ENTITY_LOCATION
+	case len(m.words) == 1 && isAcronym(last):
+		return dto.ENTITY_ORGANIZATION
+	case len(m.words) >= 2 && len(m.words) <= 3 && speechVerbs[m.after]:
+		return dto.ENTITY_PERSON
+	case len(m.words) <= 2 && placePrepositions[m.before] && !skipWords[m.words[0]]:
+		return dto.ENTITY_LOCATION
+	}
+
+	return ""
+}
+
+func isCapitalized(word string) bool {
+	for _, r := range word {
+		return unicode.IsUpper(r)
+	}
+
+	return false
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,164 @@
+package entities
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+
+	"ncrawler/internal/dto"
+)
+
+// gazetteer holds the entities known by name, it can be extended with a
+// GAZETTEER_FILE holding a JSON array of entities
+var gazetteer = []dto.Entity{
+	// people
+	{Name: "Muhammad Yunus", Type: dto.ENTITY_PERSON, Aliases: []string{"Yunus", "Dr Yunus", "Professor Yunus"}},
+	{Name: "Sheikh Hasina", Type: dto.ENTITY_PERSON, Aliases: []string{"Hasina"}},
+	{Name: "Khaleda Zia", Type: dto.ENTITY_PERSON, Aliases: []string{"Begum Khaleda Zia"}},
+	{Name: "Tarique Rahman", Type: dto.ENTITY_PERSON},
+	{Name: "Donald Trump", Type: dto.ENTITY_PERSON, Aliases: []string{"Trump", "Donald J. Trump"}},
+	{Name: "Joe Biden", Type: dto.ENTITY_PERSON, Aliases: []string{"Biden", "Joseph Biden"}},
+	{Name: "Kamala Harris", Type: dto.ENTITY_PERSON, Aliases: []string{"Harris"}},
+	{Name: "Vladimir Putin", Type: dto.ENTITY_PERSON, Aliases: []string{"Putin"}},
+	{Name: "Volodymyr Zelensky", Type: dto.ENTITY_PERSON, Aliases: []string{"Zelensky", "Zelenskyy", "Volodymyr Zelenskyy"}},
+	{Name: "Xi Jinping", Type: dto.ENTITY_PERSON, Aliases: []string{"Xi"}},
+	{Name: "Narendra Modi", Type: dto.ENTITY_PERSON, Aliases: []string{"Modi"}},
+	{Name: "Benjamin Netanyahu", Type: dto.ENTITY_PERSON, Aliases: []string{"Netanyahu"}},
+	{Name: "Keir Starmer", Type: dto.ENTITY_PERSON, Aliases: []string{"Starmer"}},
+	{Name: "Emmanuel Macron", Type: dto.ENTITY_PERSON, Aliases: []string{"Macron"}},
+	{Name: "Antonio Guterres", Type: dto.ENTITY_PERSON, Aliases: []string{"Guterres", "António Guterres"}},
+
+	// organizations
+	{Name: "United Nations", Type: dto.ENTITY_ORGANIZATION, Aliases: []string{"UN", "U.N."}},
+	{Name: "European Union", Type: dto.ENTITY_ORGANIZATION, Aliases: []string{"EU", "E.U."}},
+	{Name: "NATO", Type: dto.ENTITY_ORGANIZATION, Aliases: []string{"North Atlantic Treaty Organization"}},
+	{Name: "World Health Organization", Type: dto.ENTITY_ORGANIZATION, Aliases: []string{"WHO"}},
+	{Name: "International Monetary Fund", Type: dto.ENTITY_ORGANIZATION, Aliases: []string{"IMF"}},
+	{Name: "World Bank", Type: dto.ENTITY_ORGANIZATION},
+	{Name: "Asian Development Bank", Type: dto.ENTITY_ORGANIZATION, Aliases: []string{"ADB"}},
+	{Name: "Bangladesh Nationalist Party", Type: dto.ENTITY_ORGANIZATION, Aliases: []string{"BNP"}},
+	{Name: "Awami League", Type: dto.ENTITY_ORGANIZATION, Aliases: []string{"AL"}},
+	{Name: "Jamaat-e-Islami", Type: dto.ENTITY_ORGANIZATION, Aliases: []string{"Jamaat", "Bangladesh Jamaat-e-Islami"}},
+	{Name: "Election Commission", Type: dto.ENTITY_ORGANIZATION, Aliases: []string{"EC"}},
+	{Name: "Bangladesh Bank", Type: dto.ENTITY_ORGANIZATION},
+	{Name: "Hamas", Type: dto.ENTITY_ORGANIZATION},
+	{Name: "Hezbollah", Type: dto.ENTITY_ORGANIZATION},
+	{Name: "Taliban", Type: dto.ENTITY_ORGANIZATION},
+	{Name: "Kremlin", Type: dto.ENTITY_ORGANIZATION},
+	{Name: "White House", Type: dto.ENTITY_ORGANIZATION},
+	{Name: "Pentagon", Type: dto.ENTITY_ORGANIZATION},
+	{Name: "Federal Reserve", Type: dto.ENTITY_ORGANIZATION, Aliases: []string{"Fed"}},
+	{Name: "Reuters", Type: dto.ENTITY_ORGANIZATION},
+	{Name: "BBC", Type: dto.ENTITY_ORGANIZATION},
+	{Name: "CNN", Type: dto.ENTITY_ORGANIZATION},
+	{Name: "Al Jazeera", Type: dto.ENTITY_ORGANIZATION},
+
+	// locations
+	{Name: "Bangladesh", Type: dto.ENTITY_LOCATION},
+	{Name: "Dhaka", Type: dto.ENTITY_LOCATION},
+	{Name: "Chattogram", Type: dto.ENTITY_LOCATION, Aliases: []string{"Chittagong"}},
+	{Name: "Sylhet", Type: dto.ENTITY_LOCATION},
+	{Name: "Cox's Bazar", Type: dto.ENTITY_LOCATION},
+	{Name: "India", Type: dto.ENTITY_LOCATION},
+	{Name: "New Delhi", Type: dto.ENTITY_LOCATION, Aliases: []string{"Delhi"}},
+	{Name: "Pakistan", Type: dto.ENTITY_LOCATION},
+	{Name: "Myanmar", Type: dto.ENTITY_LOCATION, Aliases: []string{"Burma"}},
+	{Name: "China", Type: dto.ENTITY_LOCATION},
+	{Name: "Beijing", Type: dto.ENTITY_LOCATION},
+	{Name: "Japan", Type: dto.ENTITY_LOCATION},
+	{Name: "United States", Type: dto.ENTITY_LOCATION, Aliases: []string{"US", "U.S.", "USA", "United States of America"}},
+	{Name: "Washington", Type: dto.ENTITY_LOCATION, Aliases: []string{"Washington DC", "Washington, DC"}},
+	{Name: "New York", Type: dto.ENTITY_LOCATION},
+	{Name: "United Kingdom", Type: dto.ENTITY_LOCATION, Aliases: []string{"UK", "U.K.", "Britain", "Great Britain"}},
+	{Name: "London", Type: dto.ENTITY_LOCATION},
+	{Name: "France", Type: dto.ENTITY_LOCATION},
+	{Name: "Paris", Type: dto.ENTITY_LOCATION},
+	{Name: "Germany", Type: dto.ENTITY_LOCATION},
+	{Name: "Russia", Type: dto.ENTITY_LOCATION},
+	{Name: "Moscow", Type: dto.ENTITY_LOCATION},
+	{Name: "Ukraine", Type: dto.ENTITY_LOCATION},
+	{Name: "Kyiv", Type: dto.ENTITY_LOCATION, Aliases: []string{"Kiev"}},
+	{Name: "Israel", Type: dto.ENTITY_LOCATION},
+	{Name: "Jerusalem", Type: dto.ENTITY_LOCATION},
+	{Name: "Gaza", Type: dto.ENTITY_LOCATION, Aliases: []string{"Gaza Strip"}},
+	{Name: "West Bank", Type: dto.ENTITY_LOCATION},
+	{Name: "Lebanon", Type: dto.ENTITY_LOCATION},
+	{Name: "Iran", Type: dto.ENTITY_LOCATION},
+	{Name: "Syria", Type: dto.ENTITY_LOCATION},
+	{Name: "Saudi Arabia", Type: dto.ENTITY_LOCATION},
+	{Name: "Qatar", Type: dto.ENTITY_LOCATION},
+	{Name: "Afghanistan", Type: dto.ENTITY_LOCATION},
+
+	// events
+	{Name: "COP29", Type: dto.ENTITY_EVENT},
+	{Name: "Olympic Games", Type: dto.ENTITY_EVENT, Aliases: []string{"Olympics"}},
+	{Name: "FIFA World Cup", Type: dto.ENTITY_EVENT, Aliases: []string{"World Cup"}},
+	{Name: "July Uprising", Type: dto.ENTITY_EVENT, Aliases: []string{"July Revolution", "July uprising"}},
+}
+
+var (
+	loadGazetteerOnce sync.Once
+	// byAlias maps the lowercase names and aliases to the gazetteer, acronyms
+	// are matched case sensitively through byAcronym
+	byAlias   map[string]dto.Entity
+	byAcronym map[string]dto.Entity
+)
+
+// loadGazetteer indexes the gazetteer, extended with GAZETTEER_FILE
+func loadGazetteer() {
+	content, err := os.ReadFile(GAZETTEER_FILE)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		slog.Error("failed to read gazetteer file", "file", GAZETTEER_FILE, "error", err)
+	default:
+		var extra []dto.Entity
+		if err := json.Unmarshal(content, &extra); err != nil {
+			slog.Error("failed to decode gazetteer file", "file", GAZETTEER_FILE, "error", err)
+		}
+		gazetteer = append(gazetteer, extra...)
+	}
+
+	byAlias = make(map[string]dto.Entity, len(gazetteer)*2)
+	byAcronym = make(map[string]dto.Entity)
+	for _, e := range gazetteer {
+		for _, name := range append([]string{e.Name}, e.Aliases...) {
+			if isAcronym(name) {
+				byAcronym[name] = e
+				continue
+			}
+			byAlias[strings.ToLower(name)] = e
+		}
+	}
+}
+
+// lookup returns the gazetteer entity known by the name
+func lookup(name string) (dto.Entity, bool) {
+	loadGazetteerOnce.Do(loadGazetteer)
+
+	if e, ok := byAcronym[name]; ok {
+		return e, true
+	}
+	e, ok := byAlias[strings.ToLower(name)]
+
+	return e, ok
+}
+
+// isAcronym reports whether the name is written in capitals like "UN" or "U.S."
+func isAcronym(name string) bool {
+	letters := 0
+	for _, r := range name {
+		switch {
+		case r >= 'A' && r <= 'Z':
+			letters++
+		case r == '.' || (r >= '0' && r <= '9'):
+		default:
+			return false
+		}
+	}
+
+	return letters >= 2 && letters <= 6
+}
@@ -0,0 +1,50 @@
+package eval
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"ncrawler/internal/dto"
+)
+
+// Dataset is a frozen set of stored articles used to compare summarizers.
+// The stored Summary of each article is used as the reference summary.
+type Dataset struct {
+	CreatedAt time.Time    `json:"created_at"`
+	Articles  dto.NewsList `json:"articles"`
+}
+
+// NewDataset freezes the given articles into a dataset
+func NewDataset(articles dto.NewsList) *Dataset {
+	return &Dataset{
+		CreatedAt: time.Now().UTC(),
+		Articles:  articles,
+	}
+}
+
+// LoadDataset reads a dataset written by Save
+func LoadDataset(path string) (*Dataset, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading dataset: %w", err)
+	}
+
+	ds := &Dataset{}
+	if err := json.Unmarshal(content, ds); err != nil {
+		return nil, fmt.Errorf("error decoding dataset: %w", err)
+	}
+
+	return ds, nil
+}
+
+// Save writes the dataset to the given path as indented JSON
+func (ds *Dataset) Save(path string) error {
+	content, err := json.MarshalIndent(ds, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding dataset: %w", err)
+	}
+
+	return os.WriteFile(path, content, 0o644)
+}
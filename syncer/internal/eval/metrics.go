@@ -0,0 +1,139 @@
+package eval
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"ncrawler/internal/ai"
+	"ncrawler/internal/dto"
+)
+
+const (
+	MIN_SUMMARY_WORDS = 300
+	MAX_SUMMARY_WORDS = 400
+	MIN_KEY_POINTS    = 3
+	MAX_KEY_POINTS    = 5
+)
+
+var (
+	numberRegex = regexp.MustCompile(`\p{Nd}+(?:[.,]\p{Nd}+)*`)
+	quoteRegex  = regexp.MustCompile(`["“]([^"”]+)["”]`)
+	entityRegex = regexp.MustCompile(`\p{Lu}[\p{L}'’.-]*(?:\s+(?:of\s+|the\s+)?\p{Lu}[\p{L}'’.-]*)*`)
+)
+
+// Metrics are the automatic quality measures of a single summary
+type Metrics struct {
+	WordCount          int      `json:"word_count"`
+	LengthCompliant    bool     `json:"length_compliant"`
+	KeyPointCount      int      `json:"key_point_count"`
+	KeyPointsCompliant bool     `json:"key_points_compliant"`
+	Rouge              *Rouge   `json:"rouge,omitempty"`
+	Numbers            int      `json:"numbers"`
+	NumbersVerbatim    int      `json:"numbers_verbatim"`
+	Quotes             int      `json:"quotes"`
+	QuotesVerbatim     int      `json:"quotes_verbatim"`
+	MissingEntities    []string `json:"missing_entities,omitempty"`
+}
+
+// Score computes the metrics of the generated summary of the article.
+// ROUGE is only computed when the article carries a reference summary.
+func Score(summary ai.ArticleSummary, article dto.News) Metrics {
+	generated := summary.Summary + "\n" + strings.Join(summary.KeyPoints, "\n")
+	source := article.Headline + "\n" + article.Story
+
+	m := Metrics{
+		WordCount:     len(strings.Fields(summary.Summary)),
+		KeyPointCount: len(summary.KeyPoints),
+	}
+	m.LengthCompliant = m.WordCount >= MIN_SUMMARY_WORDS && m.WordCount <= MAX_SUMMARY_WORDS
+	m.KeyPointsCompliant = m.KeyPointCount >= MIN_KEY_POINTS && m.KeyPointCount <= MAX_KEY_POINTS
+
+	if article.Summary != "" {
+		r := ComputeRouge(summary.Summary, article.Summary)
+		m.Rouge = &r
+	}
+
+	sourceNumbers := make(map[string]struct{})
+	for _, n := range numberRegex.FindAllString(source, -1) {
+		sourceNumbers[normalizeNumber(n)] = struct{}{}
+	}
+	for _, n := range numberRegex.FindAllString(generated, -1) {
+		m.Numbers++
+		if _, ok := sourceNumbers[normalizeNumber(n)]; ok {
+			m.NumbersVerbatim++
+		}
+	}
+
+	normalizedSource := normalizeQuotes(source)
+	for _, q := range quoteRegex.FindAllStringSubmatch(generated, -1) {
+		m.Quotes++
+		if strings.Contains(normalizedSource, normalizeQuotes(q[1])) {
+			m.QuotesVerbatim++
+		}
+	}
+
+	m.MissingEntities = missingEntities(generated, source)
+
+	return m
+}
+
+// normalizeNumber writes a number with ASCII digits and without thousands
+// separators, so "১২,৫০০" in the story matches "12500" in the summary
+func normalizeNumber(n string) string {
+	var sb strings.Builder
+	for _, r := range n {
+		switch {
+		case r == ',':
+			continue
+		case r >= '০' && r <= '৯':
+			sb.WriteRune('0' + r - '০')
+		default:
+			sb.WriteRune(r)
+		}
+	}
+
+	return sb.String()
+}
+
+// normalizeQuotes collapses white space and typographic apostrophes so that
+// a quote copied from the story matches regardless of formatting
+func normalizeQuotes(text string) string {
+	text = strings.ReplaceAll(text, "’", "'")
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// missingEntities returns the capitalised names found in the summary that do
+// not appear in the source text
+func missingEntities(summary, source string) []string {
+	lowerSource := strings.ToLower(source)
+	seen := make(map[string]struct{})
+
+	var missing []string
+	for _, loc := range entityRegex.FindAllStringIndex(summary, -1) {
+		entity := strings.TrimRight(summary[loc[0]:loc[1]], ".-'’")
+		if !strings.Contains(entity, " ") && isSentenceStart(summary, loc[0]) {
+			continue
+		}
+		if _, ok := seen[entity]; ok {
+			continue
+		}
+		seen[entity] = struct{}{}
+
+		if !strings.Contains(lowerSource, strings.ToLower(entity)) {
+			missing = append(missing, entity)
+		}
+	}
+
+	return missing
+}
+
+// isSentenceStart reports whether the word at position i starts a sentence
+func isSentenceStart(text string, i int) bool {
+	prefix := strings.TrimRightFunc(text[:i], unicode.IsSpace)
+	if prefix == "" {
+		return true
+	}
+
+	return strings.ContainsAny(prefix[len(prefix)-1:], ".!?:\"“\n")
+}
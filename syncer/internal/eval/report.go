@@ -0,0 +1,179 @@
+package eval
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"text/tabwriter"
+
+	"ncrawler/internal/ai"
+)
+
+// ArticleResult is the outcome of summarizing one article of the dataset
+type ArticleResult struct {
+	SourceLink string   `json:"source_link"`
+	Headline   string   `json:"headline"`
+	Metrics    *Metrics `json:"metrics,omitempty"`
+	Error      string   `json:"error,omitempty"`
+}
+
+// Aggregate are the metrics averaged over all the summarized articles
+type Aggregate struct {
+	Articles           int     `json:"articles"`
+	Failed             int     `json:"failed"`
+	LengthCompliance   float64 `json:"length_compliance"`
+	KeyPointCompliance float64 `json:"key_point_compliance"`
+	AvgWordCount       float64 `json:"avg_word_count"`
+	AvgKeyPoints       float64 `json:"avg_key_points"`
+	Rouge1             float64 `json:"rouge_1"`
+	Rouge2             float64 `json:"rouge_2"`
+	RougeL             float64 `json:"rouge_l"`
+	NumberSupport      float64 `json:"number_support"`
+	QuoteSupport       float64 `json:"quote_support"`
+	AvgMissingEntities float64 `json:"avg_missing_entities"`
+}
+
+// VariantResult holds the results of one prompt/model version
+type VariantResult struct {
+	Label     string          `json:"label"`
+	Aggregate Aggregate       `json:"aggregate"`
+	Results   []ArticleResult `json:"results"`
+}
+
+// Report compares two prompt/model versions over the same dataset
+type Report struct {
+	Articles int           `json:"articles"`
+	A        VariantResult `json:"a"`
+	B        VariantResult `json:"b"`
+}
+
+// Compare runs both versions of the summarizer over the dataset
+func Compare(ds *Dataset, a, b ai.Options) *Report {
+	return &Report{
+		Articles: len(ds.Articles),
+		A:        runVariant(ds, a),
+		B:        runVariant(ds, b),
+	}
+}
+
+func runVariant(ds *Dataset, opts ai.Options) VariantResult {
+	vr := VariantResult{Label: opts.Label()}
+
+	for _, article := range ds.Articles {
+		slog.Info("evaluating", "variant", vr.Label, "headline", article.Headline)
+		res := ArticleResult{
+			SourceLink: article.SourceLink,
+			Headline:   article.Headline,
+		}
+
+		summary, err := ai.GetSummaryWithOptions(article, opts)
+		if err != nil {
+			slog.Error("failed to generate summary", "variant", vr.Label, "error", err)
+			res.Error = err.Error()
+		} else {
+			m := Score(*summary, article)
+			res.Metrics = &m
+		}
+
+		vr.Results = append(vr.Results, res)
+	}
+
+	vr.Aggregate = aggregate(vr.Results)
+
+	return vr
+}
+
+func aggregate(results []ArticleResult) Aggregate {
+	var (
+		agg                       Aggregate
+		rougeCount                int
+		numbers, numbersVerbatim  int
+		quotes, quotesVerbatim    int
+		lengthOk, keyPointsOk     int
+		words, keyPoints, missing int
+	)
+
+	for _, res := range results {
+		if res.Metrics == nil {
+			agg.Failed++
+			continue
+		}
+		m := res.Metrics
+		agg.Articles++
+
+		if m.LengthCompliant {
+			lengthOk++
+		}
+		if m.KeyPointsCompliant {
+			keyPointsOk++
+		}
+		words += m.WordCount
+		keyPoints += m.KeyPointCount
+		missing += len(m.MissingEntities)
+		numbers += m.Numbers
+		numbersVerbatim += m.NumbersVerbatim
+		quotes += m.Quotes
+		quotesVerbatim += m.QuotesVerbatim
+
+		if m.Rouge != nil {
+			rougeCount++
+			agg.Rouge1 += m.Rouge.Rouge1
+			agg.Rouge2 += m.Rouge.Rouge2
+			agg.RougeL += m.Rouge.RougeL
+		}
+	}
+
+	agg.LengthCompliance = ratio(lengthOk, agg.Articles)
+	agg.KeyPointCompliance = ratio(keyPointsOk, agg.Articles)
+	agg.AvgWordCount = ratio(words, agg.Articles)
+	agg.AvgKeyPoints = ratio(keyPoints, agg.Articles)
+	agg.AvgMissingEntities = ratio(missing, agg.Articles)
+	agg.NumberSupport = ratio(numbersVerbatim, numbers)
+	agg.QuoteSupport = ratio(quotesVerbatim, quotes)
+	if rougeCount > 0 {
+		agg.Rouge1 /= float64(rougeCount)
+		agg.Rouge2 /= float64(rougeCount)
+		agg.RougeL /= float64(rougeCount)
+	}
+
+	return agg
+}
+
+func ratio(part, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+
+	return float64(part) / float64(total)
+}
+
+// WriteText writes a side by side comparison table of both versions
+func (r *Report) WriteText(w io.Writer) error {
+	a, b := r.A.Aggregate, r.B.Aggregate
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(tw, "metric\t%s\t%s\tdelta\t\n", r.A.Label, r.B.Label)
+
+	rows := []struct {
+		name string
+		a, b float64
+	}{
+		{"summarized", float64(a.Articles), float64(b.Articles)},
+		{"failed", float64(a.Failed), float64(b.Failed)},
+		{"length compliance", a.LengthCompliance, b.LengthCompliance},
+		{"avg words", a.AvgWordCount, b.AvgWordCount},
+		{"key point compliance", a.KeyPointCompliance, b.KeyPointCompliance},
+		{"avg key points", a.AvgKeyPoints, b.AvgKeyPoints},
+		{"rouge-1", a.Rouge1, b.Rouge1},
+		{"rouge-2", a.Rouge2, b.Rouge2},
+		{"rouge-l", a.RougeL, b.RougeL},
+		{"numbers verbatim", a.NumberSupport, b.NumberSupport},
+		{"quotes verbatim", a.QuoteSupport, b.QuoteSupport},
+		{"avg missing entities", a.AvgMissingEntities, b.AvgMissingEntities},
+	}
+	for _, row := range rows {
+		fmt.Fprintf(tw, "%s\t%.3f\t%.3f\t%+.3f\t\n", row.name, row.a, row.b, row.b-row.a)
+	}
+
+	return tw.Flush()
+}
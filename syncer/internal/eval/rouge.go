@@ -0,0 +1,90 @@
+package eval
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Rouge holds the F1 scores of the ROUGE-1, ROUGE-2 and ROUGE-L metrics
+type Rouge struct {
+	Rouge1 float64 `json:"rouge_1"`
+	Rouge2 float64 `json:"rouge_2"`
+	RougeL float64 `json:"rouge_l"`
+}
+
+// ComputeRouge scores the candidate text against the reference text
+func ComputeRouge(candidate, reference string) Rouge {
+	c := tokenize(candidate)
+	r := tokenize(reference)
+
+	return Rouge{
+		Rouge1: ngramF1(c, r, 1),
+		Rouge2: ngramF1(c, r, 2),
+		RougeL: lcsF1(c, r),
+	}
+}
+
+// tokenize lower cases the text and splits it into words
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+func ngrams(tokens []string, n int) map[string]int {
+	counts := make(map[string]int)
+	for i := 0; i+n <= len(tokens); i++ {
+		counts[strings.Join(tokens[i:i+n], " ")]++
+	}
+
+	return counts
+}
+
+func ngramF1(candidate, reference []string, n int) float64 {
+	c := ngrams(candidate, n)
+	r := ngrams(reference, n)
+
+	var overlap, cTotal, rTotal int
+	for gram, count := range c {
+		overlap += min(count, r[gram])
+		cTotal += count
+	}
+	for _, count := range r {
+		rTotal += count
+	}
+
+	return f1(overlap, cTotal, rTotal)
+}
+
+// lcsF1 computes the F1 score of the longest common subsequence
+func lcsF1(candidate, reference []string) float64 {
+	if len(candidate) == 0 || len(reference) == 0 {
+		return 0
+	}
+
+	prev := make([]int, len(reference)+1)
+	curr := make([]int, len(reference)+1)
+	for i := 1; i <= len(candidate); i++ {
+		for j := 1; j <= len(reference); j++ {
+			if candidate[i-1] == reference[j-1] {
+				curr[j] = prev[j-1] + 1
+			} else {
+				curr[j] = max(prev[j], curr[j-1])
+			}
+		}
+		prev, curr = curr, prev
+	}
+
+	return f1(prev[len(reference)], len(candidate), len(reference))
+}
+
+func f1(overlap, candidateTotal, referenceTotal int) float64 {
+	if overlap == 0 || candidateTotal == 0 || referenceTotal == 0 {
+		return 0
+	}
+
+	precision := float64(overlap) / float64(candidateTotal)
+	recall := float64(overlap) / float64(referenceTotal)
+
+	return 2 * precision * recall / (precision + recall)
+}
@@ -0,0 +1,16 @@
+package export
+
+const (
+	FORMAT_CSV      = "csv"
+	FORMAT_JSON     = "json"
+	FORMAT_NDJSON   = "ndjson"
+	FORMAT_MARKDOWN = "markdown"
+	FORMAT_PARQUET  = "parquet"
+
+	// PAGE_SIZE is the number of news stories read from the datastore and
+	// written at a time, a Parquet row group holds one page
+	PAGE_SIZE = 500
+)
+
+// Formats are the supported export formats
+var Formats = []string{FORMAT_CSV, FORMAT_JSON, FORMAT_NDJSON, FORMAT_MARKDOWN, FORMAT_PARQUET}
@@ -0,0 +1,39 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+
+	"ncrawler/internal/dto"
+
+	"github.com/gocarina/gocsv"
+)
+
+// csvWriter writes the header with the first stories, lists are written as
+// JSON arrays
+type csvWriter struct {
+	w             *gocsv.SafeCSVWriter
+	headerWritten bool
+}
+
+func newCsvWriter(w io.Writer) *csvWriter {
+	return &csvWriter{w: gocsv.NewSafeCSVWriter(csv.NewWriter(w))}
+}
+
+func (cw *csvWriter) Write(newsStories []dto.News) error {
+	if cw.headerWritten {
+		return gocsv.MarshalCSVWithoutHeaders(&newsStories, cw.w)
+	}
+
+	cw.headerWritten = true
+	return gocsv.MarshalCSV(&newsStories, cw.w)
+}
+
+// Close writes the header of an export without stories
+func (cw *csvWriter) Close() error {
+	if !cw.headerWritten {
+		return cw.Write([]dto.News{})
+	}
+
+	return nil
+}
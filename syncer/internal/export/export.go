@@ -0,0 +1,70 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"ncrawler/internal/dto"
+)
+
+// Writer writes the news stories in an export format, Close ends the file
+// without closing the underlying writer
+type Writer interface {
+	Write(newsStories []dto.News) error
+	Close() error
+}
+
+// QueryFunc reads a page of the news stories matching the query
+type QueryFunc func(q dto.NewsQuery) (dto.NewsPage, error)
+
+// NewWriter returns the writer of the format
+func NewWriter(format string, w io.Writer) (Writer, error) {
+	switch format {
+	case FORMAT_CSV:
+		return newCsvWriter(w), nil
+	case FORMAT_JSON:
+		return newJsonWriter(w), nil
+	case FORMAT_NDJSON:
+		return newNdjsonWriter(w), nil
+	case FORMAT_MARKDOWN:
+		return newMarkdownWriter(w), nil
+	case FORMAT_PARQUET:
+		return newParquetWriter(w), nil
+	}
+
+	return nil, fmt.Errorf("unknown export format %s, use one of %s", format, strings.Join(Formats, ", "))
+}
+
+// Export streams the news stories matching the query to the writer page by
+// page, at most limit stories or all of them when limit is 0. It returns the
+// number of stories written and closes the writer.
+func Export(q dto.NewsQuery, limit int, query QueryFunc, w Writer) (int, error) {
+	written := 0
+	for {
+		q.Limit = PAGE_SIZE
+		if limit > 0 && limit-written < PAGE_SIZE {
+			q.Limit = limit - written
+		}
+
+		page, err := query(q)
+		if err != nil {
+			return written, err
+		}
+		if err := w.Write(page.News); err != nil {
+			return written, fmt.Errorf("error writing news: %w", err)
+		}
+		written += len(page.News)
+
+		if page.NextCursor == "" || (limit > 0 && written >= limit) {
+			break
+		}
+		q.Cursor = page.NextCursor
+	}
+
+	if err := w.Close(); err != nil {
+		return written, fmt.Errorf("error closing export: %w", err)
+	}
+
+	return written, nil
+}
@@ -0,0 +1,77 @@
+package export
+
+import (
+	"encoding/json"
+	"io"
+
+	"ncrawler/internal/dto"
+)
+
+// jsonWriter writes a JSON array one story per line
+type jsonWriter struct {
+	w       io.Writer
+	enc     *json.Encoder
+	written bool
+}
+
+func newJsonWriter(w io.Writer) *jsonWriter {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+
+	return &jsonWriter{w: w, enc: enc}
+}
+
+func (jw *jsonWriter) Write(newsStories []dto.News) error {
+	for _, news := range newsStories {
+		sep := ","
+		if !jw.written {
+			sep = "["
+		}
+		if _, err := io.WriteString(jw.w, sep); err != nil {
+			return err
+		}
+		jw.written = true
+
+		if err := jw.enc.Encode(news); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (jw *jsonWriter) Close() error {
+	end := "]\n"
+	if !jw.written {
+		end = "[]\n"
+	}
+	_, err := io.WriteString(jw.w, end)
+
+	return err
+}
+
+// ndjsonWriter writes one story per line
+type ndjsonWriter struct {
+	enc *json.Encoder
+}
+
+func newNdjsonWriter(w io.Writer) *ndjsonWriter {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+
+	return &ndjsonWriter{enc: enc}
+}
+
+func (nw *ndjsonWriter) Write(newsStories []dto.News) error {
+	for _, news := range newsStories {
+		if err := nw.enc.Encode(news); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (nw *ndjsonWriter) Close() error {
+	return nil
+}
@@ -0,0 +1,59 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"ncrawler/internal/dto"
+)
+
+// markdownEscaper escapes the headline inside the text of a link
+var markdownEscaper = strings.NewReplacer(`[`, `\[`, `]`, `\]`)
+
+// markdownWriter writes a section per story with its summary and bullet
+// points
+type markdownWriter struct {
+	w io.Writer
+}
+
+func newMarkdownWriter(w io.Writer) *markdownWriter {
+	return &markdownWriter{w: w}
+}
+
+func (mw *markdownWriter) Write(newsStories []dto.News) error {
+	for _, news := range newsStories {
+		var sb strings.Builder
+		fmt.Fprintf(&sb, "## [%s](%s)\n\n", markdownEscaper.Replace(news.Headline), news.SourceLink)
+
+		meta := []string{news.SourceName, news.Category}
+		if !news.PublishedAt.IsZero() {
+			meta = append(meta, news.PublishedAt.Format(time.DateTime)+" UTC")
+		}
+		if news.VerificationStatus != "" {
+			meta = append(meta, news.VerificationStatus)
+		}
+		fmt.Fprintf(&sb, "*%s*\n\n", strings.Join(meta, " · "))
+
+		if news.Summary != "" {
+			fmt.Fprintf(&sb, "%s\n\n", news.Summary)
+		}
+		for _, point := range news.BulletPoints {
+			fmt.Fprintf(&sb, "- %s\n", point)
+		}
+		if len(news.BulletPoints) > 0 {
+			sb.WriteString("\n")
+		}
+
+		if _, err := io.WriteString(mw.w, sb.String()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (mw *markdownWriter) Close() error {
+	return nil
+}
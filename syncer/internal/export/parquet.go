@@ -0,0 +1,34 @@
+package export
+
+import (
+	"io"
+
+	"ncrawler/internal/dto"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// parquetWriter writes a row group per page so only one page is held in
+// memory
+type parquetWriter struct {
+	w *parquet.GenericWriter[dto.News]
+}
+
+func newParquetWriter(w io.Writer) *parquetWriter {
+	return &parquetWriter{w: parquet.NewGenericWriter[dto.News](w)}
+}
+
+func (pw *parquetWriter) Write(newsStories []dto.News) error {
+	if len(newsStories) == 0 {
+		return nil
+	}
+	if _, err := pw.w.Write(newsStories); err != nil {
+		return err
+	}
+
+	return pw.w.Flush()
+}
+
+func (pw *parquetWriter) Close() error {
+	return pw.w.Close()
+}
@@ -0,0 +1,179 @@
+package extractive
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"ncrawler/pkg/helpers"
+)
+
+const (
+	// SUMMARY_WORDS is the length the extractive summary grows to
+	SUMMARY_WORDS = 150
+	// KEY_POINTS is the number of key sentences returned as bullet points
+	KEY_POINTS = 4
+	// MAX_KEY_POINT_WORDS skips overly long sentences as bullet points
+	MAX_KEY_POINT_WORDS = 45
+
+	damping    = 0.85
+	iterations = 50
+	tolerance  = 1e-6
+)
+
+var stopWords = map[string]struct{}{}
+
+func init() {
+	for _, w := range strings.Fields(`a an and are as at be been but by for from had has have he her his
+	in is it its of on or said says she that the their them they this to was were which who will with would`) {
+		stopWords[w] = struct{}{}
+	}
+}
+
+// Summary is an extractive summary made of sentences of the story
+type Summary struct {
+	Summary   string
+	KeyPoints []string
+}
+
+type sentence struct {
+	text  string
+	words map[string]struct{}
+	count int
+	index int
+	score float64
+}
+
+// Summarize ranks the sentences of the story with TextRank and returns the
+// highest ranked ones in story order as summary, and the best ranked
+// sentences of moderate length as key points
+func Summarize(story string) Summary {
+	sentences := rank(helpers.SplitSentences(story))
+	if len(sentences) == 0 {
+		return Summary{}
+	}
+
+	byScore := make([]*sentence, len(sentences))
+	copy(byScore, sentences)
+	sort.SliceStable(byScore, func(i, j int) bool { return byScore[i].score > byScore[j].score })
+
+	var (
+		picked []*sentence
+		words  int
+	)
+	for _, s := range byScore {
+		if words >= SUMMARY_WORDS {
+			break
+		}
+		picked = append(picked, s)
+		words += s.count
+	}
+
+	var keyPoints []*sentence
+	for _, s := range byScore {
+		if len(keyPoints) == KEY_POINTS {
+			break
+		}
+		if s.count <= MAX_KEY_POINT_WORDS {
+			keyPoints = append(keyPoints, s)
+		}
+	}
+
+	return Summary{
+		Summary:   strings.Join(inStoryOrder(picked), " "),
+		KeyPoints: inStoryOrder(keyPoints),
+	}
+}
+
+func inStoryOrder(sentences []*sentence) []string {
+	sorted := make([]*sentence, len(sentences))
+	copy(sorted, sentences)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].index < sorted[j].index })
+
+	texts := make([]string, 0, len(sorted))
+	for _, s := range sorted {
+		texts = append(texts, s.text)
+	}
+
+	return texts
+}
+
+// rank scores the sentences with PageRank over the sentence similarity graph
+func rank(texts []string) []*sentence {
+	sentences := make([]*sentence, 0, len(texts))
+	for i, text := range texts {
+		sentences = append(sentences, &sentence{
+			text:  text,
+			words: contentWords(text),
+			count: len(strings.Fields(text)),
+			index: i,
+			score: 1,
+		})
+	}
+
+	n := len(sentences)
+	weights := make([][]float64, n)
+	totals := make([]float64, n)
+	for i := range sentences {
+		weights[i] = make([]float64, n)
+		for j := range sentences {
+			if i != j {
+				weights[i][j] = similarity(sentences[i], sentences[j])
+				totals[i] += weights[i][j]
+			}
+		}
+	}
+
+	for it := 0; it < iterations; it++ {
+		var delta float64
+		scores := make([]float64, n)
+		for i := range sentences {
+			var sum float64
+			for j := range sentences {
+				if weights[j][i] > 0 {
+					sum += weights[j][i] / totals[j] * sentences[j].score
+				}
+			}
+			scores[i] = (1 - damping) + damping*sum
+			delta += math.Abs(scores[i] - sentences[i].score)
+		}
+		for i := range sentences {
+			sentences[i].score = scores[i]
+		}
+		if delta < tolerance {
+			break
+		}
+	}
+
+	return sentences
+}
+
+// similarity is the TextRank overlap of two sentences normalised by their length
+func similarity(a, b *sentence) float64 {
+	if len(a.words) < 2 || len(b.words) < 2 {
+		return 0
+	}
+
+	var common int
+	for w := range a.words {
+		if _, ok := b.words[w]; ok {
+			common++
+		}
+	}
+
+	return float64(common) / (math.Log(float64(len(a.words))) + math.Log(float64(len(b.words))))
+}
+
+func contentWords(text string) map[string]struct{} {
+	words := make(map[string]struct{})
+	for _, w := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r) && !unicode.Is(unicode.Mn, r) && !unicode.Is(unicode.Mc, r)
+	}) {
+		if _, stop := stopWords[w]; !stop && len([]rune(w)) > 1 {
+			words[w] = struct{}{}
+		}
+	}
+
+	return words
+}
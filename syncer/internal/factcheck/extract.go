@@ -0,0 +1,177 @@
+package factcheck
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	KIND_NUMBER     = "number"
+	KIND_PERCENTAGE = "percentage"
+	KIND_CURRENCY   = "currency"
+	KIND_DATE       = "date"
+	KIND_QUOTE      = "quote"
+)
+
+// Claim is a checkable fact found in a text
+type Claim struct {
+	Kind string `json:"kind"`
+	// Text is the claim as written
+	Text string `json:"text"`
+	// Value is the normalised form used for matching
+	Value string `json:"value"`
+}
+
+const (
+	numberPattern = `\d+(?:,\d{3})*(?:\.\d+)?`
+	scalePattern  = `(?:\s*(?:thousand|million|billion|trillion|mn|bn|m|k|crore|lakh)\b)?`
+	monthPattern  = `(?:jan(?:uary)?|feb(?:ruary)?|mar(?:ch)?|apr(?:il)?|may|june?|july?|aug(?:ust)?|sep(?:t(?:ember)?)?|oct(?:ober)?|nov(?:ember)?|dec(?:ember)?)\.?`
+)
+
+var (
+	quoteRegex      = regexp.MustCompile(`["“]([^"”]{8,})["”]`)
+	currencyRegex   = regexp.MustCompile(`(?i)(?:[$€£¥৳₹]\s?|\b(?:us\$|usd|eur|gbp|tk|taka|rs\.?)\s?)` + numberPattern + scalePattern)
+	percentageRegex = regexp.MustCompile(`(?i)` + numberPattern + `\s?(?:%|per\s?cent\b|percent\b)`)
+	dateRegex       = regexp.MustCompile(`(?i)\b(?:\d{1,2}\s` + monthPattern + `(?:\s\d{4})?|` + monthPattern + `\s\d{1,2}(?:st|nd|rd|th)?(?:,?\s\d{4})?|\d{4}-\d{2}-\d{2})\b`)
+	numberRegex     = regexp.MustCompile(numberPattern + scalePattern)
+	digitsRegex     = regexp.MustCompile(numberPattern)
+	monthRegex      = regexp.MustCompile(`(?i)` + monthPattern)
+	yearRegex       = regexp.MustCompile(`\b\d{4}\b`)
+)
+
+var months = map[string]string{
+	"jan": "01", "feb": "02", "mar": "03", "apr": "04", "may": "05", "jun": "06",
+	"jul": "07", "aug": "08", "sep": "09", "oct": "10", "nov": "11", "dec": "12",
+}
+
+var scales = map[string]string{
+	"thousand": "e3", "k": "e3",
+	"million": "e6", "mn": "e6", "m": "e6",
+	"billion": "e9", "bn": "e9",
+	"trillion": "e12",
+	"lakh":     "e5",
+	"crore":    "e7",
+}
+
+// Extract finds the quotes, currency amounts, percentages, dates and other
+// numbers in the text. Each span of the text yields at most one claim, the
+// more specific kinds take precedence over plain numbers.
+func Extract(text string) []Claim {
+	var claims []Claim
+	taken := make([]bool, len(text))
+
+	add := func(kind string, re *regexp.Regexp, group int, normalize func(string) string) {
+		for _, loc := range re.FindAllStringSubmatchIndex(text, -1) {
+			start, end := loc[0], loc[1]
+			if isTaken(taken, start, end) {
+				continue
+			}
+			markTaken(taken, start, end)
+
+			raw := text[loc[2*group]:loc[2*group+1]]
+			claims = append(claims, Claim{
+				Kind:  kind,
+				Text:  raw,
+				Value: normalize(raw),
+			})
+		}
+	}
+
+	add(KIND_QUOTE, quoteRegex, 1, normalizeQuote)
+	add(KIND_CURRENCY, currencyRegex, 0, normalizeAmount)
+	add(KIND_PERCENTAGE, percentageRegex, 0, normalizeAmount)
+	add(KIND_DATE, dateRegex, 0, normalizeDate)
+	add(KIND_NUMBER, numberRegex, 0, normalizeAmount)
+
+	return claims
+}
+
+// sourceValues returns the normalised value of every currency amount,
+// percentage, date and number of the source. Unlike Extract the spans may
+// overlap, so the year of a date or the figure of an amount supports a plain
+// number too.
+func sourceValues(text string) map[string]struct{} {
+	values := make(map[string]struct{})
+	for _, m := range []struct {
+		re        *regexp.Regexp
+		normalize func(string) string
+	}{
+		{currencyRegex, normalizeAmount},
+		{percentageRegex, normalizeAmount},
+		{dateRegex, normalizeDate},
+		{numberRegex, normalizeAmount},
+	} {
+		for _, raw := range m.re.FindAllString(text, -1) {
+			values[m.normalize(raw)] = struct{}{}
+		}
+	}
+
+	return values
+}
+
+func isTaken(taken []bool, start, end int) bool {
+	for i := start; i < end; i++ {
+		if taken[i] {
+			return true
+		}
+	}
+
+	return false
+}
+
+func markTaken(taken []bool, start, end int) {
+	for i := start; i < end; i++ {
+		taken[i] = true
+	}
+}
+
+// normalizeQuote lower cases the quote and collapses white space and
+// typographic apostrophes
+func normalizeQuote(text string) string {
+	text = strings.ReplaceAll(text, "’", "'")
+	return strings.ToLower(strings.Join(strings.Fields(text), " "))
+}
+
+// normalizeAmount reduces an amount to its number and scale, so that
+// "$1.5bn" and "1.5 billion" share the value "1.5e9"
+func normalizeAmount(text string) string {
+	loc := digitsRegex.FindStringIndex(text)
+	if loc == nil {
+		return text
+	}
+
+	number := strings.ReplaceAll(text[loc[0]:loc[1]], ",", "")
+	if f, err := strconv.ParseFloat(number, 64); err == nil {
+		number = strconv.FormatFloat(f, 'f', -1, 64)
+	}
+
+	for _, word := range strings.Fields(strings.ToLower(text[loc[1]:])) {
+		if scale, ok := scales[strings.Trim(word, ".")]; ok {
+			return number + scale
+		}
+	}
+
+	return number
+}
+
+// normalizeDate reduces a date to "MM-DD", the year is ignored as articles
+// often omit it
+func normalizeDate(text string) string {
+	lower := strings.ToLower(text)
+	if len(lower) == 10 && lower[4] == '-' {
+		return lower[5:]
+	}
+
+	month := months[monthRegex.FindString(lower)[:3]]
+	day := ""
+	for _, n := range digitsRegex.FindAllString(yearRegex.ReplaceAllString(lower, ""), -1) {
+		day = n
+		break
+	}
+	if len(day) == 1 {
+		day = "0" + day
+	}
+
+	return month + "-" + day
+}
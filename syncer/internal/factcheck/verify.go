@@ -0,0 +1,54 @@
+package factcheck
+
+import "strings"
+
+// Result is the outcome of checking generated text against its source
+type Result struct {
+	// Score is the share of claims supported by the source, 1 when there are no claims
+	Score       float64 `json:"score"`
+	Claims      int     `json:"claims"`
+	Unsupported []Claim `json:"unsupported,omitempty"`
+}
+
+// Verify checks every claim of the generated texts against the source text.
+// Numbers, percentages and currency amounts match on their normalised value,
+// dates on their month and day and quotes must appear verbatim. A claim is
+// supported by any value of the source, like a year by the year of a date.
+func Verify(source string, generated ...string) Result {
+	supported := sourceValues(source)
+	normalizedSource := normalizeQuote(source)
+
+	res := Result{Score: 1}
+	for _, text := range generated {
+		for _, c := range Extract(text) {
+			res.Claims++
+
+			var ok bool
+			if c.Kind == KIND_QUOTE {
+				ok = strings.Contains(normalizedSource, c.Value)
+			} else {
+				_, ok = supported[c.Value]
+			}
+
+			if !ok {
+				res.Unsupported = append(res.Unsupported, c)
+			}
+		}
+	}
+
+	if res.Claims > 0 {
+		res.Score = float64(res.Claims-len(res.Unsupported)) / float64(res.Claims)
+	}
+
+	return res
+}
+
+// UnsupportedTexts returns the unsupported claims as written
+func (r Result) UnsupportedTexts() []string {
+	texts := make([]string, 0, len(r.Unsupported))
+	for _, c := range r.Unsupported {
+		texts = append(texts, c.Text)
+	}
+
+	return texts
+}
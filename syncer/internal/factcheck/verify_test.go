@@ -0,0 +1,100 @@
+package factcheck
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestVerify(t *testing.T) {
+	tests := []struct {
+		name        string
+		source      string
+		summary     string
+		score       float64
+		unsupported []string
+	}{
+		{
+			name:    "year of a date",
+			source:  "The law was passed on March 5, 2024 by parliament.",
+			summary: "The law was passed in 2024.",
+			score:   1,
+		},
+		{
+			name:    "date without year",
+			source:  "The law was passed on March 5, 2024 by parliament.",
+			summary: "Parliament passed it on 5 March.",
+			score:   1,
+		},
+		{
+			name:    "day of a date",
+			source:  "Voting closes on 12 June.",
+			summary: "Voting closes on the 12th day of the month, 12 June.",
+			score:   1,
+		},
+		{
+			name:    "figure of an amount",
+			source:  "The project will cost $1.5bn over ten years.",
+			summary: "It costs 1.5 billion.",
+			score:   1,
+		},
+		{
+			name:        "figure of a percentage",
+			source:      "Inflation rose to 9.7% in May.",
+			summary:     "Inflation reached 9.7 percent, up from 9.",
+			score:       0.5,
+			unsupported: []string{"9"},
+		},
+		{
+			name:        "unsupported numbers",
+			source:      "The law was passed on March 5, 2024 by parliament.",
+			summary:     "The law was passed in 2023 with 300 votes.",
+			score:       0,
+			unsupported: []string{"2023", "300"},
+		},
+		{
+			name:    "verbatim quote",
+			source:  `The minister said “we will not back down” on Monday.`,
+			summary: `The minister said "we will not back down".`,
+			score:   1,
+		},
+		{
+			name:        "invented quote",
+			source:      "The minister spoke on Monday.",
+			summary:     `The minister said "we will not back down".`,
+			score:       0,
+			unsupported: []string{"we will not back down"},
+		},
+		{
+			name:    "no claims",
+			source:  "The minister spoke on Monday.",
+			summary: "The minister spoke.",
+			score:   1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := Verify(tt.source, tt.summary)
+			if res.Score != tt.score {
+				t.Errorf("Verify() score = %v, want %v (unsupported %q)", res.Score, tt.score, res.UnsupportedTexts())
+			}
+			if got := res.UnsupportedTexts(); !slices.Equal(got, tt.unsupported) && len(got)+len(tt.unsupported) > 0 {
+				t.Errorf("Verify() unsupported = %q, want %q", got, tt.unsupported)
+			}
+		})
+	}
+}
+
+func TestExtract(t *testing.T) {
+	claims := Extract("On March 5, 2024 the fund paid $2.5 million, 40% of its 6,250,000 budget.")
+
+	want := []Claim{
+		{Kind: KIND_CURRENCY, Text: "$2.5 million", Value: "2.5e6"},
+		{Kind: KIND_PERCENTAGE, Text: "40%", Value: "40"},
+		{Kind: KIND_DATE, Text: "March 5, 2024", Value: "03-05"},
+		{Kind: KIND_NUMBER, Text: "6,250,000", Value: "6250000"},
+	}
+	if !slices.Equal(claims, want) {
+		t.Errorf("Extract() = %+v, want %+v", claims, want)
+	}
+}
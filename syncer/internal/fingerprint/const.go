@@ -0,0 +1,15 @@
+package fingerprint
+
+import "time"
+
+const (
+	// SHINGLE_WORDS is the number of words of the shingles hashed into the fingerprint
+	SHINGLE_WORDS = 3
+	// MIN_WORDS is the shortest text that gets a fingerprint
+	MIN_WORDS = 30
+	// MAX_DISTANCE is the number of differing bits up to which two stories
+	// are near duplicates
+	MAX_DISTANCE = 4
+	// WINDOW is how far back near duplicates are looked for
+	WINDOW = 30 * 24 * time.Hour
+)
@@ -0,0 +1,59 @@
+// Package fingerprint finds near duplicate stories, like wire copy
+// republished with small edits, by the SimHash of their text
+package fingerprint
+
+import (
+	"hash/fnv"
+	"math/bits"
+	"strings"
+	"unicode"
+)
+
+// SimHash returns the 64 bit SimHash of the word shingles of the normalized
+// text. Texts shorter than MIN_WORDS get 0, meaning no fingerprint.
+func SimHash(text string) int64 {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	if len(words) < MIN_WORDS {
+		return 0
+	}
+
+	var weights [64]int
+	for i := 0; i+SHINGLE_WORDS <= len(words); i++ {
+		h := fnv.New64a()
+		h.Write([]byte(strings.Join(words[i:i+SHINGLE_WORDS], " ")))
+		sum := h.Sum64()
+		for b := 0; b < 64; b++ {
+			if sum&(1<<b) != 0 {
+				weights[b]++
+			} else {
+				weights[b]--
+			}
+		}
+	}
+
+	var fp uint64
+	for b := 0; b < 64; b++ {
+		if weights[b] > 0 {
+			fp |= 1 << b
+		}
+	}
+
+	return int64(fp)
+}
+
+// Distance returns the number of bits two fingerprints differ in
+func Distance(a, b int64) int {
+	return bits.OnesCount64(uint64(a ^ b))
+}
+
+// Similarity returns the share of equal bits of two fingerprints
+func Similarity(a, b int64) float64 {
+	return 1 - float64(Distance(a, b))/64
+}
+
+// IsNearDuplicate reports whether two fingerprints are at most MAX_DISTANCE apart
+func IsNearDuplicate(a, b int64) bool {
+	return a != 0 && b != 0 && Distance(a, b) <= MAX_DISTANCE
+}
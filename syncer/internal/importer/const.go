@@ -0,0 +1,19 @@
+package importer
+
+const (
+	FORMAT_CSV    = "csv"
+	FORMAT_JSON   = "json"
+	FORMAT_NDJSON = "ndjson"
+
+	// Lengths of the Postgres columns
+	MAX_SOURCE_NAME_LENGTH = 100
+	MAX_CATEGORY_LENGTH    = 50
+	MAX_HEADLINE_LENGTH    = 255
+
+	// QUERY_PAGE_SIZE is the number of news stories read at a time from a
+	// datastore
+	QUERY_PAGE_SIZE = 500
+)
+
+// Formats are the supported import formats
+var Formats = []string{FORMAT_CSV, FORMAT_JSON, FORMAT_NDJSON}
@@ -0,0 +1,120 @@
+package importer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"ncrawler/internal/definition"
+	"ncrawler/internal/dto"
+
+	"github.com/gocarina/gocsv"
+)
+
+// Reader streams the news stories of a dump to fn one at a time, an error
+// returned by fn stops the reading
+type Reader interface {
+	ForEach(fn func(news dto.News) error) error
+}
+
+// NewReader returns the reader of the format
+func NewReader(format string, r io.Reader) (Reader, error) {
+	switch format {
+	case FORMAT_CSV:
+		return csvReader{r: r}, nil
+	case FORMAT_JSON:
+		return jsonReader{r: r}, nil
+	case FORMAT_NDJSON:
+		return ndjsonReader{r: r}, nil
+	}
+
+	return nil, fmt.Errorf("unknown import format %s, use one of %s", format, strings.Join(Formats, ", "))
+}
+
+// csvReader reads a CSV with the columns of dto.News, like the export writes
+type csvReader struct {
+	r io.Reader
+}
+
+func (cr csvReader) ForEach(fn func(news dto.News) error) error {
+	return gocsv.UnmarshalToCallbackWithError(cr.r, fn)
+}
+
+// jsonReader reads a JSON array of news stories one at a time
+type jsonReader struct {
+	r io.Reader
+}
+
+func (jr jsonReader) ForEach(fn func(news dto.News) error) error {
+	dec := json.NewDecoder(jr.r)
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('[') {
+		return fmt.Errorf("error reading JSON: expected an array of news")
+	}
+
+	for record := 1; dec.More(); record++ {
+		var news dto.News
+		if err := dec.Decode(&news); err != nil {
+			return fmt.Errorf("error decoding record %d: %w", record, err)
+		}
+		if err := fn(news); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ndjsonReader reads one news story per line
+type ndjsonReader struct {
+	r io.Reader
+}
+
+func (nr ndjsonReader) ForEach(fn func(news dto.News) error) error {
+	dec := json.NewDecoder(nr.r)
+	for record := 1; ; record++ {
+		var news dto.News
+		err := dec.Decode(&news)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("error decoding record %d: %w", record, err)
+		}
+		if err := fn(news); err != nil {
+			return err
+		}
+	}
+}
+
+// storeReader reads all the news stories of a datastore page by page, in
+// source link order. It pages through the backup store so quarantined and
+// retracted stories are read with their flags like any other.
+type storeReader struct {
+	store definition.BackupStore
+}
+
+// NewStoreReader returns the reader of all the news stories of the store
+func NewStoreReader(store definition.BackupStore) Reader {
+	return storeReader{store: store}
+}
+
+func (sr storeReader) ForEach(fn func(news dto.News) error) error {
+	var afterLink string
+	for {
+		page, err := sr.store.GetNewsAfter(afterLink, QUERY_PAGE_SIZE)
+		if err != nil {
+			return err
+		}
+		if len(page) == 0 {
+			return nil
+		}
+		for _, news := range page {
+			if err := fn(news); err != nil {
+				return err
+			}
+		}
+		afterLink = page[len(page)-1].SourceLink
+	}
+}
@@ -0,0 +1,74 @@
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+	"unicode/utf8"
+
+	"ncrawler/internal/dto"
+	"ncrawler/internal/revision"
+	"ncrawler/internal/urlcanon"
+)
+
+// Normalize canonicalises the links of the news story like the crawler does
+// and sets the fields a dump may miss. A story without crawl time gets its
+// publish time, or now when it has none.
+func Normalize(news *dto.News, now time.Time) {
+	list := dto.NewsList{*news}
+	list.Sanitize()
+	*news = list[0]
+
+	news.SourceLink = urlcanon.Normalize(news.SourceLink)
+	news.Id = news.SourceLink
+	if news.DuplicateOf != "" {
+		news.DuplicateOf = urlcanon.Normalize(news.DuplicateOf)
+	}
+
+	if news.CrawledAt.IsZero() {
+		news.CrawledAt = news.PublishedAt
+	}
+	if news.CrawledAt.IsZero() {
+		news.CrawledAt = now.UTC()
+	}
+	if news.ContentHash == "" {
+		news.ContentHash = revision.ContentHash(*news)
+	}
+	if news.Revision < 1 {
+		news.Revision = 1
+	}
+}
+
+// Validate checks the news story can be stored in every datastore
+func Validate(news dto.News) error {
+	var errs []error
+
+	u, err := url.Parse(news.SourceLink)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		errs = append(errs, fmt.Errorf("source_link %q is not an http link", news.SourceLink))
+	}
+	if news.Headline == "" {
+		errs = append(errs, fmt.Errorf("headline is empty"))
+	}
+	if utf8.RuneCountInString(news.Headline) > MAX_HEADLINE_LENGTH {
+		errs = append(errs, fmt.Errorf("headline is longer than %d characters", MAX_HEADLINE_LENGTH))
+	}
+	if news.Story == "" {
+		errs = append(errs, fmt.Errorf("story is empty"))
+	}
+	if news.SourceName == "" || utf8.RuneCountInString(news.SourceName) > MAX_SOURCE_NAME_LENGTH {
+		errs = append(errs, fmt.Errorf("source_name is empty or longer than %d characters", MAX_SOURCE_NAME_LENGTH))
+	}
+	if news.Category == "" || utf8.RuneCountInString(news.Category) > MAX_CATEGORY_LENGTH {
+		errs = append(errs, fmt.Errorf("category is empty or longer than %d characters", MAX_CATEGORY_LENGTH))
+	}
+	if news.Language != "" && len(news.Language) != 2 {
+		errs = append(errs, fmt.Errorf("language %q is not an ISO 639-1 code", news.Language))
+	}
+	if news.SummaryMethod != "" && news.SummaryMethod != dto.SUMMARY_METHOD_LLM && news.SummaryMethod != dto.SUMMARY_METHOD_EXTRACTIVE {
+		errs = append(errs, fmt.Errorf("unknown summary_method %q", news.SummaryMethod))
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,101 @@
+package lang
+
+import (
+	"strings"
+	"unicode"
+)
+
+const (
+	ENGLISH   = "en"
+	BANGLA    = "bn"
+	HINDI     = "hi"
+	ARABIC    = "ar"
+	UNDEFINED = "und"
+)
+
+// names are the English names of the languages, used in prompts
+var names = map[string]string{
+	ENGLISH: "English",
+	BANGLA:  "Bangla",
+	HINDI:   "Hindi",
+	ARABIC:  "Arabic",
+}
+
+// scripts map a Unicode script to the language written with it
+var scripts = []struct {
+	table    *unicode.RangeTable
+	language string
+}{
+	{unicode.Bengali, BANGLA},
+	{unicode.Devanagari, HINDI},
+	{unicode.Arabic, ARABIC},
+}
+
+var englishWords = map[string]struct{}{}
+
+func init() {
+	for _, w := range strings.Fields("the of and to in a is that for on was with said by as at from it has have are") {
+		englishWords[w] = struct{}{}
+	}
+}
+
+// Name returns the English name of the language code
+func Name(code string) string {
+	if name, ok := names[code]; ok {
+		return name
+	}
+
+	return code
+}
+
+// Detect returns the ISO 639-1 code of the language of the text. The
+// language is chosen by the dominant script, Latin text is English when
+// common English words make up a fair share of it.
+func Detect(text string) string {
+	counts := make([]int, len(scripts))
+	var letters, latin int
+
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		if unicode.Is(unicode.Latin, r) {
+			latin++
+			continue
+		}
+		for i, s := range scripts {
+			if unicode.Is(s.table, r) {
+				counts[i]++
+				break
+			}
+		}
+	}
+
+	if letters == 0 {
+		return UNDEFINED
+	}
+
+	best, bestCount := UNDEFINED, latin
+	for i, s := range scripts {
+		if counts[i] > bestCount {
+			best, bestCount = s.language, counts[i]
+		}
+	}
+	if best != UNDEFINED {
+		return best
+	}
+
+	words := strings.Fields(strings.ToLower(text))
+	var common int
+	for _, w := range words {
+		if _, ok := englishWords[strings.Trim(w, `.,;:!?"'“”‘’()`)]; ok {
+			common++
+		}
+	}
+	if len(words) > 0 && float64(common)/float64(len(words)) >= 0.05 {
+		return ENGLISH
+	}
+
+	return UNDEFINED
+}
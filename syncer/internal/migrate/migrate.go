@@ -0,0 +1,205 @@
+// Package migrate applies the versioned schema migrations. The applied
+// version is kept in a schema_migrations table compatible with the
+// golang-migrate CLI.
+package migrate
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+var (
+	// ErrDirty is returned when a migration failed halfway and the schema
+	// needs to be fixed by hand
+	ErrDirty = errors.New("schema is dirty")
+	// ErrOutdated is returned when migrations are pending
+	ErrOutdated = errors.New("schema is out of date")
+)
+
+var fileRegex = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+// Migration is a versioned change of the schema and the script reverting it
+type Migration struct {
+	Version uint
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Driver runs the migrations on a database
+type Driver interface {
+	// Version returns the applied version, 0 when none, and whether the last
+	// migration failed halfway
+	Version() (uint, bool, error)
+	// Apply runs the script and records the version in one transaction
+	Apply(script string, version uint) error
+}
+
+// Status is the applied version of the schema and the pending migrations
+type Status struct {
+	Current uint
+	Latest  uint
+	Dirty   bool
+	Applied []Migration
+	Pending []Migration
+}
+
+// Migrator applies the migrations of a directory to a database
+type Migrator struct {
+	driver     Driver
+	migrations []Migration
+}
+
+// New loads the migrations of the directory
+func New(driver Driver, fsys fs.FS, dir string) (*Migrator, error) {
+	migrations, err := Load(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{driver: driver, migrations: migrations}, nil
+}
+
+// Load reads the migrations of the directory sorted by version
+func Load(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("error reading migrations: %w", err)
+	}
+
+	byVersion := map[uint]*Migration{}
+	for _, entry := range entries {
+		match := fileRegex.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+
+		version, err := strconv.ParseUint(match[1], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing version of %s: %w", entry.Name(), err)
+		}
+		script, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("error reading migration %s: %w", entry.Name(), err)
+		}
+
+		m, ok := byVersion[uint(version)]
+		if !ok {
+			m = &Migration{Version: uint(version), Name: match[2]}
+			byVersion[uint(version)] = m
+		}
+		if m.Name != match[2] {
+			return nil, fmt.Errorf("migrations %s and %s share version %d", m.Name, match[2], version)
+		}
+		if match[3] == "up" {
+			m.Up = string(script)
+		} else {
+			m.Down = string(script)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up script", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return migrations, nil
+}
+
+// Status compares the applied version with the migrations
+func (m *Migrator) Status() (Status, error) {
+	current, dirty, err := m.driver.Version()
+	if err != nil {
+		return Status{}, err
+	}
+
+	status := Status{Current: current, Dirty: dirty}
+	for _, mig := range m.migrations {
+		status.Latest = mig.Version
+		if mig.Version <= current {
+			status.Applied = append(status.Applied, mig)
+		} else {
+			status.Pending = append(status.Pending, mig)
+		}
+	}
+
+	return status, nil
+}
+
+// Up applies the pending migrations in order and returns them
+func (m *Migrator) Up() ([]Migration, error) {
+	status, err := m.Status()
+	if err != nil {
+		return nil, err
+	}
+	if status.Dirty {
+		return nil, fmt.Errorf("%w at version %d", ErrDirty, status.Current)
+	}
+
+	var applied []Migration
+	for _, mig := range status.Pending {
+		if err := m.driver.Apply(mig.Up, mig.Version); err != nil {
+			return applied, fmt.Errorf("error applying migration %d_%s: %w", mig.Version, mig.Name, err)
+		}
+		applied = append(applied, mig)
+	}
+
+	return applied, nil
+}
+
+// Down reverts the last steps applied migrations and returns them
+func (m *Migrator) Down(steps int) ([]Migration, error) {
+	status, err := m.Status()
+	if err != nil {
+		return nil, err
+	}
+	if status.Dirty {
+		return nil, fmt.Errorf("%w at version %d", ErrDirty, status.Current)
+	}
+
+	var reverted []Migration
+	for i := len(status.Applied) - 1; i >= 0 && len(reverted) < steps; i-- {
+		mig := status.Applied[i]
+		if mig.Down == "" {
+			return reverted, fmt.Errorf("migration %d_%s has no down script", mig.Version, mig.Name)
+		}
+
+		var previous uint
+		if i > 0 {
+			previous = status.Applied[i-1].Version
+		}
+		if err := m.driver.Apply(mig.Down, previous); err != nil {
+			return reverted, fmt.Errorf("error reverting migration %d_%s: %w", mig.Version, mig.Name, err)
+		}
+		reverted = append(reverted, mig)
+	}
+
+	return reverted, nil
+}
+
+// CheckCurrent returns ErrOutdated when migrations are pending and ErrDirty
+// when the last one failed halfway
+func (m *Migrator) CheckCurrent() error {
+	status, err := m.Status()
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case status.Dirty:
+		return fmt.Errorf("%w at version %d, fix it and run migrate", ErrDirty, status.Current)
+	case status.Current < status.Latest:
+		return fmt.Errorf("%w: at version %d of %d, run migrate up", ErrOutdated, status.Current, status.Latest)
+	}
+
+	return nil
+}
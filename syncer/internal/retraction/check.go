@@ -0,0 +1,90 @@
+// Package retraction finds the articles the publishers deleted or unpublished
+package retraction
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"ncrawler/internal/urlcanon"
+	"ncrawler/pkg/helpers"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+var client = &http.Client{Timeout: CHECK_TIMEOUT}
+
+// Result is the outcome of the check of an article link
+type Result struct {
+	Link       string
+	Retracted  bool
+	Reason     string
+	StatusCode int
+	// FinalURL is the link after following the redirects
+	FinalURL string
+}
+
+// Check fetches the article link and reports whether the article was
+// retracted, on a 404, a 410 or a redirect to the site root. A 200 counts
+// only on a site with a removal marker, when the page shows its removal
+// notice and no article body, as soft error pages and section fronts are too
+// easily mistaken for a removal otherwise. Network errors and unexpected status codes, like a 503 or a 429, are
+// inconclusive and returned as an error.
+func Check(link string) (Result, error) {
+	req, err := http.NewRequest(http.MethodGet, link, nil)
+	if err != nil {
+		return Result{}, fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("User-Agent", helpers.GetRandomUserAgent())
+
+	res, err := client.Do(req)
+	if err != nil {
+		return Result{}, fmt.Errorf("error fetching %s: %w", link, err)
+	}
+	defer res.Body.Close()
+
+	result := Result{Link: link, StatusCode: res.StatusCode, FinalURL: res.Request.URL.String()}
+	switch {
+	case res.StatusCode == http.StatusNotFound:
+		result.Retracted, result.Reason = true, REASON_NOT_FOUND
+	case res.StatusCode == http.StatusGone:
+		result.Retracted, result.Reason = true, REASON_GONE
+	case res.StatusCode != http.StatusOK:
+		return result, fmt.Errorf("unexpected status %d fetching %s", res.StatusCode, link)
+	case isHomeRedirect(link, result.FinalURL):
+		result.Retracted, result.Reason = true, REASON_HOME_REDIRECT
+	default:
+		marker, ok := markerOf(res.Request.URL.Hostname())
+		if !ok {
+			break
+		}
+		doc, err := goquery.NewDocumentFromReader(io.LimitReader(res.Body, MAX_BODY_BYTES))
+		if err != nil {
+			return result, fmt.Errorf("error parsing %s: %w", link, err)
+		}
+		if marker.isRemovalPage(doc) {
+			result.Retracted, result.Reason = true, REASON_REMOVED_NOTICE
+		}
+	}
+
+	return result, nil
+}
+
+// isHomeRedirect reports whether the article link was redirected to the root
+// of its site
+func isHomeRedirect(link, finalURL string) bool {
+	if urlcanon.Normalize(link) == urlcanon.Normalize(finalURL) {
+		return false
+	}
+
+	original, errO := url.Parse(link)
+	final, errF := url.Parse(finalURL)
+	if errO != nil || errF != nil {
+		return false
+	}
+
+	sameSite := strings.TrimPrefix(original.Hostname(), "www.") == strings.TrimPrefix(final.Hostname(), "www.")
+	return sameSite && strings.Trim(original.Path, "/") != "" && strings.Trim(final.Path, "/") == ""
+}
@@ -0,0 +1,21 @@
+package retraction
+
+import "time"
+
+const (
+	// REASON_NOT_FOUND is set when the article link answers 404
+	REASON_NOT_FOUND = "not_found"
+	// REASON_GONE is set when the article link answers 410
+	REASON_GONE = "gone"
+	// REASON_HOME_REDIRECT is set when the article link redirects to the root
+	// of its site
+	REASON_HOME_REDIRECT = "home_redirect"
+	// REASON_REMOVED_NOTICE is set when the article link answers 200 with the
+	// removal notice of its site in place of the article
+	REASON_REMOVED_NOTICE = "removed_notice"
+
+	// CHECK_TIMEOUT is how long a check of an article link may take
+	CHECK_TIMEOUT = 30 * time.Second
+	// MAX_BODY_BYTES is the size of the page read to look for a removal notice
+	MAX_BODY_BYTES = 2 << 20
+)
@@ -0,0 +1,80 @@
+package retraction
+
+import (
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// removalMarker tells apart the page a site serves with a 200 in place of a
+// removed article: the phrases of its notice, matched in the title and the
+// visible text, and the article body the notice page lacks
+type removalMarker struct {
+	notices []string
+	body    string
+}
+
+// removalMarkers are the markers of the crawled sites by host, the article
+// bodies are the ones the scrapers read
+var removalMarkers = map[string]removalMarker{
+	"bbc.com": {
+		notices: []string{"sorry, we couldn't find that page", "this page cannot be found"},
+		body:    "article div[data-component='text-block']",
+	},
+	"bbc.co.uk": {
+		notices: []string{"sorry, we couldn't find that page", "this page cannot be found"},
+		body:    "article div[data-component='text-block']",
+	},
+	"aljazeera.com": {
+		notices: []string{"the page you were looking for doesn't exist", "page not found"},
+		body:    "main div.wysiwyg--all-content",
+	},
+	"reuters.com": {
+		notices: []string{"this article is no longer available", "page not found"},
+		body:    "article div.article-body__paragraph__2-BtD",
+	},
+	"cnn.com": {
+		notices: []string{"the page you requested could not be found", "page not found"},
+		body:    "div.article__content",
+	},
+	"theguardian.com": {
+		notices: []string{"the page you have requested does not exist", "this article has been removed"},
+		body:    "div.content__article-body",
+	},
+}
+
+// markerOf returns the marker of the host or of its parent domain, like
+// cnn.com for edition.cnn.com
+func markerOf(host string) (removalMarker, bool) {
+	host = strings.TrimPrefix(strings.ToLower(host), "www.")
+	for {
+		if m, ok := removalMarkers[host]; ok {
+			return m, true
+		}
+
+		_, parent, found := strings.Cut(host, ".")
+		if !found || !strings.Contains(parent, ".") {
+			return removalMarker{}, false
+		}
+		host = parent
+	}
+}
+
+// isRemovalPage reports whether the page has no article body and shows the
+// removal notice of the site
+func (m removalMarker) isRemovalPage(doc *goquery.Document) bool {
+	if strings.TrimSpace(doc.Find(m.body).Text()) != "" {
+		return false
+	}
+
+	title := strings.ToLower(doc.Find("title").First().Text())
+	doc.Find("script, style, noscript").Remove()
+	text := strings.ToLower(strings.Join(strings.Fields(doc.Find("body").Text()), " "))
+	for _, notice := range m.notices {
+		if strings.Contains(title, notice) || strings.Contains(text, notice) {
+			return true
+		}
+	}
+
+	return false
+}
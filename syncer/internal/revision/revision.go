@@ -0,0 +1,66 @@
+// Package revision detects changes of re-crawled articles and describes them
+// as sentence diffs
+package revision
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"ncrawler/internal/dto"
+	"ncrawler/pkg/helpers"
+)
+
+// ContentHash returns the hash of the normalized headline and story, white
+// space and case changes do not count as changes
+func ContentHash(n dto.News) string {
+	normalized := strings.ToLower(strings.Join(strings.Fields(n.Headline+"\n"+n.Story), " "))
+	sum := sha256.Sum256([]byte(normalized))
+
+	return hex.EncodeToString(sum[:])
+}
+
+// Diff describes the changes from the old to the new version of the news:
+// the headline change, then the removed sentences prefixed by "- " and the
+// added ones by "+ " in story order
+func Diff(old, new dto.News) string {
+	var sb strings.Builder
+	if old.Headline != new.Headline {
+		fmt.Fprintf(&sb, "- headline: %s\n+ headline: %s\n", old.Headline, new.Headline)
+	}
+
+	a, b := helpers.SplitSentences(old.Story), helpers.SplitSentences(new.Story)
+
+	// longest common subsequence of the sentences
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			fmt.Fprintf(&sb, "- %s\n", a[i])
+			i++
+		default:
+			fmt.Fprintf(&sb, "+ %s\n", b[j])
+			j++
+		}
+	}
+
+	return sb.String()
+}
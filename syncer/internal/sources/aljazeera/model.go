@@ -31,6 +31,7 @@ type JsonLD struct {
 	MainEntityOfPage string    `json:"mainEntityOfPage"`
 	Headline         string    `json:"headline"`
 	DatePublished    time.Time `json:"datePublished"`
+	DateModified     time.Time `json:"dateModified"`
 	Description      string    `json:"description"`
 	Image []struct {
 		URL    string `json:"url"`
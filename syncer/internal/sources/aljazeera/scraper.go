@@ -6,6 +6,7 @@ import (
 
 	"ncrawler/internal/dto"
 	"ncrawler/internal/sources"
+	"ncrawler/internal/urlcanon"
 	"ncrawler/pkg/helpers"
 
 	"github.com/gocolly/colly/v2"
@@ -24,7 +25,7 @@ func (s *scraper) GetLatest() (dto.NewsList, error) {
 	// Save it to db
 	// Get a connection pool to the database
 	dbPool := helpers.GetDbPool()
-	newNewsLink, err := dbPool.News.DetectNewLinks(nLinks)
+	newNewsLink, err := dbPool.News.DetectNewLinks(urlcanon.NormalizeAll(nLinks))
 	if err != nil {
 		return nil, err
 	}
@@ -32,6 +33,14 @@ func (s *scraper) GetLatest() (dto.NewsList, error) {
 	return s.getNewsDetails(newNewsLink)
 }
 
+func (s *scraper) GetNews(links []string) (dto.NewsList, error) {
+	return s.getNewsDetails(links)
+}
+
+func (s *scraper) GetName() string {
+	return sources.SOURCE_ALJAZEERA
+}
+
 func (s *scraper) getLatestNewsLink() ([]string, error) {
 	var (
 		c         *colly.Collector
@@ -103,13 +112,14 @@ func (s *scraper) getNewsDetails(nLinks []string) ([]dto.News, error) {
 
 	c.OnHTML("html", func(e *colly.HTMLElement) {
 		var newsStory dto.News
+		link := urlcanon.FromPage(e.Request.URL.String(), e.ChildAttr("link[rel='canonical']", "href"), e.ChildAttr("meta[property='og:url']", "content"))
 		e.ForEach("main", func(_ int, el *colly.HTMLElement) {
 			newsStory = dto.News{
-				Id:         el.Request.URL.String(),
+				Id:         link,
 				SourceName: sources.SOURCE_ALJAZEERA,
 				Category:   sources.CATEGORY_WORLD,
 				Headline:   el.ChildText("h1"),
-				SourceLink: el.Request.URL.String(),
+				SourceLink: link,
 			}
 
 			el.ForEach("div.wysiwyg--all-content", func(_ int, tb *colly.HTMLElement) {
@@ -125,7 +135,8 @@ func (s *scraper) getNewsDetails(nLinks []string) ([]dto.News, error) {
 			}
 
 			newsStory.MetaDescription = jl.Description
-			newsStory.Date = helpers.TimeToStr(jl.DatePublished)
+			newsStory.PublishedAt = jl.DatePublished
+			newsStory.ModifiedAt = jl.DateModified
 			if len(jl.Image) > 0 {
 				newsStory.ImageLink = jl.Image[0].URL
 			}
@@ -6,6 +6,7 @@ import (
 
 	"ncrawler/internal/dto"
 	"ncrawler/internal/sources"
+	"ncrawler/internal/urlcanon"
 	"ncrawler/pkg/helpers"
 
 	"github.com/gocolly/colly/v2"
@@ -24,7 +25,7 @@ func (s *scraper) GetLatest() (dto.NewsList, error) {
 	// Save it to db
 	// Get a connection pool to the database
 	dbPool := helpers.GetDbPool()
-	newNewsLink, err := dbPool.News.DetectNewLinks(nLinks)
+	newNewsLink, err := dbPool.News.DetectNewLinks(urlcanon.NormalizeAll(nLinks))
 	if err != nil {
 		return nil, err
 	}
@@ -32,6 +33,14 @@ func (s *scraper) GetLatest() (dto.NewsList, error) {
 	return s.getNewsDetails(newNewsLink)
 }
 
+func (s *scraper) GetNews(links []string) (dto.NewsList, error) {
+	return s.getNewsDetails(links)
+}
+
+func (s *scraper) GetName() string {
+	return sources.SOURCE_BBC
+}
+
 func (s *scraper) getLatestNewsLink() ([]string, error) {
 	var (
 		c         *colly.Collector
@@ -101,13 +110,14 @@ func (s *scraper) getNewsDetails(nLinks []string) ([]dto.News, error) {
 
 	c.OnHTML("html", func(e *colly.HTMLElement) {
 		var newsStory dto.News
+		link := urlcanon.FromPage(e.Request.URL.String(), e.ChildAttr("link[rel='canonical']", "href"), e.ChildAttr("meta[property='og:url']", "content"))
 		e.ForEach("article", func(_ int, el *colly.HTMLElement) {
 			newsStory = dto.News{
-				Id:         el.Request.URL.String(),
+				Id:         link,
 				SourceName: sources.SOURCE_BBC,
 				Category:   sources.CATEGORY_WORLD,
 				Headline:   el.ChildText("h1"),
-				SourceLink: el.Request.URL.String(),
+				SourceLink: link,
 			}
 
 			el.ForEach("div[data-component='text-block']", func(_ int, tb *colly.HTMLElement) {
@@ -123,7 +133,8 @@ func (s *scraper) getNewsDetails(nLinks []string) ([]dto.News, error) {
 			}
 
 			newsStory.MetaDescription = jl.Description
-			newsStory.Date = helpers.TimeToStr(jl.DatePublished)
+			newsStory.PublishedAt = jl.DatePublished
+			newsStory.ModifiedAt = jl.DateModified
 			newsStory.ImageLink = jl.Image.URL
 		})
 
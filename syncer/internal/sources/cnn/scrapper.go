@@ -4,9 +4,11 @@ import (
 	"fmt"
 	"log/slog"
 	"strings"
+	"time"
 
 	"ncrawler/internal/dto"
 	"ncrawler/internal/sources"
+	"ncrawler/internal/urlcanon"
 	"ncrawler/pkg/helpers"
 
 	"github.com/gocolly/colly/v2"
@@ -25,7 +27,7 @@ func (s *scraper) GetLatest() (dto.NewsList, error) {
 	// Save it to db
 	// Get a connection pool to the database
 	dbPool := helpers.GetDbPool()
-	newNewsLink, err := dbPool.News.DetectNewLinks(nLinks)
+	newNewsLink, err := dbPool.News.DetectNewLinks(urlcanon.NormalizeAll(nLinks))
 	if err != nil {
 		return nil, err
 	}
@@ -33,6 +35,14 @@ func (s *scraper) GetLatest() (dto.NewsList, error) {
 	return s.getNewsDetails(newNewsLink)
 }
 
+func (s *scraper) GetNews(links []string) (dto.NewsList, error) {
+	return s.getNewsDetails(links)
+}
+
+func (s *scraper) GetName() string {
+	return sources.SOURCE_CNN
+}
+
 func (s *scraper) getLatestNewsLink() ([]string, error) {
 	var (
 		c         *colly.Collector
@@ -98,18 +108,27 @@ func (s *scraper) getNewsDetails(nLinks []string) ([]dto.News, error) {
 	})
 
 	c.OnHTML("html", func(e *colly.HTMLElement) {
+		link := urlcanon.FromPage(e.Request.URL.String(), e.ChildAttr("link[rel='canonical']", "href"), e.ChildAttr("meta[property='og:url']", "content"))
 		newsStory := dto.News{
-			Id:              e.Request.URL.String(),
+			Id:              link,
 			SourceName:      sources.SOURCE_CNN,
 			Category:        sources.CATEGORY_WORLD,
-			SourceLink:      e.Request.URL.String(),
+			SourceLink:      link,
 			Headline:        strings.TrimSpace(e.ChildText("h1#maincontent")),
 			MetaDescription: e.ChildAttr("meta[name='description']", "content"),
 			ImageLink:       e.ChildAttr("meta[property='og:image']", "content"),
-			Date:            helpers.GetCurrentTimestamp(),
 			Story:           e.ChildText("div.article__content"),
 		}
 
+		// <meta property="article:published_time" content="2024-10-21T09:12:03.000Z">
+		if published, err := time.Parse(time.RFC3339, e.ChildAttr("meta[property='article:published_time']", "content")); err == nil {
+			newsStory.PublishedAt = published
+		}
+		// <meta property="article:modified_time" content="2024-10-21T13:42:34.000Z">
+		if modified, err := time.Parse(time.RFC3339, e.ChildAttr("meta[property='article:modified_time']", "content")); err == nil {
+			newsStory.ModifiedAt = modified
+		}
+
 		newsStories = append(newsStories, newsStory)
 	})
 
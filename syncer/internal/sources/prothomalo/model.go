@@ -5,6 +5,7 @@ import (
 	"log/slog"
 	"ncrawler/internal/dto"
 	"ncrawler/internal/sources"
+	"ncrawler/internal/urlcanon"
 	"net/url"
 	"strings"
 	"time"
@@ -146,18 +147,24 @@ func (nr *NewsResponse) ToStories() []dto.News {
 	var newsStories []dto.News
 
 	for _, item := range nr.Data.Collection.Items {
+		link := urlcanon.FromPage(item.Story.URL, item.Story.CanonicalURL, "")
 		story := dto.News{
-			Id:              item.Story.URL,
-			SourceLink:      item.Story.URL,
+			Id:              link,
+			SourceLink:      link,
 			SourceName:      sources.SOURCE_PROTHOMALO,
 			Category:        getCategory(item.Story.URL),
 			Headline:        item.Story.Headline,
 			ImageLink:       fmt.Sprintf(baseImageURLFmt, item.Story.HeroImageS3Key),
-			Date:            getDateString(item.Story.PublishedAt),
 			Story:           getStory(item.Story.Cards),
 			MetaDescription: item.Story.Seo.MetaDescription,
 			MetaKeywords:    strings.Join(item.Story.Seo.MetaKeywords, ", "),
 		}
+		if item.Story.PublishedAt > 0 {
+			story.PublishedAt = time.UnixMilli(item.Story.PublishedAt)
+		}
+		if item.Story.ContentUpdatedAt > 0 {
+			story.ModifiedAt = time.UnixMilli(item.Story.ContentUpdatedAt)
+		}
 
 		newsStories = append(newsStories, story)
 	}
@@ -181,18 +188,6 @@ func getCategory(urlStr string) string {
 	return ""
 }
 
-func getDateString(timestamp int64) string {
-	// The timestamp in milliseconds
-	// Convert milliseconds to seconds
-	seconds := timestamp / 1000
-
-	// Convert to time.Time
-	t := time.Unix(seconds, 0)
-
-	// Format the time to a readable string
-	return t.Format(time.RFC3339)
-}
-
 func getStory(cards []Card) string {
 	var story string
 	for _, card := range cards {
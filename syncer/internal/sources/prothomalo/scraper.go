@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"log/slog"
 	"ncrawler/internal/dto"
+	"ncrawler/internal/sources"
 	"ncrawler/pkg/helpers"
 
 	"github.com/gocolly/colly/v2"
@@ -48,3 +49,30 @@ func (s *scraper) GetLatest() (dto.NewsList, error) {
 
 	return newsStories, nil
 }
+
+// GetNews returns the given articles found in the latest collection, the
+// api serves no single article
+func (s *scraper) GetNews(links []string) (dto.NewsList, error) {
+	latest, err := s.GetLatest()
+	if err != nil {
+		return nil, err
+	}
+
+	wanted := make(map[string]struct{}, len(links))
+	for _, l := range links {
+		wanted[l] = struct{}{}
+	}
+
+	var newsStories dto.NewsList
+	for _, n := range latest {
+		if _, ok := wanted[n.SourceLink]; ok {
+			newsStories = append(newsStories, n)
+		}
+	}
+
+	return newsStories, nil
+}
+
+func (s *scraper) GetName() string {
+	return sources.SOURCE_PROTHOMALO
+}
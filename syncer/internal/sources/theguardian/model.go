@@ -17,9 +17,7 @@ func (nr NewsResponse) toLinks() []string {
 	for _, tab := range nr.Tabs {
 		if !strings.Contains(tab.Heading, "Across") {
 			for _, trail := range tab.Trails {
-				l := strings.ReplaceAll(trail.URL, "https://www.theguardian.com", "https://api.nextgen.guardianapps.co.uk")
-				l += ".json"
-				links = append(links, l)
+				links = append(links, trail.URL)
 			}
 		}
 	}
@@ -27,6 +25,11 @@ func (nr NewsResponse) toLinks() []string {
 	return links
 }
 
+// toApiURL returns the link of the JSON of a public article link
+func toApiURL(link string) string {
+	return strings.ReplaceAll(link, "https://www.theguardian.com", "https://api.nextgen.guardianapps.co.uk") + ".json"
+}
+
 type articleResponse struct {
 	Config struct {
 		Page struct {
@@ -8,6 +8,7 @@ import (
 
 	"ncrawler/internal/dto"
 	"ncrawler/internal/sources"
+	"ncrawler/internal/urlcanon"
 	"ncrawler/pkg/helpers"
 
 	"github.com/PuerkitoBio/goquery"
@@ -27,7 +28,7 @@ func (s *scraper) GetLatest() (dto.NewsList, error) {
 	// Save it to db
 	// Get a connection pool to the database
 	dbPool := helpers.GetDbPool()
-	newNewsLink, err := dbPool.News.DetectNewLinks(nLinks)
+	newNewsLink, err := dbPool.News.DetectNewLinks(urlcanon.NormalizeAll(nLinks))
 	if err != nil {
 		return nil, err
 	}
@@ -35,6 +36,14 @@ func (s *scraper) GetLatest() (dto.NewsList, error) {
 	return s.getNewsDetails(newNewsLink)
 }
 
+func (s *scraper) GetNews(links []string) (dto.NewsList, error) {
+	return s.getNewsDetails(links)
+}
+
+func (s *scraper) GetName() string {
+	return sources.SOURCE_THE_GUARDIAN
+}
+
 func (s *scraper) getLatestNewsLink() ([]string, error) {
 	var (
 		c         *colly.Collector
@@ -94,7 +103,7 @@ func (s *scraper) getNewsDetails(nLinks []string) ([]dto.News, error) {
 	)
 
 	for _, nl := range nLinks {
-		q.AddURL(nl)
+		q.AddURL(toApiURL(nl))
 	}
 
 	c.OnRequest(func(r *colly.Request) {
@@ -116,11 +125,13 @@ func (s *scraper) getNewsDetails(nLinks []string) ([]dto.News, error) {
 			return
 		}
 
+		// the api link is stored as the public article link
+		link := urlcanon.Normalize(r.Request.URL.String())
 		newsStory := dto.News{
-			Id:         r.Request.URL.String(),
+			Id:         link,
 			SourceName: sources.SOURCE_THE_GUARDIAN,
 			Category:   sources.CATEGORY_WORLD,
-			SourceLink: r.Request.URL.String(),
+			SourceLink: link,
 		}
 
 		html := helpers.CleanHtml(ar.HTML)
@@ -155,7 +166,11 @@ func (s *scraper) getNewsDetails(nLinks []string) ([]dto.News, error) {
 			slog.Info("Error parsing time:", "cause", err)
 			return
 		}
-		newsStory.Date = helpers.TimeToStr(publishedDate)
+		newsStory.PublishedAt = publishedDate
+		// <meta itemprop="dateModified" content="2024-10-21T13:42:34+0100">
+		if modifiedDate, err := time.Parse(layout, doc.Find("meta[itemprop=dateModified]").AttrOr("content", "")); err == nil {
+			newsStory.ModifiedAt = modifiedDate
+		}
 		// Find the div with the class 'content__article-body' and extract text from all <p> tags
 		story := doc.Find("div.content__article-body").Text()
 		newsStory.Story = strings.TrimSpace(story)
@@ -0,0 +1,207 @@
+// Package urlcanon reduces the links of news articles to their canonical
+// public URL, so the same article found under different links is stored once
+package urlcanon
+
+import (
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+)
+
+// hostRule rewrites the links of a host to its canonical form
+type hostRule struct {
+	// host is the canonical host of the links
+	host string
+	// trimSuffix is removed from the path, like the ".json" of API links
+	trimSuffix string
+}
+
+// hostRules map the hosts serving the same articles to the canonical host
+var hostRules = map[string]hostRule{
+	"edition.cnn.com":                {host: "www.cnn.com"},
+	"us.cnn.com":                     {host: "www.cnn.com"},
+	"amp.cnn.com":                    {host: "www.cnn.com"},
+	"cnn.com":                        {host: "www.cnn.com"},
+	"bbc.com":                        {host: "www.bbc.com"},
+	"bbc.co.uk":                      {host: "www.bbc.com"},
+	"www.bbc.co.uk":                  {host: "www.bbc.com"},
+	"m.bbc.co.uk":                    {host: "www.bbc.com"},
+	"theguardian.com":                {host: "www.theguardian.com"},
+	"amp.theguardian.com":            {host: "www.theguardian.com"},
+	"api.nextgen.guardianapps.co.uk": {host: "www.theguardian.com", trimSuffix: ".json"},
+	"reuters.com":                    {host: "www.reuters.com"},
+	"mobile.reuters.com":             {host: "www.reuters.com"},
+	"aljazeera.com":                  {host: "www.aljazeera.com"},
+	"amp.aljazeera.com":              {host: "www.aljazeera.com"},
+	"prothomalo.com":                 {host: "www.prothomalo.com"},
+	"amp.prothomalo.com":             {host: "www.prothomalo.com"},
+	"m.timesofindia.com":             {host: "timesofindia.indiatimes.com"},
+	"m.hindustantimes.com":           {host: "www.hindustantimes.com"},
+}
+
+// trackingParams are dropped from the query, along with every utm_ parameter
+var trackingParams = map[string]bool{
+	"fbclid": true, "gclid": true, "dclid": true, "msclkid": true, "igshid": true,
+	"mc_cid": true, "mc_eid": true, "ocid": true, "cid": true, "smid": true,
+	"cmp": true, "ref": true, "ref_src": true, "src": true, "ftag": true,
+	"taid": true, "iid": true, "at_medium": true, "at_campaign": true,
+	"at_custom1": true, "at_custom2": true, "at_custom3": true, "at_custom4": true,
+	"_edition": true, "dcr": true, "amp": true, "outputtype": true, "output": true,
+	"share": true, "xtor": true, "int_source": true,
+}
+
+// Normalize returns the canonical form of the link: https, the canonical
+// host, no AMP variant, no tracking parameters, fragment or trailing slash,
+// and the remaining query parameters sorted. A link that can not be parsed
+// is returned unchanged.
+func Normalize(link string) string {
+	link = strings.TrimSpace(link)
+	u, err := url.Parse(link)
+	if err != nil || u.Host == "" {
+		return link
+	}
+
+	u.Scheme = "https"
+	u.User = nil
+	u.Fragment = ""
+	u.RawFragment = ""
+
+	host := strings.ToLower(u.Hostname())
+	if port := u.Port(); port != "" && port != "80" && port != "443" {
+		host += ":" + port
+	}
+	if rule, ok := hostRules[host]; ok {
+		host = rule.host
+		if rule.trimSuffix != "" {
+			u.Path = strings.TrimSuffix(u.Path, rule.trimSuffix)
+		}
+	}
+	u.Host = host
+
+	u.Path = stripAMP(u.Path)
+	if u.Path != "/" {
+		u.Path = strings.TrimRight(u.Path, "/")
+	}
+	u.RawPath = ""
+
+	u.RawQuery = cleanQuery(u.Query())
+
+	return u.String()
+}
+
+// NormalizeAll normalizes the links and drops the duplicates, keeping the order
+func NormalizeAll(links []string) []string {
+	seen := make(map[string]struct{}, len(links))
+	normalized := make([]string, 0, len(links))
+	for _, l := range links {
+		n := Normalize(l)
+		if _, ok := seen[n]; ok {
+			continue
+		}
+		seen[n] = struct{}{}
+		normalized = append(normalized, n)
+	}
+
+	return normalized
+}
+
+// FromPage returns the canonical URL of a fetched article. The
+// <link rel=canonical> of the page is preferred over its og:url, both are
+// only trusted when they point to the same site as the fetched link.
+func FromPage(fetched, canonical, ogURL string) string {
+	base, err := url.Parse(fetched)
+	if err != nil {
+		return Normalize(fetched)
+	}
+
+	for _, candidate := range []string{canonical, ogURL} {
+		if strings.TrimSpace(candidate) == "" {
+			continue
+		}
+		ref, err := base.Parse(strings.TrimSpace(candidate))
+		if err != nil {
+			continue
+		}
+		if n := Normalize(ref.String()); sameSite(n, Normalize(fetched)) {
+			return n
+		}
+	}
+
+	return Normalize(fetched)
+}
+
+// stripAMP removes the AMP markers of the path: an "amp" segment at the
+// start or the end, or an ".amp" extension
+func stripAMP(p string) string {
+	trimmed := strings.TrimRight(p, "/")
+	switch {
+	case strings.HasPrefix(trimmed, "/amp/"):
+		trimmed = strings.TrimPrefix(trimmed, "/amp")
+	case path.Base(trimmed) == "amp":
+		trimmed = path.Dir(trimmed)
+	case strings.HasSuffix(trimmed, ".amp"):
+		trimmed = strings.TrimSuffix(trimmed, ".amp")
+	default:
+		return p
+	}
+
+	return trimmed
+}
+
+func cleanQuery(values url.Values) string {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		lk := strings.ToLower(k)
+		if strings.HasPrefix(lk, "utm_") || trackingParams[lk] {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	if len(keys) == 0 {
+		return ""
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	for _, k := range keys {
+		vs := values[k]
+		sort.Strings(vs)
+		for _, v := range vs {
+			if sb.Len() > 0 {
+				sb.WriteByte('&')
+			}
+			sb.WriteString(url.QueryEscape(k))
+			sb.WriteByte('=')
+			sb.WriteString(url.QueryEscape(v))
+		}
+	}
+
+	return sb.String()
+}
+
+// sameSite reports whether two normalized links share their registrable
+// domain, approximated by the last two labels of the host
+func sameSite(a, b string) bool {
+	ua, errA := url.Parse(a)
+	ub, errB := url.Parse(b)
+	if errA != nil || errB != nil {
+		return false
+	}
+
+	return siteOf(ua.Hostname()) == siteOf(ub.Hostname())
+}
+
+func siteOf(host string) string {
+	labels := strings.Split(host, ".")
+	n := 2
+	// second level public suffixes like co.uk
+	if len(labels) >= 3 && len(labels[len(labels)-2]) <= 3 && len(labels[len(labels)-1]) == 2 {
+		n = 3
+	}
+	if len(labels) <= n {
+		return host
+	}
+
+	return strings.Join(labels[len(labels)-n:], ".")
+}
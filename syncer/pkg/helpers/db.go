@@ -1,9 +1,19 @@
 package helpers
 
 import (
+	"fmt"
+
+	"ncrawler/db"
 	"ncrawler/internal/datastores/pg"
+	"ncrawler/internal/datastores/sqlite3"
+	"ncrawler/internal/definition"
 	"ncrawler/internal/dto"
-	// _ "github.com/mattn/go-sqlite3"
+	"ncrawler/internal/migrate"
+)
+
+const (
+	DATASTORE_PG      = "pg"
+	DATASTORE_SQLITE3 = "sqlite3"
 )
 
 // func GetDbPool() *sqlite3.Repositories {
@@ -40,3 +50,102 @@ func GetDbPool() *pg.Repositories {
 	dbPool := pg.GetInstance(dbConfig)
 	return pg.NewRepositories(dbPool)
 }
+
+// GetSqliteDb returns the repositories of the local sqlite3 database
+func GetSqliteDb() *sqlite3.Repositories {
+	dbConfig := &dto.DBConfig{
+		DBDriver: "sqlite3",
+		DBPath:   "./db/news.db",
+	}
+	dbInstance := sqlite3.GetInstance(dbConfig)
+	return sqlite3.NewRepositories(dbInstance)
+}
+
+// GetMigrator returns the migrator of the schema of the datastore,
+// DATASTORE_PG or DATASTORE_SQLITE3
+func GetMigrator(datastore string) (*migrate.Migrator, error) {
+	switch datastore {
+	case DATASTORE_PG:
+		return migrate.New(&GetDbPool().Migrations, db.Migrations, db.PG_MIGRATIONS)
+	case DATASTORE_SQLITE3:
+		return migrate.New(&GetSqliteDb().Migrations, db.Migrations, db.SQLITE3_MIGRATIONS)
+	}
+
+	return nil, fmt.Errorf("unknown datastore %s", datastore)
+}
+
+// GetNewsStore returns the news store of the datastore, DATASTORE_PG or
+// DATASTORE_SQLITE3
+func GetNewsStore(datastore string) (definition.NewsStore, error) {
+	switch datastore {
+	case DATASTORE_PG:
+		return &GetDbPool().News, nil
+	case DATASTORE_SQLITE3:
+		return &GetSqliteDb().News, nil
+	}
+
+	return nil, fmt.Errorf("unknown datastore %s", datastore)
+}
+
+// GetEntityStore returns the entity store of the datastore, DATASTORE_PG or
+// DATASTORE_SQLITE3
+func GetEntityStore(datastore string) (definition.EntityStore, error) {
+	switch datastore {
+	case DATASTORE_PG:
+		return &GetDbPool().Entities, nil
+	case DATASTORE_SQLITE3:
+		return &GetSqliteDb().Entities, nil
+	}
+
+	return nil, fmt.Errorf("unknown datastore %s", datastore)
+}
+
+// GetRetentionStore returns the store pruning the news of the datastore,
+// DATASTORE_PG or DATASTORE_SQLITE3
+func GetRetentionStore(datastore string) (definition.RetentionStore, error) {
+	switch datastore {
+	case DATASTORE_PG:
+		return &GetDbPool().News, nil
+	case DATASTORE_SQLITE3:
+		return &GetSqliteDb().News, nil
+	}
+
+	return nil, fmt.Errorf("unknown datastore %s", datastore)
+}
+
+// GetLinkStore returns the store rewriting the news links of the datastore,
+// DATASTORE_PG or DATASTORE_SQLITE3
+func GetLinkStore(datastore string) (definition.LinkStore, error) {
+	switch datastore {
+	case DATASTORE_PG:
+		return &GetDbPool().News, nil
+	case DATASTORE_SQLITE3:
+		return &GetSqliteDb().News, nil
+	}
+
+	return nil, fmt.Errorf("unknown datastore %s", datastore)
+}
+
+// GetBackupStore returns the store backing up and restoring the datastore,
+// DATASTORE_PG or DATASTORE_SQLITE3
+func GetBackupStore(datastore string) (definition.BackupStore, error) {
+	switch datastore {
+	case DATASTORE_PG:
+		return &GetDbPool().Backup, nil
+	case DATASTORE_SQLITE3:
+		return &GetSqliteDb().Backup, nil
+	}
+
+	return nil, fmt.Errorf("unknown datastore %s", datastore)
+}
+
+// QueryNews runs the news query against the datastore, DATASTORE_PG or
+// DATASTORE_SQLITE3
+func QueryNews(datastore string, q dto.NewsQuery) (dto.NewsPage, error) {
+	store, err := GetNewsStore(datastore)
+	if err != nil {
+		return dto.NewsPage{}, err
+	}
+
+	return store.QueryNews(q)
+}
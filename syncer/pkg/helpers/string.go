@@ -1,5 +1,13 @@
 package helpers
 
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"time"
+	"unicode"
+)
+
 func GetUniqueStrings(s []string) []string {
 	if len(s) == 0 {
 		return nil
@@ -17,3 +25,46 @@ func GetUniqueStrings(s []string) []string {
 
 	return res
 }
+
+// GetRandomID returns a time ordered unique id, e.g. 20241021T054423-9f3a61c2
+func GetRandomID() string {
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+
+	return time.Now().UTC().Format("20060102T150405") + "-" + hex.EncodeToString(b)
+}
+
+// SplitSentences splits the text into sentences after ., !, ?, । (Bangla
+// dari) and line breaks
+func SplitSentences(text string) []string {
+	var (
+		sentences []string
+		current   strings.Builder
+	)
+
+	flush := func() {
+		if s := strings.TrimSpace(current.String()); s != "" {
+			sentences = append(sentences, s)
+		}
+		current.Reset()
+	}
+
+	runes := []rune(text)
+	for i, r := range runes {
+		if r == '\n' {
+			flush()
+			continue
+		}
+		current.WriteRune(r)
+
+		switch r {
+		case '.', '!', '?', '।':
+			if i+1 == len(runes) || unicode.IsSpace(runes[i+1]) {
+				flush()
+			}
+		}
+	}
+	flush()
+
+	return sentences
+}
@@ -9,3 +9,17 @@ func GetCurrentTimestamp() string {
 func TimeToStr(t time.Time) string {
 	return t.Format(time.RFC3339)
 }
+
+func GetCurrentTime() time.Time {
+	return time.Now()
+}
+
+// StartOfDay returns midnight of the day of t in the location of t
+func StartOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// StartOfMonth returns midnight of the first day of the month of t in the location of t
+func StartOfMonth(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+}